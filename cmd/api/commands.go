@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"itv/internal/config"
+	"itv/internal/dto"
+	"itv/internal/fixtures"
+	"itv/internal/model"
+	"itv/internal/service"
+	"itv/pkg/validator"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// command is something the binary does instead of, or as, serving the API.
+// Its name is one or two words, such as seed or user create, and usage
+// describes the flags and arguments that follow the name.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(cfg *config.Config, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"serve", "", "run the API server, the default", runServe},
+	{"migrate", "", "create or update the database schema and exit", runMigrate},
+	{"seed", "", "load the sample data and SEED_FAKE_MOVIES generated movies", runSeed},
+	{"user list", "", "list the users", runUserList},
+	{"user create", "[--role role] [--password-file file] username", "add a user", runUserCreate},
+	{"user set-role", "username role", "change the role of a user", runUserSetRole},
+	{"user reset-password", "[--password-file file] username", "set a new password for a user", runUserResetPassword},
+	{"token issue", "--user username [--ttl duration]", "issue a token for a user without the password, for debugging", runTokenIssue},
+	{"movies import", "[file]", "add the movies in a JSON or YAML file, or standard input, that do not exist yet", runMoviesImport},
+	{"movies export", "[--output file]", "write all movies as JSON in the format movies import reads", runMoviesExport},
+	{"config print", "", "print the effective configuration", runConfigPrint},
+	{"config docs", "", "print the configuration reference in Markdown", runConfigDocs},
+}
+
+// errUsage makes runCommand report the usage of the command.
+var errUsage = errors.New("wrong arguments")
+
+func init() {
+	var usage strings.Builder
+	w := tabwriter.NewWriter(&usage, 0, 8, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", c.name, c.summary)
+	}
+	w.Flush()
+	config.CommandUsage = usage.String()
+}
+
+// runCommand runs the command named by the first words of args with the
+// rest as its flags and arguments.
+func runCommand(cfg *config.Config, args []string) error {
+	for _, c := range commands {
+		words := strings.Fields(c.name)
+		if len(args) < len(words) || !slices.Equal(args[:len(words)], words) {
+			continue
+		}
+
+		fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "Usage: api [flags] %s %s\n\n%s.\n", c.name, c.usage, strings.ToUpper(c.summary[:1])+c.summary[1:])
+			fs.PrintDefaults()
+		}
+		err := c.run(cfg, fs, args[len(words):])
+		if errors.Is(err, errUsage) {
+			return fmt.Errorf("usage: api [flags] %s %s", c.name, c.usage)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown command %q, run api -h for usage", strings.Join(args, " "))
+}
+
+// runsWithInvalidConfig reports whether args name config print, which shows
+// the settings even when they are invalid since that is when they are most
+// needed. The problems are reported after it.
+func runsWithInvalidConfig(args []string) bool {
+	return len(args) >= 2 && args[0] == "config" && args[1] == "print"
+}
+
+// parseArgs parses the flags in args and returns the arguments after them,
+// of which there must be between min and max.
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() < min || fs.NArg() > max {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// runTask builds the application without the server and calls invoke, which
+// does its work while the application is built.
+func runTask(cfg *config.Config, invoke interface{}) error {
+	app := fx.New(fx.Supply(cfg), providers, fx.Invoke(invoke), fx.NopLogger)
+	return app.Err()
+}
+
+// validate checks a DTO by its binding rules, as the API does for requests.
+func validate(v interface{}) error {
+	validationErrs, err := validator.ValidateStruct(v)
+	if err != nil {
+		return err
+	}
+	if len(validationErrs) > 0 {
+		return validationErrs
+	}
+	return nil
+}
+
+// readPassword reads a password from file, or else the first line of the
+// standard input. Passwords are not taken as arguments, which other users
+// can see in the process list.
+func readPassword(file string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Password: ")
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("reading the password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func runServe(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	serve(cfg)
+	return nil
+}
+
+// runMigrate only connects to the database, which migrates the schema.
+func runMigrate(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	return runTask(cfg, func(db *gorm.DB) {
+		log.Println("Database schema is up to date")
+	})
+}
+
+func runSeed(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	return runTask(cfg, seed)
+}
+
+// seed loads the fixtures and SEED_FAKE_MOVIES generated movies.
+func seed(config *config.Config, seedService *service.SeedService) error {
+	_, err := seedService.Seed(config.SeedFakeMovies)
+	return err
+}
+
+func runUserList(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	return runTask(cfg, func(userService *service.UserService) error {
+		users, err := userService.GetAllUsers()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUSERNAME\tROLE\tCREATED")
+		for _, user := range users {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", user.ID, user.Username, user.Role, user.CreatedAt.Format(time.DateTime))
+		}
+		return w.Flush()
+	})
+}
+
+func runUserCreate(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	role := fs.String("role", model.RoleUser, "`role` of the user, such as user or admin")
+	passwordFile := fs.String("password-file", "", "read the password from `file` instead of the standard input")
+	args, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	userDTO := dto.CreateUserDTO{Username: args[0], Password: password, Role: *role}
+	if err := validate(userDTO); err != nil {
+		return err
+	}
+
+	return runTask(cfg, func(userService *service.UserService) error {
+		user, err := userService.CreateUser(userDTO)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s %s with ID %d\n", user.Role, user.Username, user.ID)
+		return nil
+	})
+}
+
+func runUserSetRole(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	roleDTO := dto.UpdateUserRoleDTO{Role: args[1]}
+	if err := validate(roleDTO); err != nil {
+		return err
+	}
+
+	return runTask(cfg, func(userService *service.UserService) error {
+		user, err := userService.SetRole(args[0], roleDTO)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s is now %s\n", user.Username, user.Role)
+		return nil
+	})
+}
+
+func runUserResetPassword(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	passwordFile := fs.String("password-file", "", "read the password from `file` instead of the standard input")
+	args, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	passwordDTO := dto.ResetPasswordDTO{Password: password}
+	if err := validate(passwordDTO); err != nil {
+		return err
+	}
+
+	return runTask(cfg, func(userService *service.UserService) error {
+		if err := userService.ResetPassword(args[0], passwordDTO); err != nil {
+			return err
+		}
+		fmt.Printf("Changed the password of %s\n", args[0])
+		return nil
+	})
+}
+
+// runTokenIssue prints only the token, so that it can be captured with
+// $(api token issue --user alice).
+func runTokenIssue(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	username := fs.String("user", "", "`username` to issue the token for")
+	ttl := fs.Duration("ttl", 0, "how long the token is valid for, JWT_EXPIRY when not set")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *username == "" || *ttl < 0 {
+		return errUsage
+	}
+
+	return runTask(cfg, func(authService *service.AuthService) error {
+		token, err := authService.IssueToken(*username, *ttl)
+		if err != nil {
+			return err
+		}
+		fmt.Println(token.Token)
+		return nil
+	})
+}
+
+func runMoviesImport(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+
+	input := io.Reader(os.Stdin)
+	if len(args) == 1 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+	movies, err := fixtures.ReadMovies(input)
+	if err != nil {
+		return fmt.Errorf("reading movies: %w", err)
+	}
+
+	return runTask(cfg, func(seedService *service.SeedService) error {
+		result, err := seedService.ImportMovies(movies)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d movies and %d genres, skipped %d movies that exist\n",
+			result.Movies, result.Genres, len(movies)-result.Movies)
+		return nil
+	})
+}
+
+func runMoviesExport(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	output := fs.String("output", "", "write to `file` instead of the standard output")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	return runTask(cfg, func(seedService *service.SeedService) error {
+		movies, err := seedService.ExportMovies()
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			return fixtures.WriteMovies(os.Stdout, movies)
+		}
+
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		if err := fixtures.WriteMovies(file, movies); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		log.Printf("Exported %d movies to %s", len(movies), *output)
+		return nil
+	})
+}
+
+func runConfigPrint(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	return cfg.Print(os.Stdout)
+}
+
+func runConfigDocs(cfg *config.Config, fs *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	return config.WriteDocs(os.Stdout)
+}
@@ -2,18 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"itv/internal/config"
 	"itv/internal/controller"
 	"itv/internal/middleware"
+	"itv/internal/model"
 	"itv/internal/repository"
 	"itv/internal/service"
 	"itv/pkg/auth"
+	"itv/pkg/cache"
 	"itv/pkg/database"
+	"itv/pkg/ratelimit"
+	"itv/pkg/storage"
+	"itv/pkg/validator"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/fx"
@@ -29,78 +39,290 @@ import (
 // @securityDefinitions.apikey	BearerAuth
 // @in							header
 // @name						Authorization
+// @securityDefinitions.apikey	ApiKeyAuth
+// @in							header
+// @name						X-API-Key
 func main() {
+	cfg, args, err := config.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	var invalid *config.InvalidError
+	if errors.As(err, &invalid) && runsWithInvalidConfig(invalid.Args) {
+		if err := runCommand(invalid.Config, invalid.Args); err != nil {
+			log.Print(err)
+		}
+		log.Fatal(invalid)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	err = runCommand(cfg, args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		log.Fatal(err)
+	}
+}
+
+// serve runs the API server until the process is signalled to stop.
+func serve(cfg *config.Config) {
 	app := fx.New(
-		fx.Provide(
-			config.LoadConfig,
-			database.NewDatabase,
-			repository.NewMovieRepository,
-			repository.NewUserRepository,
-			auth.NewJWTService,
-			service.NewMovieService,
-			service.NewAuthService,
-			middleware.NewAuthMiddleware,
-			controller.NewMovieController,
-			controller.NewAuthController,
-			newRouter,
-		),
-		fx.Invoke(registerHooks),
+		fx.Supply(cfg),
+		providers,
+		fx.Invoke(registerHooks, watchConfig, seedOnStart),
 	)
 
 	app.Run()
 }
 
+// providers construct the application. Constructors only run when something
+// depends on their result, so commands get just the parts they use.
+var providers = fx.Provide(
+	config.NewReloader,
+	database.NewDatabase,
+	repository.NewMovieRepository,
+	repository.NewUserRepository,
+	repository.NewGenreRepository,
+	repository.NewTagRepository,
+	repository.NewPersonRepository,
+	repository.NewReviewRepository,
+	repository.NewWatchlistRepository,
+	repository.NewWatchHistoryRepository,
+	repository.NewMediaRepository,
+	repository.NewTranslationRepository,
+	repository.NewRoleRepository,
+	repository.NewAPIKeyRepository,
+	storage.NewStorage,
+	cache.NewCache,
+	ratelimit.NewStore,
+	auth.NewJWTService,
+	service.NewMovieCache,
+	service.NewMovieService,
+	service.NewLoginGuard,
+	service.NewAuthService,
+	service.NewGenreService,
+	service.NewPersonService,
+	service.NewReviewService,
+	service.NewWatchlistService,
+	service.NewWatchHistoryService,
+	service.NewRecommendationService,
+	service.NewMediaService,
+	service.NewTranslationService,
+	service.NewSeedService,
+	service.NewUserService,
+	service.NewRoleService,
+	service.NewAPIKeyService,
+	service.NewRateLimitService,
+	middleware.NewAuthMiddleware,
+	middleware.NewRateLimitMiddleware,
+	middleware.NewCORSMiddleware,
+	controller.NewMovieController,
+	controller.NewAuthController,
+	controller.NewGenreController,
+	controller.NewPersonController,
+	controller.NewReviewController,
+	controller.NewWatchlistController,
+	controller.NewWatchHistoryController,
+	controller.NewRecommendationController,
+	controller.NewMediaController,
+	controller.NewTranslationController,
+	controller.NewCacheController,
+	controller.NewRateLimitController,
+	controller.NewRoleController,
+	controller.NewUserController,
+	controller.NewAPIKeyController,
+	newRouter,
+)
+
 func newRouter(
 	config *config.Config,
 	movieController *controller.MovieController,
 	authController *controller.AuthController,
+	genreController *controller.GenreController,
+	personController *controller.PersonController,
+	reviewController *controller.ReviewController,
+	watchlistController *controller.WatchlistController,
+	historyController *controller.WatchHistoryController,
+	recommendationController *controller.RecommendationController,
+	mediaController *controller.MediaController,
+	translationController *controller.TranslationController,
+	cacheController *controller.CacheController,
+	rateLimitController *controller.RateLimitController,
+	roleController *controller.RoleController,
+	userController *controller.UserController,
+	apiKeyController *controller.APIKeyController,
+	store storage.Storage,
 	authMiddleware *middleware.AuthMiddleware,
-) *gin.Engine {
-	if config.AppEnv == "production" {
+	rateLimitMiddleware *middleware.RateLimitMiddleware,
+	corsMiddleware *middleware.CORSMiddleware,
+	reloader *config.Reloader,
+) (*gin.Engine, error) {
+	production := config.IsProduction()
+	if production {
 		gin.SetMode(gin.ReleaseMode)
 	}
+	binding.Validator = validator.New()
 
-	router := gin.Default()
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+	router.Use(middleware.RequestLogger(production, reloader), middleware.Recovery())
+	router.Use(middleware.ErrorHandler())
+	router.NoRoute(middleware.NoRoute)
+	router.NoMethod(middleware.NoMethod)
 
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	router.Use(corsMiddleware.Handler(), middleware.SecurityHeaders(config))
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusOK)
-			return
-		}
-		c.Next()
-	})
+	// Uploads kept on the local disk are served by the API itself; other
+	// backends hand out their own URLs.
+	if local, ok := store.(*storage.LocalStorage); ok && strings.HasPrefix(config.MediaBaseURL, "/") {
+		router.Static(config.MediaBaseURL, local.Dir())
+	}
 
 	api := router.Group("/api/v1")
+	api.Use(middleware.BodyLimit(config.MaxBodySize))
 	{
 		authApi := api.Group("/auth")
+		authApi.Use(rateLimitMiddleware.Limit("auth"))
 		{
-			authApi.POST("/login", authController.Login)
+			authApi.POST("/login", rateLimitMiddleware.Login(), authController.Login)
 		}
 
 		movies := api.Group("/movies")
-		movies.Use(authMiddleware.JWTAuth())
+		movies.Use(authMiddleware.JWTAuth(), rateLimitMiddleware.Limit("movies"), middleware.CacheControl(config.MoviesCacheControl))
 		{
 			movies.GET("", movieController.GetAllMovies)
 			movies.GET("/:id", movieController.GetMovieByID)
-			movies.GET("/search", movieController.SearchMovies)
+			movies.GET("/search", rateLimitMiddleware.Limit("search"), movieController.SearchMovies)
+			movies.GET("/:id/similar", recommendationController.GetSimilarMovies)
+			movies.GET("/:id/media", mediaController.GetMovieMedia)
+			movies.GET("/:id/translations", translationController.GetTranslations)
+			movies.GET("/:id/reviews", reviewController.GetMovieReviews)
+			movies.GET("/:id/reviews/me", reviewController.GetMyReview)
+			movies.PUT("/:id/reviews/me", reviewController.UpsertMyReview)
+			movies.DELETE("/:id/reviews/me", reviewController.DeleteMyReview)
+
+			writeRoutes := movies.Group("")
+			writeRoutes.Use(authMiddleware.RequirePermission(model.PermissionMoviesWrite))
+			{
+				writeRoutes.POST("", movieController.CreateMovie)
+				writeRoutes.PUT("/:id", movieController.UpdateMovie)
+				// Uploads are bounded by MEDIA_MAX_SIZE in the handler instead.
+				writeRoutes.POST("/:id/media", middleware.BodyLimit(0), mediaController.UploadMedia)
+				writeRoutes.DELETE("/:id/media/:mediaId", mediaController.DeleteMedia)
+				writeRoutes.PUT("/:id/translations/:locale", translationController.SaveTranslation)
+				writeRoutes.DELETE("/:id/translations/:locale", translationController.DeleteTranslation)
+			}
+			movies.DELETE("/:id", authMiddleware.RequirePermission(model.PermissionMoviesDelete), movieController.DeleteMovie)
+		}
+
+		genres := api.Group("/genres")
+		genres.Use(authMiddleware.JWTAuth(), rateLimitMiddleware.Limit("genres"), middleware.CacheControl(config.GenresCacheControl))
+		{
+			genres.GET("", genreController.GetAllGenres)
+			genres.GET("/:id", genreController.GetGenreByID)
+
+			writeRoutes := genres.Group("")
+			writeRoutes.Use(authMiddleware.RequirePermission(model.PermissionMoviesWrite))
+			{
+				writeRoutes.POST("", genreController.CreateGenre)
+				writeRoutes.PUT("/:id", genreController.UpdateGenre)
+			}
+			genres.DELETE("/:id", authMiddleware.RequirePermission(model.PermissionMoviesDelete), genreController.DeleteGenre)
+		}
+
+		people := api.Group("/people")
+		people.Use(authMiddleware.JWTAuth(), rateLimitMiddleware.Limit("people"), middleware.CacheControl(config.PeopleCacheControl))
+		{
+			people.GET("", personController.GetAllPeople)
+			people.GET("/:id", personController.GetPersonByID)
+			people.GET("/:id/filmography", personController.GetFilmography)
+
+			writeRoutes := people.Group("")
+			writeRoutes.Use(authMiddleware.RequirePermission(model.PermissionMoviesWrite))
+			{
+				writeRoutes.POST("", personController.CreatePerson)
+				writeRoutes.PUT("/:id", personController.UpdatePerson)
+			}
+			people.DELETE("/:id", authMiddleware.RequirePermission(model.PermissionMoviesDelete), personController.DeletePerson)
+		}
 
-			adminRoutes := movies.Group("")
-			adminRoutes.Use(authMiddleware.RoleAuth("admin"))
+		reviews := api.Group("/reviews")
+		reviews.Use(authMiddleware.JWTAuth(), rateLimitMiddleware.Limit("reviews"))
+		{
+			reviews.POST("/:id/flag", reviewController.FlagReview)
+
+			moderatorRoutes := reviews.Group("")
+			moderatorRoutes.Use(authMiddleware.RequirePermission(model.PermissionReviewsModerate))
+			{
+				moderatorRoutes.GET("/flagged", reviewController.GetFlaggedReviews)
+				moderatorRoutes.PUT("/:id/moderation", reviewController.ModerateReview)
+				moderatorRoutes.DELETE("/:id", reviewController.DeleteReview)
+			}
+		}
+
+		me := api.Group("/me")
+		me.Use(authMiddleware.JWTAuth(), rateLimitMiddleware.Limit("me"), middleware.CacheControl(config.MeCacheControl))
+		{
+			me.GET("/watchlists", watchlistController.GetWatchlists)
+			me.POST("/watchlists", watchlistController.CreateWatchlist)
+			me.GET("/watchlists/:listId", watchlistController.GetWatchlist)
+			me.PUT("/watchlists/:listId", watchlistController.RenameWatchlist)
+			me.DELETE("/watchlists/:listId", watchlistController.DeleteWatchlist)
+			me.POST("/watchlists/:listId/items", watchlistController.AddMovie)
+			me.PUT("/watchlists/:listId/items/order", watchlistController.ReorderMovies)
+			me.DELETE("/watchlists/:listId/items/:movieId", watchlistController.RemoveMovie)
+
+			me.GET("/history", historyController.GetHistory)
+			me.POST("/history", historyController.MarkWatched)
+			me.DELETE("/history/:id", historyController.DeleteEntry)
+
+			me.GET("/recommendations", recommendationController.GetRecommendations)
+		}
+
+		admin := api.Group("/admin")
+		admin.Use(authMiddleware.JWTAuth(), rateLimitMiddleware.Limit("admin"))
+		{
+			// Operating the server is not covered by a permission.
+			admin.GET("/cache", authMiddleware.RoleAuth(model.RoleAdmin), cacheController.GetCacheStats)
+			admin.GET("/rate-limits", authMiddleware.RoleAuth(model.RoleAdmin), rateLimitController.GetUsage)
+
+			// API keys can carry any permission, so only admins issue them.
+			apiKeyRoutes := admin.Group("/api-keys")
+			apiKeyRoutes.Use(authMiddleware.RoleAuth(model.RoleAdmin))
+			{
+				apiKeyRoutes.GET("", apiKeyController.GetAllAPIKeys)
+				apiKeyRoutes.POST("", apiKeyController.CreateAPIKey)
+				apiKeyRoutes.DELETE("/:id", apiKeyController.RevokeAPIKey)
+			}
+
+			userRoutes := admin.Group("")
+			userRoutes.Use(authMiddleware.RequirePermission(model.PermissionUsersManage))
 			{
-				adminRoutes.POST("", movieController.CreateMovie)
-				adminRoutes.PUT("/:id", movieController.UpdateMovie)
-				adminRoutes.DELETE("/:id", movieController.DeleteMovie)
+				userRoutes.GET("/permissions", roleController.GetPermissions)
+				userRoutes.GET("/roles", roleController.GetAllRoles)
+				userRoutes.POST("/roles", roleController.CreateRole)
+				userRoutes.GET("/roles/:id", roleController.GetRoleByID)
+				userRoutes.PUT("/roles/:id", roleController.UpdateRole)
+				userRoutes.DELETE("/roles/:id", roleController.DeleteRole)
+				userRoutes.GET("/users", userController.GetAllUsers)
+				userRoutes.PUT("/users/:id/role", userController.UpdateUserRole)
 			}
 		}
 	}
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if config.SwaggerEnabled {
+		router.GET("/swagger/*any",
+			middleware.ContentSecurityPolicy(middleware.SwaggerContentSecurityPolicy),
+			ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
 
-	return router
+	return router, nil
 }
 
 func registerHooks(
@@ -133,3 +355,29 @@ func registerHooks(
 		},
 	)
 }
+
+// watchConfig applies configuration changes on SIGHUP or when the config
+// file is edited while the server runs.
+func watchConfig(lifecycle fx.Lifecycle, reloader *config.Reloader) {
+	lifecycle.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return reloader.Start()
+			},
+			OnStop: func(ctx context.Context) error {
+				reloader.Stop()
+				return nil
+			},
+		},
+	)
+}
+
+// seedOnStart seeds the database before the server starts when
+// SEED_ON_START is set. It runs while the application is built rather than
+// in a start hook, which would time out on large seeds.
+func seedOnStart(config *config.Config, seedService *service.SeedService) error {
+	if !config.SeedOnStart {
+		return nil
+	}
+	return seed(config, seedService)
+}
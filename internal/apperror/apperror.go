@@ -0,0 +1,110 @@
+// Package apperror defines the domain errors that services and repositories
+// return. Each error carries a kind, which decides the HTTP status, and a
+// stable machine-readable code that clients can rely on.
+package apperror
+
+import (
+	"errors"
+	"itv/pkg/validator"
+	"time"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooLarge
+	KindUnsupportedMedia
+	KindTooManyRequests
+)
+
+const CodeValidationFailed = "validation_failed"
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Fields lists the offending fields of a validation error.
+	Fields validator.ValidationErrors
+	// RetryAfter tells a throttled client when to try again.
+	RetryAfter time.Duration
+	// Err is the underlying cause, if any. It is never shown to clients.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func TooLarge(code, message string) *Error {
+	return &Error{Kind: KindTooLarge, Code: code, Message: message}
+}
+
+func UnsupportedMedia(code, message string) *Error {
+	return &Error{Kind: KindUnsupportedMedia, Code: code, Message: message}
+}
+
+// TooManyRequests reports a throttled client that may retry after
+// retryAfter.
+func TooManyRequests(code, message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindTooManyRequests, Code: code, Message: message, RetryAfter: retryAfter}
+}
+
+// InvalidFields reports one or more invalid request fields.
+func InvalidFields(fields validator.ValidationErrors) *Error {
+	return &Error{
+		Kind:    KindValidation,
+		Code:    CodeValidationFailed,
+		Message: "one or more fields are invalid",
+		Fields:  fields,
+	}
+}
+
+// InvalidField reports a single request field that failed the validation
+// rule tag with param.
+func InvalidField(field, tag, param string) *Error {
+	return InvalidFields(validator.ValidationErrors{validator.NewError(field, tag, param)})
+}
+
+// KindOf returns the kind of the first *Error in err's chain, or
+// KindInternal when there is none.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
+
+func IsNotFound(err error) bool {
+	return KindOf(err) == KindNotFound
+}
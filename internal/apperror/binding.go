@@ -0,0 +1,59 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"itv/pkg/validator"
+
+	playground "github.com/go-playground/validator/v10"
+)
+
+// FromBinding converts an error from Gin's ShouldBind* methods into a
+// validation error without exposing Go type or struct names.
+func FromBinding(err error) *Error {
+	var fieldErrs playground.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		return InvalidFields(validator.FromFieldErrors(fieldErrs))
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return InvalidField(typeErr.Field, "type", jsonTypeName(typeErr.Type.Kind().String()))
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return TooLarge("request_too_large", "request body is too large")
+	}
+
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return &Error{Kind: KindValidation, Code: "malformed_request", Message: "request body is empty", Err: err}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &Error{Kind: KindValidation, Code: "malformed_request", Message: "request body is not valid JSON", Err: err}
+	default:
+		return &Error{Kind: KindValidation, Code: "malformed_request", Message: "request could not be parsed", Err: err}
+	}
+}
+
+func jsonTypeName(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"):
+		return "integer"
+	case strings.HasPrefix(kind, "float"):
+		return "number"
+	case kind == "slice" || kind == "array":
+		return "array"
+	case kind == "struct" || kind == "map":
+		return "object"
+	case kind == "bool":
+		return "boolean"
+	default:
+		return kind
+	}
+}
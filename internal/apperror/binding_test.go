@@ -0,0 +1,64 @@
+package apperror
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"itv/pkg/validator"
+)
+
+type bindingTarget struct {
+	Title string `json:"title" binding:"required"`
+	Year  int    `json:"year" binding:"min=1888"`
+}
+
+// bind decodes and validates body the way Gin's JSON binding does.
+func bind(body io.Reader) error {
+	var target bindingTarget
+	if err := json.NewDecoder(body).Decode(&target); err != nil {
+		return err
+	}
+	return validator.New().ValidateStruct(&target)
+}
+
+func TestFromBinding(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        io.Reader
+		wantKind    Kind
+		wantCode    string
+		wantMessage string
+		wantFields  []string
+	}{
+		{"missing field", strings.NewReader(`{"year": 2000}`), KindValidation, CodeValidationFailed, "one or more fields are invalid",
+			[]string{"title: This field is required"}},
+		{"failed rule", strings.NewReader(`{"title": "Heat", "year": 1000}`), KindValidation, CodeValidationFailed, "one or more fields are invalid",
+			[]string{"year: Must be at least 1888"}},
+		{"wrong type", strings.NewReader(`{"title": "Heat", "year": "1995"}`), KindValidation, CodeValidationFailed, "one or more fields are invalid",
+			[]string{"year: Must be of type integer"}},
+		{"wrong top-level type", strings.NewReader(`[]`), KindValidation, "malformed_request", "request could not be parsed", nil},
+		{"empty body", strings.NewReader(``), KindValidation, "malformed_request", "request body is empty", nil},
+		{"truncated body", strings.NewReader(`{"title":`), KindValidation, "malformed_request", "request body is not valid JSON", nil},
+		{"invalid JSON", strings.NewReader(`{title}`), KindValidation, "malformed_request", "request body is not valid JSON", nil},
+		{"too large", http.MaxBytesReader(nil, io.NopCloser(strings.NewReader(`{"title": "Heat"}`)), 4), KindTooLarge, "request_too_large", "request body is too large", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromBinding(bind(tt.body))
+			if err.Kind != tt.wantKind || err.Code != tt.wantCode || err.Message != tt.wantMessage {
+				t.Errorf("FromBinding = %d %q %q, want %d %q %q", err.Kind, err.Code, err.Message, tt.wantKind, tt.wantCode, tt.wantMessage)
+			}
+
+			var fields []string
+			for _, field := range err.Fields {
+				fields = append(fields, field.Field+": "+field.Message)
+			}
+			if strings.Join(fields, "\n") != strings.Join(tt.wantFields, "\n") {
+				t.Errorf("FromBinding fields = %q, want %q", fields, tt.wantFields)
+			}
+		})
+	}
+}
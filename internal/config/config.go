@@ -1,74 +1,232 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"log"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
+// Config holds the application settings. The env tag names the key of each
+// field, the default tag its value when no source sets it and the doc tag
+// describes it for the generated reference. Fields tagged secret are redacted
+// by Print and can be read from a file named by their _FILE variant, and
+// fields tagged reload are applied by Reloader without a restart.
 type Config struct {
-	AppEnv     string
-	AppPort    int
-	DBHost     string
-	DBPort     int
-	DBName     string
-	DBUser     string
-	DBPassword string
-	DBSSLMode  string
-	JWTSecret  string
-	JWTExpiry  time.Duration
-	AdminUser  string
-	AdminPass  string
+	AppEnv     string        `env:"APP_ENV" default:"development" doc:"Environment name: development, test, staging or production."`
+	AppPort    int           `env:"APP_PORT" default:"8080" doc:"Port the HTTP server listens on."`
+	LogLevel   string        `env:"LOG_LEVEL" default:"info" reload:"true" doc:"Requests to log: debug and info log all, warn client and server errors, error server errors only."`
+	DBDriver   string        `env:"DB_DRIVER" default:"postgres" doc:"Database driver: postgres, mysql or sqlite."`
+	DBPath     string        `env:"DB_PATH" default:"movies.db" doc:"SQLite database file, or :memory: for a database that lives as long as the process."`
+	DBHost     string        `env:"DB_HOST" default:"localhost" doc:"PostgreSQL or MySQL host."`
+	DBPort     int           `env:"DB_PORT" default:"5432" doc:"PostgreSQL or MySQL port; defaults to 3306 with the mysql driver."`
+	DBName     string        `env:"DB_NAME" default:"movies_db" doc:"PostgreSQL or MySQL database name."`
+	DBUser     string        `env:"DB_USER" default:"postgres" doc:"PostgreSQL or MySQL user."`
+	DBPassword string        `env:"DB_PASSWORD" default:"postgres" secret:"true" doc:"PostgreSQL or MySQL password."`
+	DBSSLMode  string        `env:"DB_SSL_MODE" default:"disable" doc:"TLS mode in PostgreSQL sslmode terms: disable, allow, prefer, require, verify-ca or verify-full."`
+	JWTSecret  string        `env:"JWT_SECRET" default:"default_secret_key" secret:"true" doc:"Key used to sign access tokens, at least 32 characters in production."`
+	JWTExpiry  time.Duration `env:"JWT_EXPIRATION" default:"24h" reload:"true" doc:"Lifetime of access tokens."`
+	AdminUser  string        `env:"ADMIN_USERNAME" default:"admin" doc:"Username of the admin account created on first start."`
+	AdminPass  string        `env:"ADMIN_PASSWORD" default:"adminpassword" secret:"true" doc:"Password of the admin account created on first start."`
+
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"25" doc:"Maximum number of open connections per database, 0 for no limit."`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"10" doc:"Maximum number of idle connections kept per database."`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"30m" doc:"How long a connection may be reused, 0 for no limit."`
+	DBConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"5m" doc:"How long a connection may sit idle, 0 for no limit."`
+	DBConnectRetries  int           `env:"DB_CONNECT_RETRIES" default:"10" doc:"How often to retry connecting at startup before giving up."`
+	DBConnectBackoff  time.Duration `env:"DB_CONNECT_BACKOFF" default:"1s" doc:"Wait before the first connection retry, doubled on each further retry up to 30s."`
+	// DBReplicas are full DSNs, in key=value or URL form, of read replicas.
+	DBReplicas []string `env:"DB_REPLICAS" secret:"true" doc:"Comma-separated DSNs of read replicas that serve catalog reads and search."`
+
+	SeedOnStart    bool `env:"SEED_ON_START" default:"false" doc:"Load the sample data, as the seed command does, before the server starts."`
+	SeedFakeMovies int  `env:"SEED_FAKE_MOVIES" default:"0" doc:"Number of generated movies the sample data includes, for load testing."`
+
+	// SwaggerEnabled and HSTSMaxAge default to values that depend on AppEnv.
+	SwaggerEnabled bool          `env:"SWAGGER_ENABLED" doc:"Serve the Swagger UI; defaults to false in production and true otherwise."`
+	TrustedProxies []string      `env:"TRUSTED_PROXIES" doc:"Proxy addresses or CIDR ranges whose forwarding headers are trusted."`
+	MaxBodySize    int64         `env:"MAX_BODY_SIZE" default:"1048576" doc:"Maximum size of JSON request bodies in bytes."`
+	HSTSMaxAge     time.Duration `env:"HSTS_MAX_AGE" doc:"Strict-Transport-Security max-age; defaults to 8760h in production and off otherwise."`
+	ReferrerPolicy string        `env:"REFERRER_POLICY" default:"no-referrer" doc:"Referrer-Policy header value; empty omits the header."`
+
+	StorageDriver string `env:"STORAGE_DRIVER" default:"local" doc:"Media storage backend: local or s3."`
+	MediaDir      string `env:"MEDIA_DIR" default:"./uploads" doc:"Directory for uploaded media with the local driver."`
+	MediaBaseURL  string `env:"MEDIA_BASE_URL" default:"/media" doc:"URL prefix under which local media is served."`
+	MediaMaxSize  int64  `env:"MEDIA_MAX_SIZE" default:"10485760" doc:"Maximum size of a media upload in bytes."`
+	S3Endpoint    string `env:"S3_ENDPOINT" default:"localhost:9000" doc:"S3 endpoint host and port."`
+	S3Region      string `env:"S3_REGION" default:"us-east-1" doc:"S3 region."`
+	S3Bucket      string `env:"S3_BUCKET" default:"movies-media" doc:"S3 bucket for media."`
+	S3AccessKey   string `env:"S3_ACCESS_KEY" secret:"true" doc:"S3 access key."`
+	S3SecretKey   string `env:"S3_SECRET_KEY" secret:"true" doc:"S3 secret key."`
+	S3UseSSL      bool   `env:"S3_USE_SSL" default:"false" doc:"Connect to S3 over TLS."`
+	S3PublicURL   string `env:"S3_PUBLIC_URL" doc:"Public base URL of the bucket; empty uses the endpoint."`
+
+	CacheDriver   string        `env:"CACHE_DRIVER" default:"memory" doc:"Response cache backend: memory, redis or none."`
+	CacheTTL      time.Duration `env:"CACHE_TTL" default:"5m" doc:"Lifetime of cached entries."`
+	CacheSize     int           `env:"CACHE_SIZE" default:"1000" doc:"Maximum number of entries in the memory cache."`
+	RedisAddr     string        `env:"REDIS_ADDR" default:"localhost:6379" doc:"Redis address with the redis cache driver."`
+	RedisPassword string        `env:"REDIS_PASSWORD" secret:"true" doc:"Redis password."`
+	RedisDB       int           `env:"REDIS_DB" default:"0" doc:"Redis database number."`
+
+	LoginIPLimit      string        `env:"LOGIN_IP_LIMIT" default:"20/1m:10" reload:"true" doc:"Login attempts allowed per client IP, as <n>/<period>[:<burst>]."`
+	LoginUserLimit    string        `env:"LOGIN_USER_LIMIT" default:"5/1m" reload:"true" doc:"Login attempts allowed per username, as <n>/<period>[:<burst>]."`
+	LoginMaxFailures  int           `env:"LOGIN_MAX_FAILURES" default:"5" doc:"Consecutive failed logins from one client IP before the account is locked for that IP."`
+	LoginFailureDelay time.Duration `env:"LOGIN_FAILURE_DELAY" default:"1s" doc:"Delay after the first failed login, doubled on each further failure."`
+	LoginLockout      time.Duration `env:"LOGIN_LOCKOUT" default:"15m" doc:"How long an account stays locked for a client IP."`
+
+	// API rate limit policies per route group, see ratelimit.ParsePolicy.
+	// Groups without a policy of their own use RateLimitDefault.
+	RateLimitDefault string `env:"RATE_LIMIT_DEFAULT" default:"anonymous=60/1m:20,user=120/1m:30,admin=600/1m:100" reload:"true" doc:"Rate limit policy for route groups without their own, as role=<n>/<period>[:<burst>],..."`
+	RateLimitAuth    string `env:"RATE_LIMIT_AUTH" reload:"true" doc:"Rate limit policy for /auth; defaults to RATE_LIMIT_DEFAULT."`
+	RateLimitMovies  string `env:"RATE_LIMIT_MOVIES" reload:"true" doc:"Rate limit policy for /movies; defaults to RATE_LIMIT_DEFAULT."`
+	RateLimitSearch  string `env:"RATE_LIMIT_SEARCH" default:"user=30/1m:10,admin=120/1m:30" reload:"true" doc:"Rate limit policy for /movies/search."`
+	RateLimitGenres  string `env:"RATE_LIMIT_GENRES" reload:"true" doc:"Rate limit policy for /genres; defaults to RATE_LIMIT_DEFAULT."`
+	RateLimitPeople  string `env:"RATE_LIMIT_PEOPLE" reload:"true" doc:"Rate limit policy for /people; defaults to RATE_LIMIT_DEFAULT."`
+	RateLimitReviews string `env:"RATE_LIMIT_REVIEWS" reload:"true" doc:"Rate limit policy for reviews; defaults to RATE_LIMIT_DEFAULT."`
+	RateLimitMe      string `env:"RATE_LIMIT_ME" reload:"true" doc:"Rate limit policy for /me; defaults to RATE_LIMIT_DEFAULT."`
+	RateLimitAdmin   string `env:"RATE_LIMIT_ADMIN" reload:"true" doc:"Rate limit policy for /admin; defaults to RATE_LIMIT_DEFAULT."`
+
+	CORSAllowedOrigins   []string      `env:"CORS_ALLOWED_ORIGINS" default:"*" reload:"true" doc:"Origins allowed to call the API; entries may be *, exact or https://*.example.com."`
+	CORSAllowedMethods   []string      `env:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE,OPTIONS" reload:"true" doc:"Methods allowed in CORS requests."`
+	CORSAllowedHeaders   []string      `env:"CORS_ALLOWED_HEADERS" default:"Authorization,Content-Type,Accept-Language,If-None-Match,If-Modified-Since" reload:"true" doc:"Request headers allowed in CORS requests."`
+	CORSExposedHeaders   []string      `env:"CORS_EXPOSED_HEADERS" default:"ETag,Last-Modified,Content-Language,Retry-After,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy" reload:"true" doc:"Response headers exposed to CORS clients."`
+	CORSAllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" default:"false" reload:"true" doc:"Allow credentialed CORS requests; cannot be combined with a * origin."`
+	CORSMaxAge           time.Duration `env:"CORS_MAX_AGE" default:"10m" reload:"true" doc:"How long browsers may cache a preflight response."`
+
+	// Cache-Control values for successful reads, per route group.
+	MoviesCacheControl string `env:"CACHE_CONTROL_MOVIES" default:"private, no-cache" doc:"Cache-Control for movie reads; empty omits the header."`
+	GenresCacheControl string `env:"CACHE_CONTROL_GENRES" default:"private, max-age=300" doc:"Cache-Control for genre reads; empty omits the header."`
+	PeopleCacheControl string `env:"CACHE_CONTROL_PEOPLE" default:"private, max-age=300" doc:"Cache-Control for people reads; empty omits the header."`
+	MeCacheControl     string `env:"CACHE_CONTROL_ME" default:"private, no-store" doc:"Cache-Control for /me reads; empty omits the header."`
+
+	// args and file record where the configuration came from so that it
+	// can be loaded again.
+	args []string
+	file string
 }
 
-func LoadConfig() (*Config, error) {
-	_ = godotenv.Load()
-
-	appEnv := getEnv("APP_ENV", "development")
-	appPort, _ := strconv.Atoi(getEnv("APP_PORT", "8080"))
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort, _ := strconv.Atoi(getEnv("DB_PORT", "5432"))
-	dbName := getEnv("DB_NAME", "movies_db")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbSSLMode := getEnv("DB_SSL_MODE", "disable")
-	jwtSecret := getEnv("JWT_SECRET", "default_secret_key")
-	jwtExpiry, _ := time.ParseDuration(getEnv("JWT_EXPIRATION", "24h"))
-	adminUser := getEnv("ADMIN_USERNAME", "admin")
-	adminPass := getEnv("ADMIN_PASSWORD", "adminpassword")
-
-	return &Config{
-		AppEnv:     appEnv,
-		AppPort:    appPort,
-		DBHost:     dbHost,
-		DBPort:     dbPort,
-		DBName:     dbName,
-		DBUser:     dbUser,
-		DBPassword: dbPassword,
-		DBSSLMode:  dbSSLMode,
-		JWTSecret:  jwtSecret,
-		JWTExpiry:  jwtExpiry,
-		AdminUser:  adminUser,
-		AdminPass:  adminPass,
-	}, nil
+// InvalidError lists the problems with a configuration that was read but is
+// not valid. Config holds the values as far as they could be read, with
+// defaults in place of those that could not, and Args the command after the
+// flags, so that config print can still show them.
+type InvalidError struct {
+	Config *Config
+	Args   []string
+	Errs   []error
 }
 
+func (e *InvalidError) Error() string {
+	return "invalid configuration:\n" + errors.Join(e.Errs...).Error()
+}
+
+func (e *InvalidError) Unwrap() []error {
+	return e.Errs
+}
+
+// LoadConfig builds the configuration from, in order of precedence, the
+// command-line flags in args, the environment, an optional .env file in the
+// working directory, and the YAML or TOML file named by --config or
+// CONFIG_FILE. Every
+// value is parsed and validated, and all problems are reported together in an
+// *InvalidError so that the server does not start half-configured. The
+// arguments left after the flags are returned as the command to run.
+func LoadConfig(args []string) (*Config, []string, error) {
+	line, err := parseFlags(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources := []source{mapSource("command line", line.values), envSource()}
+	var errs []error
+	dotenv, err := dotenvSource(dotenvFile)
+	if err != nil {
+		errs = append(errs, err)
+	} else if dotenv.lookup != nil {
+		sources = append(sources, dotenv)
+	}
+	path := line.configFile
+	if path == "" {
+		path, _, _ = lookup(sources, "CONFIG_FILE", false)
+	}
+	if path != "" {
+		file, fileErrs := readConfigFile(path)
+		if file.lookup != nil {
+			sources = append(sources, file)
+		}
+		errs = append(errs, fileErrs...)
+	}
+
+	config := &Config{args: args, file: path}
+	set, loadErrs := load(config, sources)
+	config.applyDerivedDefaults(set)
+	errs = firstPerKey(append(append(errs, loadErrs...), config.validate()...))
+	if len(errs) > 0 {
+		return nil, nil, &InvalidError{Config: config, Args: line.args, Errs: errs}
+	}
+
+	for _, warning := range config.warnings() {
+		log.Printf("config: %s", warning)
+	}
+	return config, line.args, nil
+}
+
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == "production"
+}
+
+// GetDBConnectionString returns the DSN of the primary database in the form
+// the driver named by DB_DRIVER expects.
 func (c *Config) GetDBConnectionString() string {
-	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName, c.DBSSLMode)
+	switch c.DBDriver {
+	case "mysql":
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=true&loc=UTC&tls=%s",
+			c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName, mysqlTLS[c.DBSSLMode])
+	case "sqlite":
+		// Foreign keys are off by default in SQLite, and the busy timeout
+		// makes concurrent writers wait for the lock instead of failing.
+		pragmas := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+		if c.DBPath == ":memory:" {
+			return "file::memory:?" + pragmas
+		}
+		return "file:" + c.DBPath + "?_pragma=journal_mode(WAL)&" + pragmas
+	default:
+		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName, c.DBSSLMode)
+	}
+}
+
+// mysqlTLS maps DB_SSL_MODE to the tls parameter of the MySQL driver.
+var mysqlTLS = map[string]string{
+	"disable":     "false",
+	"allow":       "preferred",
+	"prefer":      "preferred",
+	"require":     "skip-verify",
+	"verify-ca":   "true",
+	"verify-full": "true",
 }
 
 func (c *Config) GetAppAddress() string {
 	return fmt.Sprintf(":%d", c.AppPort)
 }
 
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// applyDerivedDefaults fills the settings whose defaults depend on others
+// unless set records that they were given explicitly.
+func (c *Config) applyDerivedDefaults(set map[string]bool) {
+	if !set["SWAGGER_ENABLED"] {
+		c.SwaggerEnabled = !c.IsProduction()
+	}
+	if !set["HSTS_MAX_AGE"] && c.IsProduction() {
+		c.HSTSMaxAge = 365 * 24 * time.Hour
+	}
+	if !set["DB_PORT"] && c.DBDriver == "mysql" {
+		c.DBPort = 3306
+	}
+
+	for _, policy := range []*string{
+		&c.RateLimitAuth, &c.RateLimitMovies, &c.RateLimitGenres, &c.RateLimitPeople,
+		&c.RateLimitReviews, &c.RateLimitMe, &c.RateLimitAdmin,
+	} {
+		if *policy == "" {
+			*policy = c.RateLimitDefault
+		}
 	}
-	return value
 }
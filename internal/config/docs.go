@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WriteDocs writes a Markdown reference of every setting, generated from the
+// tags of Config, to w.
+func WriteDocs(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Configuration\n\n")
+	b.WriteString("<!-- Generated by `go run ./cmd/api config docs`; do not edit. -->\n\n")
+	b.WriteString("Settings are read from, in order of precedence, command-line flags, environment variables, a `.env` file in the working directory and a YAML or TOML file named by `--config` or `CONFIG_FILE`. ")
+	b.WriteString("In the file, keys are the lowercase setting names and lists may be written as arrays. ")
+	b.WriteString("Secrets can also be read from a file named by their `_FILE` variant and are only accepted on the command line in that form.\n\n")
+	b.WriteString("Settings marked as reloadable are applied on SIGHUP, which also rereads the `.env` file, or when the config file changes; changes to the others need a restart.\n\n")
+	b.WriteString("| Setting | File key | Flag | Type | Default | Reloadable | Description |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+
+	for _, field := range settings() {
+		key := field.Tag.Get("env")
+		fileKeys := fmt.Sprintf("`%s`", strings.ToLower(key))
+		flag := fmt.Sprintf("`--%s`", flagName(key))
+		description := field.Tag.Get("doc")
+		if isSecret(field) {
+			fileKeys += fmt.Sprintf(", `%s`", strings.ToLower(key+fileSuffix))
+			flag = fmt.Sprintf("`--%s`", flagName(key+fileSuffix))
+			description += fmt.Sprintf(" Secret; `%s` names a file holding it.", key+fileSuffix)
+		}
+		defaultValue := ""
+		if value := field.Tag.Get("default"); value != "" {
+			defaultValue = fmt.Sprintf("`%s`", value)
+		}
+		reloadable := ""
+		if field.Tag.Get("reload") == "true" {
+			reloadable = "yes"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s | %s |\n",
+			key, fileKeys, flag, typeName(field.Type), defaultValue, reloadable, description)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func typeName(t reflect.Type) string {
+	if t == durationType {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice:
+		return "list"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// settingError reports a problem with the setting named Key.
+type settingError struct {
+	Key string
+	Err error
+}
+
+func (e *settingError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *settingError) Unwrap() error {
+	return e.Err
+}
+
+// firstPerKey drops every error after the first one reported for the same
+// setting, so a value that fails to parse is not also reported as invalid.
+func firstPerKey(errs []error) []error {
+	seen := make(map[string]bool)
+	var result []error
+	for _, err := range errs {
+		var setting *settingError
+		if errors.As(err, &setting) {
+			if seen[setting.Key] {
+				continue
+			}
+			seen[setting.Key] = true
+		}
+		result = append(result, err)
+	}
+	return result
+}
+
+// load sets every tagged field of config from the first of sources that has
+// its key, falling back to the field's default, which also replaces values
+// that could not be parsed. It returns the keys that were provided and one
+// error per value that could not be read or parsed.
+func load(config *Config, sources []source) (map[string]bool, []error) {
+	set := make(map[string]bool)
+	var errs []error
+
+	v := reflect.ValueOf(config).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		value, ok, err := lookup(sources, key, isSecret(field))
+		if err != nil {
+			errs = append(errs, &settingError{Key: key, Err: err})
+			continue
+		}
+		if ok {
+			set[key] = true
+		} else {
+			value = field.Tag.Get("default")
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			errs = append(errs, &settingError{Key: key, Err: err})
+			_ = setField(v.Field(i), field.Tag.Get("default"))
+		}
+	}
+	return set, errs
+}
+
+// setField parses value into field according to the field's type. An empty
+// value leaves the zero value.
+func setField(field reflect.Value, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s or 5m", value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// formatField renders a field the way setField reads it.
+func formatField(field reflect.Value) string {
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String()
+	}
+	switch field.Kind() {
+	case reflect.Slice:
+		return strings.Join(field.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(field.Interface())
+	}
+}
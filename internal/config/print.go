@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+const redacted = "[redacted]"
+
+// Print writes the effective configuration to w as KEY=value lines in the
+// order the settings are declared. Secrets that are set are redacted.
+func (c *Config) Print(w io.Writer) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		value := formatField(v.Field(i))
+		if field.Tag.Get("secret") == "true" && value != "" {
+			value = redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce collapses the burst of events an editor or a Kubernetes
+// ConfigMap update produces into a single reload.
+const reloadDebounce = 250 * time.Millisecond
+
+// Reloader loads the configuration again on SIGHUP and whenever the config
+// file changes. A new configuration that passes validation replaces the
+// current one and is passed to the subscribers. Only fields tagged reload
+// are taken over; changes to the others are logged and ignored until the
+// next restart.
+type Reloader struct {
+	current     atomic.Pointer[Config]
+	mu          sync.Mutex
+	subscribers []func(*Config) error
+	done        chan struct{}
+	stopped     sync.WaitGroup
+}
+
+func NewReloader(config *Config) *Reloader {
+	r := &Reloader{}
+	r.current.Store(config)
+	return r
+}
+
+// Current returns the configuration with the latest reloaded values.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Subscribe registers fn to be called with every reloaded configuration.
+// A subscriber that returns an error keeps its previous settings.
+func (r *Reloader) Subscribe(fn func(*Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload loads and validates the configuration again and applies the
+// reloadable changes. An invalid configuration leaves the current one in
+// place and is returned as an error.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.current.Load()
+	next, _, err := LoadConfig(current.args)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	cv, nv := reflect.ValueOf(current).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		field := cv.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" || reflect.DeepEqual(cv.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+		if field.Tag.Get("reload") != "true" {
+			log.Printf("config: %s changed but only takes effect after a restart", key)
+			nv.Field(i).Set(cv.Field(i))
+			continue
+		}
+		log.Printf("config: reloaded %s", key)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	r.current.Store(next)
+	for _, fn := range r.subscribers {
+		if err := fn(next); err != nil {
+			log.Printf("config: applying reload: %v", err)
+		}
+	}
+	return nil
+}
+
+// Start reloads on SIGHUP and, when the configuration came from a file, on
+// changes to that file until Stop is called.
+func (r *Reloader) Start() error {
+	var events chan fsnotify.Event
+	var errs chan error
+	var watcher *fsnotify.Watcher
+	if file := r.current.Load().file; file != "" {
+		var err error
+		watcher, err = fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		// The directory is watched rather than the file so that files
+		// replaced by a rename, as editors and Kubernetes do, are followed.
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			watcher.Close()
+			return err
+		}
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	r.done = make(chan struct{})
+	r.stopped.Add(1)
+
+	go func() {
+		defer r.stopped.Done()
+		defer signal.Stop(hangup)
+		if watcher != nil {
+			defer watcher.Close()
+		}
+
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-hangup:
+				log.Println("config: reloading on SIGHUP")
+				r.reload()
+			case event := <-events:
+				if r.touches(event) {
+					debounce = time.After(reloadDebounce)
+				}
+			case <-debounce:
+				log.Println("config: reloading after the config file changed")
+				r.reload()
+			case err := <-errs:
+				log.Printf("config: watching the config file: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ends watching for reloads.
+func (r *Reloader) Stop() {
+	if r.done == nil {
+		return
+	}
+	close(r.done)
+	r.stopped.Wait()
+	r.done = nil
+}
+
+func (r *Reloader) reload() {
+	if err := r.Reload(); err != nil {
+		log.Printf("config: keeping the current configuration: %v", err)
+	}
+}
+
+// touches reports whether event may have changed the config file. Besides the
+// file itself, Kubernetes swaps the ..data symlink of a mounted ConfigMap.
+func (r *Reloader) touches(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	return name == filepath.Base(r.current.Load().file) || name == "..data"
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// newFileReloader loads the configuration from a config file with content
+// and returns a Reloader for it along with the file's path.
+func newFileReloader(t *testing.T, content string) (*Reloader, string) {
+	t.Helper()
+	for _, key := range []string{"CONFIG_FILE", "APP_PORT", "LOG_LEVEL", "RATE_LIMIT_MOVIES"} {
+		t.Setenv(key, "")
+	}
+	path := writeFile(t, "config.yaml", content)
+	config, _, err := LoadConfig([]string{"--config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewReloader(config), path
+}
+
+func rewrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloaderReload(t *testing.T) {
+	r, path := newFileReloader(t, "app_port: 7070\nlog_level: info\n")
+	original := r.Current()
+	var applied []*Config
+	r.Subscribe(func(c *Config) error {
+		applied = append(applied, c)
+		return errors.New("failing subscriber")
+	})
+	r.Subscribe(func(c *Config) error {
+		applied = append(applied, c)
+		return nil
+	})
+
+	// Nothing changed.
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Current() != original || len(applied) != 0 {
+		t.Errorf("Reload without changes replaced the configuration or called %d subscribers", len(applied))
+	}
+
+	// Only settings tagged reload are taken over.
+	rewrite(t, path, "app_port: 9090\nlog_level: debug\nrate_limit_movies: user=1/1m\n")
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	current := r.Current()
+	if current.LogLevel != "debug" || current.RateLimitMovies != "user=1/1m" {
+		t.Errorf("reloaded LOG_LEVEL = %q, RATE_LIMIT_MOVIES = %q", current.LogLevel, current.RateLimitMovies)
+	}
+	if current.AppPort != 7070 {
+		t.Errorf("APP_PORT = %d, want 7070 until a restart", current.AppPort)
+	}
+	if original.LogLevel != "info" {
+		t.Errorf("Reload changed the previous configuration in place")
+	}
+	if len(applied) != 2 || applied[0] != current || applied[1] != current {
+		t.Errorf("subscribers were called %d times, want both with the new configuration", len(applied))
+	}
+
+	// A change to a restart-only setting alone is no reload.
+	rewrite(t, path, "app_port: 8081\nlog_level: debug\nrate_limit_movies: user=1/1m\n")
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Current() != current || len(applied) != 2 {
+		t.Errorf("Reload of a restart-only change replaced the configuration or called subscribers")
+	}
+
+	// An invalid configuration keeps the current one.
+	rewrite(t, path, "log_level: loud\n")
+	var invalid *InvalidError
+	if err := r.Reload(); !errors.As(err, &invalid) {
+		t.Errorf("Reload of an invalid configuration = %v, want an *InvalidError", err)
+	}
+	if r.Current() != current || len(applied) != 2 {
+		t.Errorf("invalid configuration was applied")
+	}
+}
+
+func TestReloaderReloadDotenv(t *testing.T) {
+	for _, key := range []string{"CONFIG_FILE", "LOG_LEVEL"} {
+		t.Setenv(key, "")
+	}
+	dir := t.TempDir()
+	chdir(t, dir)
+	path := filepath.Join(dir, ".env")
+	rewrite(t, path, "LOG_LEVEL=info\n")
+	config, _, err := LoadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReloader(config)
+
+	rewrite(t, path, "LOG_LEVEL=debug\n")
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Current().LogLevel; got != "debug" {
+		t.Errorf("LOG_LEVEL after editing .env = %q, want debug", got)
+	}
+	if got := os.Getenv("LOG_LEVEL"); got != "" {
+		t.Errorf("loading .env set LOG_LEVEL=%q in the environment", got)
+	}
+}
+
+func TestReloaderTouches(t *testing.T) {
+	r := NewReloader(&Config{file: "/etc/itv/config.yaml"})
+	tests := []struct {
+		event fsnotify.Event
+		want  bool
+	}{
+		{fsnotify.Event{Name: "/etc/itv/config.yaml", Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: "/etc/itv/config.yaml", Op: fsnotify.Create}, true},
+		{fsnotify.Event{Name: "/etc/itv/config.yaml", Op: fsnotify.Rename}, true},
+		{fsnotify.Event{Name: "/etc/itv/config.yaml", Op: fsnotify.Chmod}, false},
+		{fsnotify.Event{Name: "/etc/itv/..data", Op: fsnotify.Create}, true},
+		{fsnotify.Event{Name: "/etc/itv/other.yaml", Op: fsnotify.Write}, false},
+	}
+	for _, tt := range tests {
+		if got := r.touches(tt.event); got != tt.want {
+			t.Errorf("touches(%s) = %t, want %t", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestReloaderStart(t *testing.T) {
+	r, path := newFileReloader(t, "log_level: info\n")
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	// Replace the file by a rename, as editors and Kubernetes do.
+	next := filepath.Join(filepath.Dir(path), "config.yaml.tmp")
+	rewrite(t, next, "log_level: warn\n")
+	if err := os.Rename(next, path); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Current().LogLevel != "warn" {
+		if time.Now().After(deadline) {
+			t.Fatal("the config file change was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
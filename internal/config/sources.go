@@ -0,0 +1,264 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// fileSuffix marks the variant of a secret setting that names a file holding
+// the value, as mounted by Docker and Kubernetes secrets.
+const fileSuffix = "_FILE"
+
+// A source provides raw setting values by key. LoadConfig consults its
+// sources in order of precedence and the first one that has a key wins.
+type source struct {
+	name   string
+	lookup func(key string) (string, bool)
+}
+
+// envSource reads environment variables. Empty variables count as unset.
+func envSource() source {
+	return source{
+		name: "environment",
+		lookup: func(key string) (string, bool) {
+			value := os.Getenv(key)
+			return value, value != ""
+		},
+	}
+}
+
+// dotenvFile is the file of environment variable assignments that LoadConfig
+// reads from the working directory.
+const dotenvFile = ".env"
+
+// dotenvSource reads the variables assigned in the .env file at path. Like
+// the environment, it treats empty values as unset. A missing file provides
+// nothing. The file is read on every load rather than copied into the
+// environment, so reloads pick up edits to it.
+func dotenvSource(path string) (source, error) {
+	values, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return source{}, nil
+	}
+	if err != nil {
+		return source{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return source{
+		name: path,
+		lookup: func(key string) (string, bool) {
+			value := values[key]
+			return value, value != ""
+		},
+	}, nil
+}
+
+// mapSource serves values collected from flags or a config file. Unlike the
+// environment, an empty value is an explicit setting there.
+func mapSource(name string, values map[string]string) source {
+	return source{
+		name: name,
+		lookup: func(key string) (string, bool) {
+			value, ok := values[key]
+			return value, ok
+		},
+	}
+}
+
+// settings returns the fields of Config that carry an env key, in
+// declaration order.
+func settings() []reflect.StructField {
+	t := reflect.TypeOf(Config{})
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("env") != "" {
+			fields = append(fields, t.Field(i))
+		}
+	}
+	return fields
+}
+
+func isSecret(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true"
+}
+
+// knownKeys returns every key a source may provide.
+func knownKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, field := range settings() {
+		key := field.Tag.Get("env")
+		keys[key] = true
+		if isSecret(field) {
+			keys[key+fileSuffix] = true
+		}
+	}
+	return keys
+}
+
+// flagName turns an env key such as DB_SSL_MODE into db-ssl-mode.
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// fileKey turns a config file key such as db_ssl_mode into DB_SSL_MODE.
+func fileKey(key string) string {
+	return strings.ReplaceAll(strings.ToUpper(key), "-", "_")
+}
+
+// commandLine holds the parsed command-line flags.
+type commandLine struct {
+	configFile string
+	values     map[string]string
+	args       []string
+}
+
+// CommandUsage lists the commands in the -h message. The binary, which
+// defines the commands, fills it in.
+var CommandUsage string
+
+// parseFlags reads the flags in front of a command. Every setting has a flag
+// named after its key, except that secrets only accept their file variant so
+// that they do not show up in process listings.
+func parseFlags(args []string) (*commandLine, error) {
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: api [flags] [command]")
+		if CommandUsage != "" {
+			fmt.Fprint(fs.Output(), "\nCommands:\n", CommandUsage)
+		}
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	line := &commandLine{values: make(map[string]string)}
+	fs.StringVar(&line.configFile, "config", "", "YAML or TOML `file` to read settings from (CONFIG_FILE)")
+	names := make(map[string]string)
+	for _, field := range settings() {
+		key := field.Tag.Get("env")
+		if isSecret(field) {
+			key += fileSuffix
+		}
+		names[flagName(key)] = key
+		fs.String(flagName(key), "", field.Tag.Get("doc"))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := names[f.Name]; ok {
+			line.values[key] = f.Value.String()
+		}
+	})
+	line.args = fs.Args()
+	return line, nil
+}
+
+// readConfigFile loads a flat YAML or TOML file whose keys are the lowercase
+// setting keys, such as app_port or jwt_secret_file.
+func readConfigFile(path string) (source, []error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return source{}, []error{fmt.Errorf("config file: %w", err)}
+	}
+
+	raw := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		err = errors.New("unsupported format, use .yaml, .yml or .toml")
+	}
+	if err != nil {
+		return source{}, []error{fmt.Errorf("config file %s: %w", path, err)}
+	}
+
+	known := knownKeys()
+	values := make(map[string]string, len(raw))
+	var errs []error
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := fileKey(name)
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("config file %s: unknown setting %q", path, name))
+			continue
+		}
+		value, err := fileValue(raw[name])
+		if err != nil {
+			errs = append(errs, &settingError{Key: key, Err: err})
+			continue
+		}
+		values[key] = value
+	}
+	return mapSource("config file "+path, values), errs
+}
+
+// fileValue renders a decoded config file value in the form setField reads.
+func fileValue(value any) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			s, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", errors.New("must be a single value or a list")
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// lookup finds key in the first source that has it. For secrets, a source
+// may instead name a file with the key's _FILE variant, whose content with
+// trailing newlines removed becomes the value.
+func lookup(sources []source, key string, secret bool) (string, bool, error) {
+	for _, src := range sources {
+		value, ok := src.lookup(key)
+		if !secret {
+			if ok {
+				return value, true, nil
+			}
+			continue
+		}
+
+		path, fromFile := src.lookup(key + fileSuffix)
+		switch {
+		case ok && fromFile:
+			return "", false, fmt.Errorf("%s sets both %s and %s", src.name, key, key+fileSuffix)
+		case ok:
+			return value, true, nil
+		case fromFile && path != "":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", false, fmt.Errorf("reading %s%s: %w", key, fileSuffix, err)
+			}
+			return strings.TrimRight(string(data), "\r\n"), true, nil
+		}
+	}
+	return "", false, nil
+}
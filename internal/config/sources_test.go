@@ -0,0 +1,230 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to name in a temporary directory and returns its
+// path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	secret := writeFile(t, "jwt_secret", "from-file\r\n\n")
+	tests := []struct {
+		name    string
+		sources []source
+		secret  bool
+		want    string
+		wantOK  bool
+		wantErr bool
+	}{
+		{"unset", []source{mapSource("flags", nil)}, true, "", false, false},
+		{"value", []source{mapSource("flags", map[string]string{"JWT_SECRET": "value"})}, true, "value", true, false},
+		{"file", []source{mapSource("flags", map[string]string{"JWT_SECRET_FILE": secret})}, true, "from-file", true, false},
+		{"empty file name", []source{mapSource("flags", map[string]string{"JWT_SECRET_FILE": ""})}, true, "", false, false},
+		{"missing file", []source{mapSource("flags", map[string]string{"JWT_SECRET_FILE": secret + ".missing"})}, true, "", false, true},
+		{"value and file", []source{mapSource("flags", map[string]string{"JWT_SECRET": "value", "JWT_SECRET_FILE": secret})}, true, "", false, true},
+		{"file in a lower source", []source{
+			mapSource("flags", map[string]string{"JWT_SECRET": "value"}),
+			mapSource("config file", map[string]string{"JWT_SECRET_FILE": secret}),
+		}, true, "value", true, false},
+		{"file in a higher source", []source{
+			mapSource("flags", map[string]string{"JWT_SECRET_FILE": secret}),
+			mapSource("config file", map[string]string{"JWT_SECRET": "value"}),
+		}, true, "from-file", true, false},
+		{"file for a plain setting", []source{mapSource("flags", map[string]string{"JWT_SECRET_FILE": secret})}, false, "", false, false},
+		{"explicitly empty", []source{
+			mapSource("flags", map[string]string{"JWT_SECRET": ""}),
+			mapSource("config file", map[string]string{"JWT_SECRET": "value"}),
+		}, true, "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := lookup(tt.sources, "JWT_SECRET", tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("lookup error = %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("lookup = %q, %t, want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEnvSource(t *testing.T) {
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("LOG_LEVEL", "")
+	env := envSource()
+	if value, ok := env.lookup("APP_PORT"); !ok || value != "9090" {
+		t.Errorf("lookup(APP_PORT) = %q, %t, want 9090", value, ok)
+	}
+	if _, ok := env.lookup("LOG_LEVEL"); ok {
+		t.Error("an empty variable counts as set")
+	}
+}
+
+// chdir changes into dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestDotenvSource(t *testing.T) {
+	path := writeFile(t, ".env", "# local settings\nAPP_PORT=9090\nexport DB_NAME=dotenv_db\nLOG_LEVEL=\n")
+	src, err := dotenvSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"APP_PORT": "9090", "DB_NAME": "dotenv_db"} {
+		if got, ok := src.lookup(key); !ok || got != want {
+			t.Errorf("%s = %q, %t, want %q", key, got, ok, want)
+		}
+	}
+	if _, ok := src.lookup("LOG_LEVEL"); ok {
+		t.Error("an empty variable counts as set")
+	}
+
+	if src, err := dotenvSource(path + ".missing"); err != nil || src.lookup != nil {
+		t.Errorf("dotenvSource of a missing file = %v, want no source and no error", err)
+	}
+	if _, err := dotenvSource(writeFile(t, ".env", "NOT VALID LINE\n")); err == nil {
+		t.Error("dotenvSource accepted a malformed file")
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		content    string
+		want       map[string]string
+		wantErrors int
+	}{
+		{"yaml", "config.yaml", "app_port: 9090\nlog-level: debug\ncors_allowed_origins:\n  - https://a.example.com\n  - https://b.example.com\njwt_secret_file: /run/secrets/jwt\nswagger_enabled: false\nreferrer_policy:\n",
+			map[string]string{
+				"APP_PORT":             "9090",
+				"LOG_LEVEL":            "debug",
+				"CORS_ALLOWED_ORIGINS": "https://a.example.com,https://b.example.com",
+				"JWT_SECRET_FILE":      "/run/secrets/jwt",
+				"SWAGGER_ENABLED":      "false",
+				"REFERRER_POLICY":      "",
+			}, 0},
+		{"toml", "config.toml", "app_port = 9090\ncache_ttl = \"1m\"\ntrusted_proxies = [\"10.0.0.0/8\"]\n",
+			map[string]string{"APP_PORT": "9090", "CACHE_TTL": "1m", "TRUSTED_PROXIES": "10.0.0.0/8"}, 0},
+		{"unknown keys", "config.yml", "app_port: 9090\napp_prot: 9091\nlog_level_file: x\n",
+			map[string]string{"APP_PORT": "9090"}, 2},
+		{"nested value", "config.yaml", "app_port: 9090\ndb_host:\n  primary: db\n",
+			map[string]string{"APP_PORT": "9090"}, 1},
+		{"malformed", "config.yaml", "app_port: [9090\n", nil, 1},
+		{"other format", "config.json", `{"app_port": 9090}`, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, errs := readConfigFile(writeFile(t, tt.file, tt.content))
+			if len(errs) != tt.wantErrors {
+				t.Errorf("readConfigFile errors = %v, want %d", errs, tt.wantErrors)
+			}
+			if tt.want == nil {
+				if src.lookup != nil {
+					t.Error("readConfigFile returned a source for an unreadable file")
+				}
+				return
+			}
+			for key, want := range tt.want {
+				if got, ok := src.lookup(key); !ok || got != want {
+					t.Errorf("%s = %q, %t, want %q", key, got, ok, want)
+				}
+			}
+			if _, ok := src.lookup("DB_NAME"); ok {
+				t.Error("DB_NAME is set although the file does not mention it")
+			}
+		})
+	}
+
+	if _, errs := readConfigFile(filepath.Join(t.TempDir(), "missing.yaml")); len(errs) != 1 {
+		t.Errorf("readConfigFile of a missing file = %v, want one error", errs)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	line, err := parseFlags([]string{"--config", "app.yaml", "--app-port", "9090", "--jwt-secret-file", "/run/secrets/jwt", "--referrer-policy=", "seed", "--app-port", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"APP_PORT": "9090", "JWT_SECRET_FILE": "/run/secrets/jwt", "REFERRER_POLICY": ""}
+	if line.configFile != "app.yaml" || len(line.values) != len(want) {
+		t.Errorf("parseFlags = %q, %v, want app.yaml, %v", line.configFile, line.values, want)
+	}
+	for key, value := range want {
+		if got, ok := line.values[key]; !ok || got != value {
+			t.Errorf("%s = %q, %t, want %q", key, got, ok, value)
+		}
+	}
+	if !slices.Equal(line.args, []string{"seed", "--app-port", "1"}) {
+		t.Errorf("args = %q, want the command with its own arguments", line.args)
+	}
+
+	// Secrets have no flag of their own to keep them out of process listings.
+	if _, err := parseFlags([]string{"--jwt-secret", "value"}); err == nil {
+		t.Error("parseFlags accepted --jwt-secret")
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	secret := writeFile(t, "jwt_secret", "secret-from-file\n")
+	file := writeFile(t, "config.yaml", "app_port: 7070\nlog_level: warn\ndb_name: file_db\ncache_ttl: 1m\ndb_driver: mysql\n")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("CACHE_TTL", "")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("DB_NAME", "env_db")
+	t.Setenv("JWT_SECRET_FILE", secret)
+	dir := t.TempDir()
+	dotenv := "CONFIG_FILE=" + file + "\nLOG_LEVEL=error\nCACHE_TTL=2m\n"
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(dotenv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, dir)
+
+	config, args, err := LoadConfig([]string{"--db-name", "flag_db", "serve"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		setting   string
+		got, want any
+	}{
+		{"APP_PORT from the file", config.AppPort, 7070},
+		{"LOG_LEVEL from the environment over .env and the file", config.LogLevel, "debug"},
+		{"CACHE_TTL from .env over the file", config.CacheTTL, 2 * time.Minute},
+		{"DB_NAME from a flag over the environment and the file", config.DBName, "flag_db"},
+		{"JWT_SECRET from the file named by the environment", config.JWTSecret, "secret-from-file"},
+		{"DB_PORT derived from DB_DRIVER", config.DBPort, 3306},
+		{"DB_HOST by default", config.DBHost, "localhost"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.setting, tt.got, tt.want)
+		}
+	}
+	if !slices.Equal(args, []string{"serve"}) {
+		t.Errorf("args = %q, want [serve]", args)
+	}
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"itv/pkg/ratelimit"
+)
+
+const (
+	defaultJWTSecret     = "default_secret_key"
+	defaultAdminPassword = "adminpassword"
+	defaultDBPassword    = "postgres"
+
+	minJWTSecretLength     = 32
+	minAdminPasswordLength = 12
+)
+
+var (
+	appEnvs        = []string{"development", "test", "staging", "production"}
+	logLevels      = []string{"debug", "info", "warn", "error"}
+	dbDrivers      = []string{"postgres", "mysql", "sqlite"}
+	dbSSLModes     = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	storageDrivers = []string{"local", "s3"}
+	cacheDrivers   = []string{"memory", "redis", "none"}
+)
+
+// validate checks the loaded values against each other and against the
+// production requirements. It returns every problem it finds.
+func (c *Config) validate() []error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, &settingError{Key: key, Err: fmt.Errorf(format, args...)})
+	}
+
+	oneOf := func(key, value string, allowed []string) {
+		if !slices.Contains(allowed, value) {
+			fail(key, "%q must be one of %s", value, strings.Join(allowed, ", "))
+		}
+	}
+	oneOf("APP_ENV", c.AppEnv, appEnvs)
+	oneOf("LOG_LEVEL", c.LogLevel, logLevels)
+	oneOf("DB_DRIVER", c.DBDriver, dbDrivers)
+	oneOf("DB_SSL_MODE", c.DBSSLMode, dbSSLModes)
+	oneOf("STORAGE_DRIVER", c.StorageDriver, storageDrivers)
+	oneOf("CACHE_DRIVER", c.CacheDriver, cacheDrivers)
+
+	port := func(key string, value int) {
+		if value < 1 || value > 65535 {
+			fail(key, "%d is not a port between 1 and 65535", value)
+		}
+	}
+	port("APP_PORT", c.AppPort)
+	port("DB_PORT", c.DBPort)
+
+	positive := func(key string, value int64) {
+		if value <= 0 {
+			fail(key, "must be greater than zero")
+		}
+	}
+	positive("JWT_EXPIRATION", int64(c.JWTExpiry))
+	positive("MAX_BODY_SIZE", c.MaxBodySize)
+	positive("MEDIA_MAX_SIZE", c.MediaMaxSize)
+	positive("CACHE_TTL", int64(c.CacheTTL))
+	positive("CACHE_SIZE", int64(c.CacheSize))
+	positive("LOGIN_MAX_FAILURES", int64(c.LoginMaxFailures))
+
+	nonNegative := func(key string, value time.Duration) {
+		if value < 0 {
+			fail(key, "must not be negative")
+		}
+	}
+	nonNegative("HSTS_MAX_AGE", c.HSTSMaxAge)
+	nonNegative("LOGIN_FAILURE_DELAY", c.LoginFailureDelay)
+	nonNegative("LOGIN_LOCKOUT", c.LoginLockout)
+	nonNegative("CORS_MAX_AGE", c.CORSMaxAge)
+	nonNegative("DB_CONN_MAX_LIFETIME", c.DBConnMaxLifetime)
+	nonNegative("DB_CONN_MAX_IDLE_TIME", c.DBConnMaxIdleTime)
+	nonNegative("DB_CONNECT_BACKOFF", c.DBConnectBackoff)
+	for _, count := range []struct {
+		key   string
+		value int
+	}{
+		{"DB_MAX_OPEN_CONNS", c.DBMaxOpenConns},
+		{"DB_MAX_IDLE_CONNS", c.DBMaxIdleConns},
+		{"DB_CONNECT_RETRIES", c.DBConnectRetries},
+		{"REDIS_DB", c.RedisDB},
+		{"SEED_FAKE_MOVIES", c.SeedFakeMovies},
+	} {
+		if count.value < 0 {
+			fail(count.key, "must not be negative")
+		}
+	}
+
+	required := func(key, value string) {
+		if strings.TrimSpace(value) == "" {
+			fail(key, "must be set")
+		}
+	}
+	if c.DBDriver == "sqlite" {
+		required("DB_PATH", c.DBPath)
+		if len(c.DBReplicas) > 0 {
+			fail("DB_REPLICAS", "read replicas are not supported with the sqlite driver")
+		}
+	} else {
+		required("DB_HOST", c.DBHost)
+		required("DB_NAME", c.DBName)
+		required("DB_USER", c.DBUser)
+	}
+	required("JWT_SECRET", c.JWTSecret)
+	required("ADMIN_USERNAME", c.AdminUser)
+	required("ADMIN_PASSWORD", c.AdminPass)
+	switch c.StorageDriver {
+	case "local":
+		required("MEDIA_DIR", c.MediaDir)
+	case "s3":
+		required("S3_ENDPOINT", c.S3Endpoint)
+		required("S3_BUCKET", c.S3Bucket)
+		required("S3_ACCESS_KEY", c.S3AccessKey)
+		required("S3_SECRET_KEY", c.S3SecretKey)
+	}
+	if c.CacheDriver == "redis" {
+		required("REDIS_ADDR", c.RedisAddr)
+	}
+
+	for _, limit := range []struct{ key, value string }{
+		{"LOGIN_IP_LIMIT", c.LoginIPLimit},
+		{"LOGIN_USER_LIMIT", c.LoginUserLimit},
+	} {
+		if _, err := ratelimit.ParseLimit(limit.value); err != nil {
+			fail(limit.key, "%v", err)
+		}
+	}
+	for _, policy := range []struct{ key, value string }{
+		{"RATE_LIMIT_DEFAULT", c.RateLimitDefault},
+		{"RATE_LIMIT_AUTH", c.RateLimitAuth},
+		{"RATE_LIMIT_MOVIES", c.RateLimitMovies},
+		{"RATE_LIMIT_SEARCH", c.RateLimitSearch},
+		{"RATE_LIMIT_GENRES", c.RateLimitGenres},
+		{"RATE_LIMIT_PEOPLE", c.RateLimitPeople},
+		{"RATE_LIMIT_REVIEWS", c.RateLimitReviews},
+		{"RATE_LIMIT_ME", c.RateLimitMe},
+		{"RATE_LIMIT_ADMIN", c.RateLimitAdmin},
+	} {
+		if _, err := ratelimit.ParsePolicy(policy.value); err != nil {
+			fail(policy.key, "%v", err)
+		}
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				fail("TRUSTED_PROXIES", "%q is not an IP address or CIDR range", proxy)
+			}
+		}
+	}
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		fail("CORS_ALLOWED_ORIGINS", `"*" cannot be combined with CORS_ALLOW_CREDENTIALS`)
+	}
+
+	if c.IsProduction() {
+		for _, problem := range c.secretProblems() {
+			fail(problem.key, "%s", problem.message)
+		}
+	}
+	return errs
+}
+
+// warnings returns the secret problems outside production, where they are
+// tolerated to keep local setups simple.
+func (c *Config) warnings() []string {
+	if c.IsProduction() {
+		return nil
+	}
+	var warnings []string
+	for _, problem := range c.secretProblems() {
+		warnings = append(warnings, fmt.Sprintf("%s: %s (refused when APP_ENV=production)", problem.key, problem.message))
+	}
+	return warnings
+}
+
+type secretProblem struct {
+	key     string
+	message string
+}
+
+// secretProblems reports default and weak secrets.
+func (c *Config) secretProblems() []secretProblem {
+	var problems []secretProblem
+	add := func(key, message string) {
+		problems = append(problems, secretProblem{key, message})
+	}
+
+	switch {
+	case c.JWTSecret == defaultJWTSecret:
+		add("JWT_SECRET", "uses the built-in default")
+	case len(c.JWTSecret) < minJWTSecretLength:
+		add("JWT_SECRET", fmt.Sprintf("must be at least %d characters long", minJWTSecretLength))
+	}
+
+	switch {
+	case c.AdminPass == defaultAdminPassword:
+		add("ADMIN_PASSWORD", "uses the built-in default")
+	default:
+		if message := weakPassword(c.AdminPass, c.AdminUser); message != "" {
+			add("ADMIN_PASSWORD", message)
+		}
+	}
+
+	if c.DBDriver != "sqlite" && c.DBPassword == defaultDBPassword {
+		add("DB_PASSWORD", "uses the built-in default")
+	}
+	return problems
+}
+
+// weakPassword describes why password is too weak for the admin account, or
+// returns an empty string when it is acceptable.
+func weakPassword(password, username string) string {
+	if len(password) < minAdminPasswordLength {
+		return fmt.Sprintf("must be at least %d characters long", minAdminPasswordLength)
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return "must not contain the admin username"
+	}
+
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return "must mix at least three of lowercase, uppercase, digits and symbols"
+	}
+	return ""
+}
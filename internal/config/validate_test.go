@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// defaults returns the configuration made of the default values only.
+func defaults(t *testing.T) *Config {
+	t.Helper()
+	config := &Config{}
+	set, errs := load(config, nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	config.applyDerivedDefaults(set)
+	return config
+}
+
+// errorKeys returns the settings that errs report, in order.
+func errorKeys(errs []error) []string {
+	var keys []string
+	for _, err := range errs {
+		var setting *settingError
+		if errors.As(err, &setting) {
+			keys = append(keys, setting.Key)
+		} else {
+			keys = append(keys, err.Error())
+		}
+	}
+	return keys
+}
+
+func TestValidate(t *testing.T) {
+	const strongSecret = "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		wantKeys []string
+	}{
+		{"defaults", func(c *Config) {}, nil},
+		{"unknown choices", func(c *Config) {
+			c.AppEnv, c.LogLevel, c.DBDriver, c.CacheDriver = "prod", "verbose", "oracle", "memcached"
+		}, []string{"APP_ENV", "LOG_LEVEL", "DB_DRIVER", "CACHE_DRIVER"}},
+		{"ports out of range", func(c *Config) { c.AppPort, c.DBPort = 0, 70000 }, []string{"APP_PORT", "DB_PORT"}},
+		{"zero sizes", func(c *Config) { c.MaxBodySize, c.CacheSize = 0, -1 }, []string{"MAX_BODY_SIZE", "CACHE_SIZE"}},
+		{"negative durations", func(c *Config) { c.LoginLockout, c.CORSMaxAge = -1, -1 }, []string{"LOGIN_LOCKOUT", "CORS_MAX_AGE"}},
+		{"negative counts", func(c *Config) { c.DBConnectRetries, c.SeedFakeMovies = -1, -1 }, []string{"DB_CONNECT_RETRIES", "SEED_FAKE_MOVIES"}},
+		{"missing database host", func(c *Config) { c.DBHost, c.DBName = " ", "" }, []string{"DB_HOST", "DB_NAME"}},
+		{"sqlite without a path", func(c *Config) { c.DBDriver, c.DBPath, c.DBHost = "sqlite", "", "" }, []string{"DB_PATH"}},
+		{"sqlite with replicas", func(c *Config) {
+			c.DBDriver, c.DBReplicas = "sqlite", []string{"file:replica.db"}
+		}, []string{"DB_REPLICAS"}},
+		{"s3 without credentials", func(c *Config) { c.StorageDriver = "s3" }, []string{"S3_ACCESS_KEY", "S3_SECRET_KEY"}},
+		{"redis without an address", func(c *Config) { c.CacheDriver, c.RedisAddr = "redis", "" }, []string{"REDIS_ADDR"}},
+		{"invalid limits", func(c *Config) {
+			c.LoginIPLimit, c.RateLimitSearch = "10", "user=fast"
+		}, []string{"LOGIN_IP_LIMIT", "RATE_LIMIT_SEARCH"}},
+		{"trusted proxies", func(c *Config) {
+			c.TrustedProxies = []string{"10.0.0.1", "10.0.0.0/8", "::1", "proxy.local"}
+		}, []string{"TRUSTED_PROXIES"}},
+		{"any origin with credentials", func(c *Config) {
+			c.CORSAllowedOrigins, c.CORSAllowCredentials = []string{"*"}, true
+		}, []string{"CORS_ALLOWED_ORIGINS"}},
+		{"default secrets in production", func(c *Config) { c.AppEnv = "production" },
+			[]string{"JWT_SECRET", "ADMIN_PASSWORD", "DB_PASSWORD"}},
+		{"strong secrets in production", func(c *Config) {
+			c.AppEnv, c.JWTSecret, c.AdminPass, c.DBPassword = "production", strongSecret, "Correct-Horse-42", "db-password"
+		}, nil},
+		{"short secret in production", func(c *Config) {
+			c.AppEnv, c.JWTSecret, c.AdminPass, c.DBPassword = "production", "short", "Correct-Horse-42", "db-password"
+		}, []string{"JWT_SECRET"}},
+		{"sqlite ignores the database password", func(c *Config) {
+			c.AppEnv, c.JWTSecret, c.AdminPass, c.DBDriver = "production", strongSecret, "Correct-Horse-42", "sqlite"
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaults(t)
+			tt.modify(config)
+			if got := errorKeys(config.validate()); !slices.Equal(got, tt.wantKeys) {
+				t.Errorf("validate reported %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	config := defaults(t)
+	warnings := config.warnings()
+	if len(warnings) != 3 {
+		t.Fatalf("warnings = %q, want one each for JWT_SECRET, ADMIN_PASSWORD and DB_PASSWORD", warnings)
+	}
+	for _, warning := range warnings {
+		if !strings.HasSuffix(warning, "(refused when APP_ENV=production)") {
+			t.Errorf("warning %q does not mention production", warning)
+		}
+	}
+
+	config.AppEnv = "production"
+	if warnings := config.warnings(); warnings != nil {
+		t.Errorf("warnings in production = %q, want them reported as errors", warnings)
+	}
+}
+
+func TestWeakPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		weak     bool
+	}{
+		{"Correct-Horse-42", false},
+		{"correcthorse42!", false},
+		{"CORRECTHORSE42", true},
+		{"Short-4", true},
+		{"correcthorsebattery", true},
+		{"My-Admin-Pass-1", true},
+		{"Пароль-надёжный-1", false},
+	}
+	for _, tt := range tests {
+		if got := weakPassword(tt.password, "admin"); (got != "") != tt.weak {
+			t.Errorf("weakPassword(%q) = %q, want weak %t", tt.password, got, tt.weak)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	values := map[string]string{"APP_PORT": "http", "CACHE_TTL": "1m", "CACHE_SIZE": "many"}
+	config := &Config{}
+	set, errs := load(config, []source{mapSource("test", values)})
+	if got, want := errorKeys(errs), []string{"APP_PORT", "CACHE_SIZE"}; !slices.Equal(got, want) {
+		t.Errorf("load reported %v, want %v", got, want)
+	}
+	if !set["CACHE_TTL"] || set["APP_ENV"] {
+		t.Errorf("set = %v, want only the provided keys", set)
+	}
+	// Values that do not parse fall back to their defaults.
+	if config.AppPort != 8080 || config.CacheSize != 1000 || config.CacheTTL != time.Minute || config.AppEnv != "development" {
+		t.Errorf("config = %+v, want the provided values with defaults for the rest", config)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	_, _, err := LoadConfig([]string{"--app-port", "http", "--log-level", "loud", "--cache-size", "0", "print"})
+	var invalid *InvalidError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("LoadConfig error = %v, want an *InvalidError", err)
+	}
+	// A value that does not parse is not also reported as out of range.
+	if got, want := errorKeys(invalid.Errs), []string{"APP_PORT", "LOG_LEVEL", "CACHE_SIZE"}; !slices.Equal(got, want) {
+		t.Errorf("LoadConfig reported %v, want %v", got, want)
+	}
+	if invalid.Config == nil || invalid.Config.LogLevel != "loud" || invalid.Config.AppPort != 8080 {
+		t.Errorf("Config = %+v, want the values read with defaults for the rest", invalid.Config)
+	}
+	if !slices.Equal(invalid.Args, []string{"print"}) {
+		t.Errorf("Args = %q, want [print]", invalid.Args)
+	}
+}
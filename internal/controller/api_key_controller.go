@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService *service.APIKeyService) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// GetAllAPIKeys godoc
+//	@Summary		Get all API keys
+//	@Description	List the API keys, newest first, including revoked and expired ones. The keys themselves are not shown, only their prefixes (admin only)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		dto.APIKeyResponseDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/admin/api-keys [get]
+func (c *APIKeyController) GetAllAPIKeys(ctx *gin.Context) {
+	keys, err := c.apiKeyService.GetAllAPIKeys()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey godoc
+//	@Summary		Create an API key
+//	@Description	Issue an API key that grants the given permissions to programs sending it in the X-API-Key header. The key is only returned in this response. Without expires_at it is valid until revoked (admin only)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			key	body		dto.CreateAPIKeyDTO	true	"API key data"
+//	@Success		201	{object}	dto.CreatedAPIKeyResponseDTO
+//	@Failure		400	{object}	dto.ProblemDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Router			/admin/api-keys [post]
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	var keyDTO dto.CreateAPIKeyDTO
+	if err := ctx.ShouldBindJSON(&keyDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	key, err := c.apiKeyService.CreateAPIKey(userID, keyDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey godoc
+//	@Summary		Revoke an API key
+//	@Description	Stop accepting an API key from the next request on. The key stays listed with the time it was revoked (admin only)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"API key ID"
+//	@Success		204	"No Content"
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/admin/api-keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.apiKeyService.RevokeAPIKey(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
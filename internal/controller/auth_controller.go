@@ -2,6 +2,7 @@ package controller
 
 import (
 	"github.com/gin-gonic/gin"
+	"itv/internal/apperror"
 	"itv/internal/dto"
 	"itv/internal/service"
 	"net/http"
@@ -25,19 +26,21 @@ func NewAuthController(authService *service.AuthService) *AuthController {
 //	@Produce		json
 //	@Param			login	body		dto.LoginDTO	true	"Login credentials"
 //	@Success		200		{object}	dto.TokenResponseDTO
-//	@Failure		400		{object}	dto.ErrorResponseDTO
-//	@Failure		401		{object}	dto.ErrorResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		401		{object}	dto.ProblemDTO
+//	@Failure		429		{object}	dto.ProblemDTO
+//	@Header			429		{integer}	Retry-After	"Seconds to wait before the next attempt"
 //	@Router			/auth/login [post]
 func (c *AuthController) Login(ctx *gin.Context) {
 	var loginDTO dto.LoginDTO
 	if err := ctx.ShouldBindJSON(&loginDTO); err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(apperror.FromBinding(err))
 		return
 	}
 
-	token, err := c.authService.Login(loginDTO)
+	token, err := c.authService.Login(loginDTO, ctx.ClientIP())
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"itv/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheController struct {
+	movieCache *service.MovieCache
+}
+
+func NewCacheController(movieCache *service.MovieCache) *CacheController {
+	return &CacheController{
+		movieCache: movieCache,
+	}
+}
+
+// GetCacheStats godoc
+//	@Summary		Get movie cache statistics
+//	@Description	Get the hit and miss counts of the movie cache since the server started (admin only)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	dto.CacheStatsDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Router			/admin/cache [get]
+func (c *CacheController) GetCacheStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.movieCache.Stats())
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GenreController struct {
+	genreService *service.GenreService
+}
+
+func NewGenreController(genreService *service.GenreService) *GenreController {
+	return &GenreController{
+		genreService: genreService,
+	}
+}
+
+// GetAllGenres godoc
+//	@Summary		Get all genres
+//	@Description	Get a list of all genres ordered by name
+//	@Tags			genres
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		dto.GenreResponseDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/genres [get]
+func (c *GenreController) GetAllGenres(ctx *gin.Context) {
+	genres, err := c.genreService.GetAllGenres()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, genres)
+}
+
+// GetGenreByID godoc
+//	@Summary		Get a genre by ID
+//	@Description	Get a genre by its ID
+//	@Tags			genres
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Genre ID"
+//	@Success		200	{object}	dto.GenreResponseDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/genres/{id} [get]
+func (c *GenreController) GetGenreByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	genre, err := c.genreService.GetGenreByID(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, genre)
+}
+
+// CreateGenre godoc
+//	@Summary		Create a new genre
+//	@Description	Create a new genre with a unique name
+//	@Tags			genres
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			genre	body		dto.CreateGenreDTO	true	"Genre data"
+//	@Success		201		{object}	dto.GenreResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		409		{object}	dto.ProblemDTO
+//	@Failure		500		{object}	dto.ProblemDTO
+//	@Router			/genres [post]
+func (c *GenreController) CreateGenre(ctx *gin.Context) {
+	var genreDTO dto.CreateGenreDTO
+	if err := ctx.ShouldBindJSON(&genreDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	genre, err := c.genreService.CreateGenre(genreDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, genre)
+}
+
+// UpdateGenre godoc
+//	@Summary		Update a genre
+//	@Description	Update a genre with the provided data
+//	@Tags			genres
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"Genre ID"
+//	@Param			genre	body		dto.UpdateGenreDTO	true	"Genre data"
+//	@Success		200		{object}	dto.GenreResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Failure		409		{object}	dto.ProblemDTO
+//	@Router			/genres/{id} [put]
+func (c *GenreController) UpdateGenre(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var genreDTO dto.UpdateGenreDTO
+	if err := ctx.ShouldBindJSON(&genreDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	genre, err := c.genreService.UpdateGenre(uint(id), genreDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, genre)
+}
+
+// DeleteGenre godoc
+//	@Summary		Delete a genre
+//	@Description	Delete a genre and detach it from all movies
+//	@Tags			genres
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path	int	true	"Genre ID"
+//	@Success		204	"No Content"
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/genres/{id} [delete]
+func (c *GenreController) DeleteGenre(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.genreService.DeleteGenre(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"itv/internal/apperror"
+	"itv/internal/service"
+	"itv/pkg/locale"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidID       = apperror.Validation("invalid_id", "invalid ID format")
+	errUnauthenticated = apperror.Unauthorized("unauthorized", "authentication is required")
+)
+
+// currentUserID returns the authenticated user's ID that AuthMiddleware.JWTAuth
+// stored in the context.
+func currentUserID(ctx *gin.Context) (uint, bool) {
+	value, exists := ctx.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	userID, ok := value.(uint)
+	return userID, ok
+}
+
+// hasPermission reports whether the request holds permission, through the
+// API key it was made with or else the user's role.
+func hasPermission(ctx *gin.Context, roleService *service.RoleService, permission string) (bool, error) {
+	if permissions, ok := ctx.Get("apiKeyPermissions"); ok {
+		return slices.Contains(permissions.([]string), permission), nil
+	}
+	return roleService.HasPermission(ctx.GetString("role"), permission)
+}
+
+// currentGrantor returns who the request acts as when it changes or assigns
+// roles: the API key it was made with or else the user's role.
+func currentGrantor(ctx *gin.Context) service.Grantor {
+	if permissions, ok := ctx.Get("apiKeyPermissions"); ok {
+		return service.Grantor{Permissions: permissions.([]string)}
+	}
+	return service.Grantor{Role: ctx.GetString("role")}
+}
+
+// languagePreferences reads the languages the client prefers for movie titles
+// and plots from the lang query parameter or the Accept-Language header.
+func languagePreferences(ctx *gin.Context) locale.Preferences {
+	ctx.Writer.Header().Add("Vary", "Accept-Language")
+	return locale.ParsePreferences(ctx.Query("lang"), ctx.GetHeader("Accept-Language"))
+}
+
+// notModified sets a weak ETag and Last-Modified for a listing of count rows
+// whose latest change was at lastModified, and answers 304 Not Modified when
+// the client's copy is still current. The requested languages are part of the
+// tag since they change the representation. If-Modified-Since is only
+// consulted without If-None-Match, as RFC 9110 requires.
+func notModified(ctx *gin.Context, count int64, lastModified time.Time) bool {
+	hash := sha1.New()
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(count))
+	binary.BigEndian.PutUint64(buf[8:], uint64(lastModified.UnixNano()))
+	hash.Write(buf[:])
+	hash.Write([]byte(ctx.Query("lang") + "\x00" + ctx.GetHeader("Accept-Language")))
+	etag := `W/"` + hex.EncodeToString(hash.Sum(nil)[:10]) + `"`
+
+	ctx.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	current := false
+	if match := ctx.GetHeader("If-None-Match"); match != "" {
+		current = etagMatches(match, etag)
+	} else if since, err := http.ParseTime(ctx.GetHeader("If-Modified-Since")); err == nil && !lastModified.IsZero() {
+		current = !lastModified.Truncate(time.Second).After(since)
+	}
+	if current {
+		ctx.Status(http.StatusNotModified)
+	}
+	return current
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, using the
+// weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
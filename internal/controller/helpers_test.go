@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `W/"abc"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`W/"abc"`, true},
+		{`"abc"`, true},
+		{`"xyz", W/"abc"`, true},
+		{`"xyz",W/"abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{`W/"abcd"`, false},
+		{`abc`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %t, want %t", tt.header, got, tt.want)
+		}
+	}
+}
+
+// listingETag returns the ETag that notModified sets for a listing.
+func listingETag(t *testing.T, count int64, lastModified time.Time, query string, header http.Header) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/movies"+query, nil)
+	ctx.Request.Header = header
+	notModified(ctx, count, lastModified)
+	return rec.Header().Get("ETag")
+}
+
+func TestNotModified(t *testing.T) {
+	changed := time.Date(2024, 5, 1, 12, 0, 0, 500_000_000, time.UTC)
+	etag := listingETag(t, 3, changed, "", http.Header{})
+	tests := []struct {
+		name         string
+		count        int64
+		lastModified time.Time
+		header       http.Header
+		want         bool
+	}{
+		{"no validators", 3, changed, http.Header{}, false},
+		{"matching ETag", 3, changed, http.Header{"If-None-Match": {etag}}, true},
+		{"ETag after a new movie", 4, changed, http.Header{"If-None-Match": {etag}}, false},
+		{"ETag after a change", 3, changed.Add(time.Second), http.Header{"If-None-Match": {etag}}, false},
+		{"ETag in another language", 3, changed, http.Header{"If-None-Match": {etag}, "Accept-Language": {"ru"}}, false},
+		{"any ETag", 3, changed, http.Header{"If-None-Match": {"*"}}, true},
+		{"unchanged since", 3, changed, http.Header{"If-Modified-Since": {changed.Format(http.TimeFormat)}}, true},
+		{"changed since", 3, changed, http.Header{"If-Modified-Since": {changed.Add(-time.Second).Format(http.TimeFormat)}}, false},
+		{"unparsable date", 3, changed, http.Header{"If-Modified-Since": {"yesterday"}}, false},
+		{"date without a change", 0, time.Time{}, http.Header{"If-Modified-Since": {changed.Format(http.TimeFormat)}}, false},
+		// If-Modified-Since is ignored when If-None-Match is present.
+		{"ETag takes precedence", 3, changed, http.Header{
+			"If-None-Match":     {`W/"other"`},
+			"If-Modified-Since": {changed.Format(http.TimeFormat)},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ctx, router := gin.CreateTestContext(rec)
+			router.GET("/movies", func(ctx *gin.Context) {
+				if !notModified(ctx, tt.count, tt.lastModified) {
+					ctx.String(http.StatusOK, "movies")
+				}
+			})
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/movies", nil)
+			ctx.Request.Header = tt.header
+			router.HandleContext(ctx)
+
+			wantStatus := http.StatusOK
+			if tt.want {
+				wantStatus = http.StatusNotModified
+			}
+			if rec.Code != wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, wantStatus)
+			}
+			if rec.Header().Get("ETag") == "" {
+				t.Error("ETag is missing")
+			}
+			wantLastModified := ""
+			if !tt.lastModified.IsZero() {
+				wantLastModified = tt.lastModified.Format(http.TimeFormat)
+			}
+			if got := rec.Header().Get("Last-Modified"); got != wantLastModified {
+				t.Errorf("Last-Modified = %q, want %q", got, wantLastModified)
+			}
+		})
+	}
+
+	if etag != listingETag(t, 3, changed, "", http.Header{}) {
+		t.Error("ETag differs between identical listings")
+	}
+	if etag == listingETag(t, 3, changed, "?lang=ru", http.Header{}) {
+		t.Error("ETag does not depend on the lang parameter")
+	}
+}
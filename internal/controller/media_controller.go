@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"errors"
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for form fields and part headers on top of
+// the file size limit.
+const multipartOverhead = 1 << 20
+
+type MediaController struct {
+	mediaService *service.MediaService
+}
+
+func NewMediaController(mediaService *service.MediaService) *MediaController {
+	return &MediaController{
+		mediaService: mediaService,
+	}
+}
+
+// GetMovieMedia godoc
+//	@Summary		Get movie media
+//	@Description	Get the poster, backdrop and stills of a movie with their thumbnails
+//	@Tags			media
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Movie ID"
+//	@Success		200	{array}		dto.MediaResponseDTO
+//	@Failure		400	{object}	dto.ProblemDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/media [get]
+func (c *MediaController) GetMovieMedia(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	media, err := c.mediaService.GetMovieMedia(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, media)
+}
+
+// UploadMedia godoc
+//	@Summary		Upload movie media
+//	@Description	Upload a JPEG, PNG or WebP poster, backdrop or still (requires movies:write). Thumbnails are generated; a new poster or backdrop replaces the previous one
+//	@Tags			media
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int		true	"Movie ID"
+//	@Param			kind	formData	string	true	"Media kind"	Enums(poster, backdrop, still)
+//	@Param			file	formData	file	true	"Image file"
+//	@Success		201		{object}	dto.MediaResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		401		{object}	dto.ProblemDTO
+//	@Failure		403		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Failure		413		{object}	dto.ProblemDTO
+//	@Failure		415		{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/media [post]
+func (c *MediaController) UploadMedia(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.mediaService.MaxSize()+multipartOverhead)
+
+	var uploadDTO dto.UploadMediaDTO
+	if err := ctx.ShouldBind(&uploadDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		ctx.Error(apperror.InvalidField("file", "required", ""))
+		return
+	}
+	if err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+	if fileHeader.Size > c.mediaService.MaxSize() {
+		ctx.Error(service.ErrMediaTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	defer file.Close()
+
+	media, err := c.mediaService.UploadMedia(ctx.Request.Context(), uint(id), uploadDTO.Kind, file)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, media)
+}
+
+// DeleteMedia godoc
+//	@Summary		Delete movie media
+//	@Description	Delete a poster, backdrop or still and its thumbnails (requires movies:write)
+//	@Tags			media
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id		path	int	true	"Movie ID"
+//	@Param			mediaId	path	int	true	"Media ID"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		401		{object}	dto.ProblemDTO
+//	@Failure		403		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/media/{mediaId} [delete]
+func (c *MediaController) DeleteMedia(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	mediaID, err := strconv.ParseUint(ctx.Param("mediaId"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.mediaService.DeleteMedia(uint(id), uint(mediaID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"itv/internal/apperror"
 	"itv/internal/dto"
 	"itv/internal/service"
 	"net/http"
@@ -21,18 +22,49 @@ func NewMovieController(movieService *service.MovieService) *MovieController {
 
 // GetAllMovies godoc
 //	@Summary		Get all movies
-//	@Description	Get a list of all movies
+//	@Description	Get a list of all movies, optionally filtered by genres, tags, release window and country
 //	@Tags			movies
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{array}		dto.MovieResponseDTO
-//	@Failure		500	{object}	dto.ErrorResponseDTO
+//	@Security		ApiKeyAuth
+//	@Param			genre	query		[]string	false	"Genre names (repeated or comma-separated)"	collectionFormat(multi)
+//	@Param			tag		query		[]string	false	"Tag names (repeated or comma-separated)"	collectionFormat(multi)
+//	@Param			match	query		string		false	"Match any or all of the genres and tags"	Enums(any, all)
+//	@Param			released_from	query	string	false	"Earliest release date (YYYY-MM-DD)"
+//	@Param			released_to		query	string	false	"Latest release date (YYYY-MM-DD)"
+//	@Param			country			query	string	false	"Only regional releases in this country (ISO 3166-1 alpha-2); the release window then applies to them"
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
+//	@Param			If-None-Match		header	string	false	"ETag of a cached copy"
+//	@Param			If-Modified-Since	header	string	false	"Last-Modified of a cached copy"
+//	@Success		200		{array}		dto.MovieResponseDTO
+//	@Header			200		{string}	ETag			"Weak validator of the listing"
+//	@Header			200		{string}	Last-Modified	"Latest change among the listed movies"
+//	@Success		304		"The cached copy is current"
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		500		{object}	dto.ProblemDTO
 //	@Router			/movies [get]
 func (c *MovieController) GetAllMovies(ctx *gin.Context) {
-	movies, err := c.movieService.GetAllMovies()
+	var filterDTO dto.MovieFilterDTO
+	if err := ctx.ShouldBindQuery(&filterDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	count, lastModified, err := c.movieService.GetMoviesVersion(filterDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	prefs := languagePreferences(ctx)
+	if notModified(ctx, count, lastModified) {
+		return
+	}
+
+	movies, err := c.movieService.GetAllMovies(filterDTO, prefs)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -46,21 +78,24 @@ func (c *MovieController) GetAllMovies(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Movie ID"
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
 //	@Success		200	{object}	dto.MovieResponseDTO
-//	@Failure		404	{object}	dto.ErrorResponseDTO
-//	@Failure		500	{object}	dto.ErrorResponseDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
 //	@Router			/movies/{id} [get]
 func (c *MovieController) GetMovieByID(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.ErrorResponseDTO{Error: "invalid ID format"})
+		ctx.Error(errInvalidID)
 		return
 	}
 
-	movie, err := c.movieService.GetMovieByID(uint(id))
+	movie, err := c.movieService.GetMovieByID(uint(id), languagePreferences(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -74,21 +109,22 @@ func (c *MovieController) GetMovieByID(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Param			movie	body		dto.CreateMovieDTO	true	"Movie data"
 //	@Success		201		{object}	dto.MovieResponseDTO
-//	@Failure		400		{object}	dto.ErrorResponseDTO
-//	@Failure		500		{object}	dto.ErrorResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		500		{object}	dto.ProblemDTO
 //	@Router			/movies [post]
 func (c *MovieController) CreateMovie(ctx *gin.Context) {
 	var movieDTO dto.CreateMovieDTO
 	if err := ctx.ShouldBindJSON(&movieDTO); err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(apperror.FromBinding(err))
 		return
 	}
 
 	movie, err := c.movieService.CreateMovie(movieDTO)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -102,29 +138,30 @@ func (c *MovieController) CreateMovie(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Param			id		path		int					true	"Movie ID"
 //	@Param			movie	body		dto.UpdateMovieDTO	true	"Movie data"
 //	@Success		200		{object}	dto.MovieResponseDTO
-//	@Failure		400		{object}	dto.ErrorResponseDTO
-//	@Failure		404		{object}	dto.ErrorResponseDTO
-//	@Failure		500		{object}	dto.ErrorResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Failure		500		{object}	dto.ProblemDTO
 //	@Router			/movies/{id} [put]
 func (c *MovieController) UpdateMovie(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.ErrorResponseDTO{Error: "invalid ID format"})
+		ctx.Error(errInvalidID)
 		return
 	}
 
 	var movieDTO dto.UpdateMovieDTO
 	if err := ctx.ShouldBindJSON(&movieDTO); err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(apperror.FromBinding(err))
 		return
 	}
 
 	movie, err := c.movieService.UpdateMovie(uint(id), movieDTO)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -138,21 +175,22 @@ func (c *MovieController) UpdateMovie(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Param			id	path	int	true	"Movie ID"
 //	@Success		204	"No Content"
-//	@Failure		404	{object}	dto.ErrorResponseDTO
-//	@Failure		500	{object}	dto.ErrorResponseDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
 //	@Router			/movies/{id} [delete]
 func (c *MovieController) DeleteMovie(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.ErrorResponseDTO{Error: "invalid ID format"})
+		ctx.Error(errInvalidID)
 		return
 	}
 
 	err = c.movieService.DeleteMovie(uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -161,25 +199,56 @@ func (c *MovieController) DeleteMovie(ctx *gin.Context) {
 
 // SearchMovies godoc
 //	@Summary		Search movies
-//	@Description	Search movies based on a query
+//	@Description	Search movies based on a query, optionally filtered by genres, tags, release window and country
 //	@Tags			movies
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			query	query		string	true	"Search query"
+//	@Security		ApiKeyAuth
+//	@Param			query	query		string		true	"Search query"
+//	@Param			genre	query		[]string	false	"Genre names (repeated or comma-separated)"	collectionFormat(multi)
+//	@Param			tag		query		[]string	false	"Tag names (repeated or comma-separated)"	collectionFormat(multi)
+//	@Param			match	query		string		false	"Match any or all of the genres and tags"	Enums(any, all)
+//	@Param			released_from	query	string	false	"Earliest release date (YYYY-MM-DD)"
+//	@Param			released_to		query	string	false	"Latest release date (YYYY-MM-DD)"
+//	@Param			country			query	string	false	"Only regional releases in this country (ISO 3166-1 alpha-2); the release window then applies to them"
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
+//	@Param			If-None-Match		header	string	false	"ETag of a cached copy"
+//	@Param			If-Modified-Since	header	string	false	"Last-Modified of a cached copy"
 //	@Success		200		{array}		dto.MovieResponseDTO
-//	@Failure		500		{object}	dto.ErrorResponseDTO
+//	@Header			200		{string}	ETag			"Weak validator of the listing"
+//	@Header			200		{string}	Last-Modified	"Latest change among the listed movies"
+//	@Success		304		"The cached copy is current"
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		500		{object}	dto.ProblemDTO
 //	@Router			/movies/search [get]
 func (c *MovieController) SearchMovies(ctx *gin.Context) {
 	query := ctx.Query("query")
 	if query == "" {
-		ctx.JSON(http.StatusBadRequest, dto.ErrorResponseDTO{Error: "search query is required"})
+		ctx.Error(apperror.InvalidField("query", "required", ""))
+		return
+	}
+
+	var filterDTO dto.MovieFilterDTO
+	if err := ctx.ShouldBindQuery(&filterDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	count, lastModified, err := c.movieService.SearchMoviesVersion(query, filterDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	prefs := languagePreferences(ctx)
+	if notModified(ctx, count, lastModified) {
 		return
 	}
 
-	movies, err := c.movieService.SearchMovies(query)
+	movies, err := c.movieService.SearchMovies(query, filterDTO, prefs)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponseDTO{Error: err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonController struct {
+	personService *service.PersonService
+}
+
+func NewPersonController(personService *service.PersonService) *PersonController {
+	return &PersonController{
+		personService: personService,
+	}
+}
+
+
+// GetAllPeople godoc
+//	@Summary		Get all people
+//	@Description	Get a list of people ordered by name, optionally filtered by name
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			query	query		string	false	"Name search"
+//	@Success		200		{array}		dto.PersonResponseDTO
+//	@Failure		500		{object}	dto.ProblemDTO
+//	@Router			/people [get]
+func (c *PersonController) GetAllPeople(ctx *gin.Context) {
+	people, err := c.personService.GetAllPeople(ctx.Query("query"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, people)
+}
+
+// GetPersonByID godoc
+//	@Summary		Get a person by ID
+//	@Description	Get a person by their ID
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Person ID"
+//	@Success		200	{object}	dto.PersonResponseDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/people/{id} [get]
+func (c *PersonController) GetPersonByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	person, err := c.personService.GetPersonByID(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, person)
+}
+
+// GetFilmography godoc
+//	@Summary		Get a person's filmography
+//	@Description	Get every movie credit of a person, newest movies first
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Person ID"
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
+//	@Success		200	{object}	dto.FilmographyResponseDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/people/{id}/filmography [get]
+func (c *PersonController) GetFilmography(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	filmography, err := c.personService.GetFilmography(uint(id), languagePreferences(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, filmography)
+}
+
+// CreatePerson godoc
+//	@Summary		Create a new person
+//	@Description	Create a new person who can be credited on movies
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			person	body		dto.CreatePersonDTO	true	"Person data"
+//	@Success		201		{object}	dto.PersonResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		500		{object}	dto.ProblemDTO
+//	@Router			/people [post]
+func (c *PersonController) CreatePerson(ctx *gin.Context) {
+	var personDTO dto.CreatePersonDTO
+	if err := ctx.ShouldBindJSON(&personDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	person, err := c.personService.CreatePerson(personDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, person)
+}
+
+// UpdatePerson godoc
+//	@Summary		Update a person
+//	@Description	Update a person with the provided data
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"Person ID"
+//	@Param			person	body		dto.UpdatePersonDTO	true	"Person data"
+//	@Success		200		{object}	dto.PersonResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/people/{id} [put]
+func (c *PersonController) UpdatePerson(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var personDTO dto.UpdatePersonDTO
+	if err := ctx.ShouldBindJSON(&personDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	person, err := c.personService.UpdatePerson(uint(id), personDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, person)
+}
+
+// DeletePerson godoc
+//	@Summary		Delete a person
+//	@Description	Delete a person and all of their movie credits
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path	int	true	"Person ID"
+//	@Success		204	"No Content"
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/people/{id} [delete]
+func (c *PersonController) DeletePerson(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.personService.DeletePerson(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
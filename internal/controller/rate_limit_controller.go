@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"itv/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitController struct {
+	rateLimitService *service.RateLimitService
+}
+
+func NewRateLimitController(rateLimitService *service.RateLimitService) *RateLimitController {
+	return &RateLimitController{
+		rateLimitService: rateLimitService,
+	}
+}
+
+// GetUsage godoc
+//	@Summary		Get API rate limit usage
+//	@Description	List the rate limit buckets currently in use per user, or per client IP for anonymous requests (admin only)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		dto.RateLimitUsageDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/admin/rate-limits [get]
+func (c *RateLimitController) GetUsage(ctx *gin.Context) {
+	usage, err := c.rateLimitService.GetUsage()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, usage)
+}
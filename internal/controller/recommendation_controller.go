@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultRecommendationLimit = 20
+
+type RecommendationController struct {
+	recommendationService *service.RecommendationService
+}
+
+func NewRecommendationController(recommendationService *service.RecommendationService) *RecommendationController {
+	return &RecommendationController{
+		recommendationService: recommendationService,
+	}
+}
+
+// GetRecommendations godoc
+//	@Summary		Get personal recommendations
+//	@Description	Get movies the authenticated user has not rated or watched, scored by genres, tags, people and similar users' ratings; popular movies are returned when there is no history yet
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit	query		int	false	"Maximum number of movies (1-100, default 20)"
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
+//	@Success		200		{array}		dto.RecommendationDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		401		{object}	dto.ProblemDTO
+//	@Failure		500		{object}	dto.ProblemDTO
+//	@Router			/me/recommendations [get]
+func (c *RecommendationController) GetRecommendations(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	limit, ok := parseRecommendationLimit(ctx)
+	if !ok {
+		return
+	}
+
+	recommendations, err := c.recommendationService.GetRecommendations(userID, limit, languagePreferences(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recommendations)
+}
+
+// GetSimilarMovies godoc
+//	@Summary		Get similar movies
+//	@Description	Get movies sharing genres, tags and people with the movie, or liked by the same users
+//	@Tags			movies
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int	true	"Movie ID"
+//	@Param			limit	query		int	false	"Maximum number of movies (1-100, default 20)"
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
+//	@Success		200		{array}		dto.RecommendationDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/similar [get]
+func (c *RecommendationController) GetSimilarMovies(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	limit, ok := parseRecommendationLimit(ctx)
+	if !ok {
+		return
+	}
+
+	movies, err := c.recommendationService.GetSimilarMovies(uint(id), limit, languagePreferences(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, movies)
+}
+
+func parseRecommendationLimit(ctx *gin.Context) (int, bool) {
+	value := ctx.Query("limit")
+	if value == "" {
+		return defaultRecommendationLimit, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	switch {
+	case err != nil:
+		ctx.Error(apperror.InvalidField("limit", "type", "integer"))
+		return 0, false
+	case limit < 1:
+		ctx.Error(apperror.InvalidField("limit", "min", "1"))
+		return 0, false
+	case limit > service.MaxRecommendations:
+		ctx.Error(apperror.InvalidField("limit", "max", strconv.Itoa(service.MaxRecommendations)))
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewController struct {
+	reviewService *service.ReviewService
+	roleService   *service.RoleService
+}
+
+func NewReviewController(reviewService *service.ReviewService, roleService *service.RoleService) *ReviewController {
+	return &ReviewController{
+		reviewService: reviewService,
+		roleService:   roleService,
+	}
+}
+
+// GetMovieReviews godoc
+//	@Summary		Get movie reviews
+//	@Description	Get the reviews of a movie, newest first. Hidden reviews are only listed for users with the reviews:moderate permission
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Movie ID"
+//	@Success		200	{array}		dto.ReviewResponseDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/reviews [get]
+func (c *ReviewController) GetMovieReviews(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	includeHidden, err := hasPermission(ctx, c.roleService, model.PermissionReviewsModerate)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	reviews, err := c.reviewService.GetMovieReviews(uint(id), includeHidden)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reviews)
+}
+
+// GetMyReview godoc
+//	@Summary		Get own review
+//	@Description	Get the authenticated user's review of a movie
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Movie ID"
+//	@Success		200	{object}	dto.ReviewResponseDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/reviews/me [get]
+func (c *ReviewController) GetMyReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	review, err := c.reviewService.GetUserReview(uint(id), userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, review)
+}
+
+// UpsertMyReview godoc
+//	@Summary		Rate and review a movie
+//	@Description	Create or replace the authenticated user's 1-10 score and optional review text
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int					true	"Movie ID"
+//	@Param			review	body		dto.UpsertReviewDTO	true	"Review data"
+//	@Success		200		{object}	dto.ReviewResponseDTO
+//	@Success		201		{object}	dto.ReviewResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/reviews/me [put]
+func (c *ReviewController) UpsertMyReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	var reviewDTO dto.UpsertReviewDTO
+	if err := ctx.ShouldBindJSON(&reviewDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	review, created, err := c.reviewService.UpsertReview(uint(id), userID, reviewDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if created {
+		ctx.JSON(http.StatusCreated, review)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+
+// DeleteMyReview godoc
+//	@Summary		Delete own review
+//	@Description	Delete the authenticated user's review of a movie
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"Movie ID"
+//	@Success		204	"No Content"
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/reviews/me [delete]
+func (c *ReviewController) DeleteMyReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	err = c.reviewService.DeleteUserReview(uint(id), userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// FlagReview godoc
+//	@Summary		Flag a review
+//	@Description	Report a review to the moderators. Repeated reports by the same user are ignored
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path	int					true	"Review ID"
+//	@Param			flag	body	dto.FlagReviewDTO	false	"Flag reason"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/reviews/{id}/flag [post]
+func (c *ReviewController) FlagReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	var flagDTO dto.FlagReviewDTO
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&flagDTO); err != nil {
+			ctx.Error(apperror.FromBinding(err))
+			return
+		}
+	}
+
+	err = c.reviewService.FlagReview(uint(id), userID, flagDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetFlaggedReviews godoc
+//	@Summary		Get flagged reviews
+//	@Description	Get the reviews with unresolved flags, most flagged first
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		dto.ReviewResponseDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/reviews/flagged [get]
+func (c *ReviewController) GetFlaggedReviews(ctx *gin.Context) {
+	reviews, err := c.reviewService.GetFlaggedReviews()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reviews)
+}
+
+// ModerateReview godoc
+//	@Summary		Moderate a review
+//	@Description	Hide or show a review and clear its flags. Hidden reviews do not count towards the movie's average rating
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int						true	"Review ID"
+//	@Param			moderation	body		dto.ModerateReviewDTO	true	"Moderation decision"
+//	@Success		200			{object}	dto.ReviewResponseDTO
+//	@Failure		400			{object}	dto.ProblemDTO
+//	@Failure		404			{object}	dto.ProblemDTO
+//	@Router			/reviews/{id}/moderation [put]
+func (c *ReviewController) ModerateReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var moderateDTO dto.ModerateReviewDTO
+	if err := ctx.ShouldBindJSON(&moderateDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	review, err := c.reviewService.ModerateReview(uint(id), moderateDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, review)
+}
+
+// DeleteReview godoc
+//	@Summary		Delete a review
+//	@Description	Delete any review as a moderator
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path	int	true	"Review ID"
+//	@Success		204	"No Content"
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/reviews/{id} [delete]
+func (c *ReviewController) DeleteReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.reviewService.DeleteReview(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
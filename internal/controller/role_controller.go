@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	roleService *service.RoleService
+}
+
+func NewRoleController(roleService *service.RoleService) *RoleController {
+	return &RoleController{
+		roleService: roleService,
+	}
+}
+
+// GetPermissions godoc
+//	@Summary		Get all permissions
+//	@Description	List the permissions that roles can grant (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		string
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Router			/admin/permissions [get]
+func (c *RoleController) GetPermissions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, model.Permissions)
+}
+
+// GetAllRoles godoc
+//	@Summary		Get all roles
+//	@Description	List the roles with their permissions, ordered by name (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		dto.RoleResponseDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/admin/roles [get]
+func (c *RoleController) GetAllRoles(ctx *gin.Context) {
+	roles, err := c.roleService.GetAllRoles()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roles)
+}
+
+// GetRoleByID godoc
+//	@Summary		Get a role by ID
+//	@Description	Get a role with its permissions (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Role ID"
+//	@Success		200	{object}	dto.RoleResponseDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/admin/roles/{id} [get]
+func (c *RoleController) GetRoleByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	role, err := c.roleService.GetRoleByID(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+// CreateRole godoc
+//	@Summary		Create a role
+//	@Description	Create a role with a unique lowercase name and a set of permissions. Only admins may grant permissions they do not hold themselves (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			role	body		dto.CreateRoleDTO	true	"Role data"
+//	@Success		201		{object}	dto.RoleResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		403		{object}	dto.ProblemDTO
+//	@Failure		409		{object}	dto.ProblemDTO
+//	@Router			/admin/roles [post]
+func (c *RoleController) CreateRole(ctx *gin.Context) {
+	var roleDTO dto.CreateRoleDTO
+	if err := ctx.ShouldBindJSON(&roleDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	role, err := c.roleService.CreateRole(currentGrantor(ctx), roleDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole godoc
+//	@Summary		Update a role
+//	@Description	Replace the description and permissions of a role. The admin role always holds every permission and cannot be changed. Only admins may grant permissions they do not hold themselves (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"Role ID"
+//	@Param			role	body		dto.UpdateRoleDTO	true	"Role data"
+//	@Success		200		{object}	dto.RoleResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		403		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Failure		409		{object}	dto.ProblemDTO
+//	@Router			/admin/roles/{id} [put]
+func (c *RoleController) UpdateRole(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var roleDTO dto.UpdateRoleDTO
+	if err := ctx.ShouldBindJSON(&roleDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	role, err := c.roleService.UpdateRole(currentGrantor(ctx), uint(id), roleDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+// DeleteRole godoc
+//	@Summary		Delete a role
+//	@Description	Delete a role that is not built in and that no user holds (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path	int	true	"Role ID"
+//	@Success		204	"No Content"
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Failure		409	{object}	dto.ProblemDTO
+//	@Router			/admin/roles/{id} [delete]
+func (c *RoleController) DeleteRole(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.roleService.DeleteRole(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
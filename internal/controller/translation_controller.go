@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TranslationController struct {
+	translationService *service.TranslationService
+}
+
+func NewTranslationController(translationService *service.TranslationService) *TranslationController {
+	return &TranslationController{
+		translationService: translationService,
+	}
+}
+
+// GetTranslations godoc
+//	@Summary		Get movie translations
+//	@Description	Get the translated titles and plots of a movie, one per locale
+//	@Tags			translations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Movie ID"
+//	@Success		200	{array}		dto.TranslationResponseDTO
+//	@Failure		400	{object}	dto.ProblemDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/translations [get]
+func (c *TranslationController) GetTranslations(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	translations, err := c.translationService.GetTranslations(uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, translations)
+}
+
+// SaveTranslation godoc
+//	@Summary		Create or replace a movie translation
+//	@Description	Set the title and plot of a movie in a locale (requires movies:write)
+//	@Tags			translations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int					true	"Movie ID"
+//	@Param			locale		path		string				true	"BCP 47 language tag, e.g. ru or pt-BR"
+//	@Param			translation	body		dto.TranslationDTO	true	"Translated title and plot"
+//	@Success		200			{object}	dto.TranslationResponseDTO
+//	@Failure		400			{object}	dto.ProblemDTO
+//	@Failure		401			{object}	dto.ProblemDTO
+//	@Failure		403			{object}	dto.ProblemDTO
+//	@Failure		404			{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/translations/{locale} [put]
+func (c *TranslationController) SaveTranslation(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var translationDTO dto.TranslationDTO
+	if err := ctx.ShouldBindJSON(&translationDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	translation, err := c.translationService.SaveTranslation(uint(id), ctx.Param("locale"), translationDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, translation)
+}
+
+// DeleteTranslation godoc
+//	@Summary		Delete a movie translation
+//	@Description	Delete the translation of a movie into a locale (requires movies:write)
+//	@Tags			translations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id		path	int		true	"Movie ID"
+//	@Param			locale	path	string	true	"BCP 47 language tag"
+//	@Success		204		"No Content"
+//	@Failure		400	{object}	dto.ProblemDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/movies/{id}/translations/{locale} [delete]
+func (c *TranslationController) DeleteTranslation(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.translationService.DeleteTranslation(uint(id), ctx.Param("locale"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct {
+	userService *service.UserService
+}
+
+func NewUserController(userService *service.UserService) *UserController {
+	return &UserController{
+		userService: userService,
+	}
+}
+
+// GetAllUsers godoc
+//	@Summary		Get all users
+//	@Description	List the users with their roles in the order they were created (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		dto.UserResponseDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		403	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/admin/users [get]
+func (c *UserController) GetAllUsers(ctx *gin.Context) {
+	users, err := c.userService.GetAllUsers()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, users)
+}
+
+// UpdateUserRole godoc
+//	@Summary		Change the role of a user
+//	@Description	Assign an existing role to a user, which applies from their next request. Only admins may assign or remove the admin role, and others only roles whose permissions they hold themselves, both for the new role and the one it replaces. The last admin cannot lose the admin role (requires users:manage)
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int						true	"User ID"
+//	@Param			role	body		dto.UpdateUserRoleDTO	true	"Role"
+//	@Success		200		{object}	dto.UserResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		403		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Failure		409		{object}	dto.ProblemDTO
+//	@Router			/admin/users/{id}/role [put]
+func (c *UserController) UpdateUserRole(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	var roleDTO dto.UpdateUserRoleDTO
+	if err := ctx.ShouldBindJSON(&roleDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	user, err := c.userService.SetRoleByID(currentGrantor(ctx), uint(id), roleDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
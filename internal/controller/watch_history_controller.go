@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WatchHistoryController struct {
+	historyService *service.WatchHistoryService
+}
+
+func NewWatchHistoryController(historyService *service.WatchHistoryService) *WatchHistoryController {
+	return &WatchHistoryController{
+		historyService: historyService,
+	}
+}
+
+// GetHistory godoc
+//	@Summary		Get own watch history
+//	@Description	Get the movies the authenticated user marked as watched, most recent first
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
+//	@Success		200	{array}		dto.WatchHistoryResponseDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/me/history [get]
+func (c *WatchHistoryController) GetHistory(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	history, err := c.historyService.GetHistory(userID, languagePreferences(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+// MarkWatched godoc
+//	@Summary		Mark a movie as watched
+//	@Description	Add a viewing to the authenticated user's history; watched_at defaults to now
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			entry	body		dto.MarkWatchedDTO	true	"Watched movie"
+//	@Success		201		{object}	dto.WatchHistoryResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/me/history [post]
+func (c *WatchHistoryController) MarkWatched(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	var watchedDTO dto.MarkWatchedDTO
+	if err := ctx.ShouldBindJSON(&watchedDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	entry, err := c.historyService.MarkWatched(userID, watchedDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// DeleteEntry godoc
+//	@Summary		Delete a watch history entry
+//	@Description	Remove one viewing from the authenticated user's history
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"History entry ID"
+//	@Success		204	"No Content"
+//	@Failure		404	{object}	dto.ProblemDTO
+//	@Router			/me/history/{id} [delete]
+func (c *WatchHistoryController) DeleteEntry(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.historyService.DeleteEntry(userID, uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
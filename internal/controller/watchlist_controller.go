@@ -0,0 +1,296 @@
+package controller
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WatchlistController struct {
+	watchlistService *service.WatchlistService
+}
+
+func NewWatchlistController(watchlistService *service.WatchlistService) *WatchlistController {
+	return &WatchlistController{
+		watchlistService: watchlistService,
+	}
+}
+
+// GetWatchlists godoc
+//	@Summary		Get own watchlists
+//	@Description	Get the authenticated user's watchlists with item counts, the default list first
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		dto.WatchlistResponseDTO
+//	@Failure		401	{object}	dto.ProblemDTO
+//	@Failure		500	{object}	dto.ProblemDTO
+//	@Router			/me/watchlists [get]
+func (c *WatchlistController) GetWatchlists(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	watchlists, err := c.watchlistService.GetWatchlists(userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, watchlists)
+}
+
+// GetWatchlist godoc
+//	@Summary		Get a watchlist
+//	@Description	Get one of the authenticated user's watchlists with its movies in order
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			listId	path		string	true	"Watchlist ID or \"default\""
+//	@Param			lang	query		string	false	"Preferred languages for titles and plots (BCP 47, comma-separated); overrides Accept-Language"
+//	@Param			Accept-Language	header	string	false	"Preferred languages for titles and plots"
+//	@Success		200		{object}	dto.WatchlistResponseDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/me/watchlists/{listId} [get]
+func (c *WatchlistController) GetWatchlist(ctx *gin.Context) {
+	userID, listID, ok := c.parseTarget(ctx)
+	if !ok {
+		return
+	}
+
+	watchlist, err := c.watchlistService.GetWatchlist(userID, listID, languagePreferences(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, watchlist)
+}
+
+// CreateWatchlist godoc
+//	@Summary		Create a watchlist
+//	@Description	Create a named custom watchlist for the authenticated user
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			watchlist	body		dto.CreateWatchlistDTO	true	"Watchlist data"
+//	@Success		201			{object}	dto.WatchlistResponseDTO
+//	@Failure		400			{object}	dto.ProblemDTO
+//	@Failure		409			{object}	dto.ProblemDTO
+//	@Router			/me/watchlists [post]
+func (c *WatchlistController) CreateWatchlist(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return
+	}
+
+	var watchlistDTO dto.CreateWatchlistDTO
+	if err := ctx.ShouldBindJSON(&watchlistDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	watchlist, err := c.watchlistService.CreateWatchlist(userID, watchlistDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, watchlist)
+}
+
+// RenameWatchlist godoc
+//	@Summary		Rename a watchlist
+//	@Description	Rename one of the authenticated user's custom watchlists
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			listId		path		string					true	"Watchlist ID"
+//	@Param			watchlist	body		dto.UpdateWatchlistDTO	true	"Watchlist data"
+//	@Success		200			{object}	dto.WatchlistResponseDTO
+//	@Failure		400			{object}	dto.ProblemDTO
+//	@Failure		404			{object}	dto.ProblemDTO
+//	@Failure		409			{object}	dto.ProblemDTO
+//	@Router			/me/watchlists/{listId} [put]
+func (c *WatchlistController) RenameWatchlist(ctx *gin.Context) {
+	userID, listID, ok := c.parseTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var watchlistDTO dto.UpdateWatchlistDTO
+	if err := ctx.ShouldBindJSON(&watchlistDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	watchlist, err := c.watchlistService.RenameWatchlist(userID, listID, watchlistDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, watchlist)
+}
+
+// DeleteWatchlist godoc
+//	@Summary		Delete a watchlist
+//	@Description	Delete one of the authenticated user's custom watchlists
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			listId	path	string	true	"Watchlist ID"
+//	@Success		204		"No Content"
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Failure		409		{object}	dto.ProblemDTO
+//	@Router			/me/watchlists/{listId} [delete]
+func (c *WatchlistController) DeleteWatchlist(ctx *gin.Context) {
+	userID, listID, ok := c.parseTarget(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.watchlistService.DeleteWatchlist(userID, listID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// AddMovie godoc
+//	@Summary		Add a movie to a watchlist
+//	@Description	Append a movie to the end of one of the authenticated user's watchlists
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			listId	path		string					true	"Watchlist ID or \"default\""
+//	@Param			item	body		dto.AddWatchlistItemDTO	true	"Movie to add"
+//	@Success		201		{object}	dto.WatchlistResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Failure		409		{object}	dto.ProblemDTO
+//	@Router			/me/watchlists/{listId}/items [post]
+func (c *WatchlistController) AddMovie(ctx *gin.Context) {
+	userID, listID, ok := c.parseTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var itemDTO dto.AddWatchlistItemDTO
+	if err := ctx.ShouldBindJSON(&itemDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	watchlist, err := c.watchlistService.AddMovie(userID, listID, itemDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, watchlist)
+}
+
+// RemoveMovie godoc
+//	@Summary		Remove a movie from a watchlist
+//	@Description	Remove a movie from one of the authenticated user's watchlists
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			listId	path	string	true	"Watchlist ID or \"default\""
+//	@Param			movieId	path	int		true	"Movie ID"
+//	@Success		204		"No Content"
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/me/watchlists/{listId}/items/{movieId} [delete]
+func (c *WatchlistController) RemoveMovie(ctx *gin.Context) {
+	userID, listID, ok := c.parseTarget(ctx)
+	if !ok {
+		return
+	}
+
+	movieID, err := strconv.ParseUint(ctx.Param("movieId"), 10, 32)
+	if err != nil {
+		ctx.Error(errInvalidID)
+		return
+	}
+
+	err = c.watchlistService.RemoveMovie(userID, listID, uint(movieID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReorderMovies godoc
+//	@Summary		Reorder a watchlist
+//	@Description	Set the order of a watchlist by listing all of its movie IDs
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			listId	path		string					true	"Watchlist ID or \"default\""
+//	@Param			order	body		dto.ReorderWatchlistDTO	true	"Movie IDs in the new order"
+//	@Success		200		{object}	dto.WatchlistResponseDTO
+//	@Failure		400		{object}	dto.ProblemDTO
+//	@Failure		404		{object}	dto.ProblemDTO
+//	@Router			/me/watchlists/{listId}/items/order [put]
+func (c *WatchlistController) ReorderMovies(ctx *gin.Context) {
+	userID, listID, ok := c.parseTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var reorderDTO dto.ReorderWatchlistDTO
+	if err := ctx.ShouldBindJSON(&reorderDTO); err != nil {
+		ctx.Error(apperror.FromBinding(err))
+		return
+	}
+
+	watchlist, err := c.watchlistService.ReorderMovies(userID, listID, reorderDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, watchlist)
+}
+
+// parseTarget resolves the current user and the listId path parameter, where
+// "default" maps to 0. It writes the error response itself when it fails.
+func (c *WatchlistController) parseTarget(ctx *gin.Context) (uint, uint, bool) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(errUnauthenticated)
+		return 0, 0, false
+	}
+
+	if ctx.Param("listId") == "default" {
+		return userID, 0, true
+	}
+
+	listID, err := strconv.ParseUint(ctx.Param("listId"), 10, 32)
+	if err != nil || listID == 0 {
+		ctx.Error(errInvalidID)
+		return 0, 0, false
+	}
+
+	return userID, uint(listID), true
+}
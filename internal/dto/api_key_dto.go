@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+)
+
+// CreateAPIKeyDTO describes a new API key. Keys without an expiry are valid
+// until they are revoked.
+type CreateAPIKeyDTO struct {
+	Name        string     `json:"name" binding:"required,notblank,max=100"`
+	Permissions []string   `json:"permissions" binding:"required,min=1,dive,oneof=movies:write movies:delete users:manage reviews:moderate"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponseDTO struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	CreatedByID uint       `json:"created_by_id"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyResponseDTO is the response to creating a key, the only one
+// that contains the key itself.
+type CreatedAPIKeyResponseDTO struct {
+	APIKeyResponseDTO
+	Key string `json:"key"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// CacheStatsDTO counts movie cache lookups since the server started. Errors
+// are failed cache backend calls that fell back to the database.
+type CacheStatsDTO struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+	Errors   uint64  `json:"errors"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+)
+
+type CreateGenreDTO struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+type UpdateGenreDTO struct {
+	Name        string `json:"name" binding:"omitempty,max=100"`
+	Description string `json:"description"`
+}
+
+type GenreResponseDTO struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+)
+
+// UploadMediaDTO holds the form fields sent next to the "file" part.
+type UploadMediaDTO struct {
+	Kind string `form:"kind" binding:"required,oneof=poster backdrop still"`
+}
+
+type MediaThumbnailDTO struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type MediaResponseDTO struct {
+	ID          uint                `json:"id"`
+	Kind        string              `json:"kind"`
+	URL         string              `json:"url"`
+	ContentType string              `json:"content_type"`
+	Size        int64               `json:"size"`
+	Width       int                 `json:"width"`
+	Height      int                 `json:"height"`
+	Thumbnails  []MediaThumbnailDTO `json:"thumbnails"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
@@ -4,36 +4,96 @@ import (
 	"time"
 )
 
+// DateLayout is the ISO 8601 calendar date format used for release dates.
+const DateLayout = "2006-01-02"
+
+// CreateMovieDTO accepts the director either as a name, which is matched to or
+// creates a person, or as a director entry in Credits. ReleaseDate is the
+// original release and must fall in Year.
 type CreateMovieDTO struct {
-	Title       string  `json:"title" binding:"required"`
-	Director    string  `json:"director" binding:"required"`
-	Year        int     `json:"year" binding:"required,min=1800,max=2100"`
-	Plot        string  `json:"plot"`
-	Rating      float32 `json:"rating" binding:"min=0,max=10"`
-	Duration    int     `json:"duration" binding:"required,min=1"`
-	ReleaseDate string  `json:"release_date" binding:"required"`
+	Title            string       `json:"title" binding:"required,notblank"`
+	Director         string       `json:"director" binding:"required_without=Credits"`
+	Year             int          `json:"year" binding:"required,min=1800,max_years_ahead=5"`
+	Plot             string       `json:"plot"`
+	OriginalLanguage string       `json:"original_language" binding:"omitempty,bcp47_language_tag"`
+	Rating           float32      `json:"rating" binding:"min=0,max=10"`
+	Duration         int          `json:"duration" binding:"required,min=1"`
+	ReleaseDate      string       `json:"release_date" binding:"required,date"`
+	GenreIDs         []uint       `json:"genre_ids"`
+	Tags             []string     `json:"tags" binding:"omitempty,dive,required,max=100"`
+	Credits          []CreditDTO  `json:"credits" binding:"omitempty,dive"`
+	Releases         []ReleaseDTO `json:"releases" binding:"omitempty,dive"`
 }
 
 type UpdateMovieDTO struct {
-	Title       string  `json:"title"`
-	Director    string  `json:"director"`
-	Year        int     `json:"year" binding:"omitempty,min=1800,max=2100"`
-	Plot        string  `json:"plot"`
-	Rating      float32 `json:"rating" binding:"omitempty,min=0,max=10"`
-	Duration    int     `json:"duration" binding:"omitempty,min=1"`
-	ReleaseDate string  `json:"release_date"`
+	Title            string       `json:"title" binding:"omitempty,notblank"`
+	Director         string       `json:"director"`
+	Year             int          `json:"year" binding:"omitempty,min=1800,max_years_ahead=5"`
+	Plot             string       `json:"plot"`
+	OriginalLanguage string       `json:"original_language" binding:"omitempty,bcp47_language_tag"`
+	Rating           float32      `json:"rating" binding:"omitempty,min=0,max=10"`
+	Duration         int          `json:"duration" binding:"omitempty,min=1"`
+	ReleaseDate      string       `json:"release_date" binding:"omitempty,date"`
+	GenreIDs         []uint       `json:"genre_ids"`
+	Tags             []string     `json:"tags" binding:"omitempty,dive,required,max=100"`
+	Credits          []CreditDTO  `json:"credits" binding:"omitempty,dive"`
+	Releases         []ReleaseDTO `json:"releases" binding:"omitempty,dive"`
+}
+
+// ReleaseDTO is a regional release; Country is an ISO 3166-1 alpha-2 code.
+type ReleaseDTO struct {
+	Country     string `json:"country" binding:"required,iso3166_1_alpha2"`
+	Type        string `json:"type" binding:"required,oneof=premiere theatrical digital physical streaming tv"`
+	ReleaseDate string `json:"release_date" binding:"required,date"`
+}
+
+type ReleaseResponseDTO struct {
+	Country     string `json:"country"`
+	Type        string `json:"type"`
+	ReleaseDate string `json:"release_date"`
 }
 type MovieResponseDTO struct {
-	ID          uint      `json:"id"`
-	Title       string    `json:"title"`
-	Director    string    `json:"director"`
-	Year        int       `json:"year"`
-	Plot        string    `json:"plot"`
-	Rating      float32   `json:"rating"`
-	Duration    int       `json:"duration"`
-	ReleaseDate string    `json:"release_date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID               uint                 `json:"id"`
+	Title            string               `json:"title"`
+	OriginalTitle    string               `json:"original_title,omitempty"` // set when Title is a translation
+	Language         string               `json:"language,omitempty"`       // language of Title and Plot
+	OriginalLanguage string               `json:"original_language,omitempty"`
+	Director         string               `json:"director"`
+	Year             int                  `json:"year"`
+	Plot             string               `json:"plot"`
+	Rating           float32              `json:"rating"`
+	UserRating       float64              `json:"user_rating"`
+	UserRatingCount  int                  `json:"user_rating_count"`
+	Duration         int                  `json:"duration"`
+	ReleaseDate      string               `json:"release_date"`
+	Genres           []GenreResponseDTO   `json:"genres"`
+	Tags             []string             `json:"tags"`
+	Credits          []CreditResponseDTO  `json:"credits"`
+	Releases         []ReleaseResponseDTO `json:"releases"`
+	Poster           *MediaResponseDTO    `json:"poster,omitempty"`
+	Backdrop         *MediaResponseDTO    `json:"backdrop,omitempty"`
+	Stills           []MediaResponseDTO   `json:"stills"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+type MovieSummaryDTO struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+}
+
+// MovieFilterDTO holds the optional list filters. Genres and tags may be
+// repeated or comma-separated; match selects whether a movie needs any or all
+// of the requested values. The release window applies to the original release
+// date, or to the regional releases when a country is given.
+type MovieFilterDTO struct {
+	Genres       []string `form:"genre"`
+	Tags         []string `form:"tag"`
+	Match        string   `form:"match" binding:"omitempty,oneof=any all"`
+	ReleasedFrom string   `form:"released_from" binding:"omitempty,date"`
+	ReleasedTo   string   `form:"released_to" binding:"omitempty,date"`
+	Country      string   `form:"country" binding:"omitempty,iso3166_1_alpha2"`
 }
 
 type LoginDTO struct {
@@ -45,6 +105,10 @@ type TokenResponseDTO struct {
 	Token string `json:"token"`
 }
 
-type ErrorResponseDTO struct {
-	Error string `json:"error"`
+// RecommendationDTO is a suggested movie with its relevance score and the
+// strongest reasons behind it.
+type RecommendationDTO struct {
+	Movie   MovieSummaryDTO `json:"movie"`
+	Score   float64         `json:"score"`
+	Reasons []string        `json:"reasons"`
 }
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+)
+
+type CreatePersonDTO struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Bio  string `json:"bio"`
+}
+
+type UpdatePersonDTO struct {
+	Name string `json:"name" binding:"omitempty,max=255"`
+	Bio  string `json:"bio"`
+}
+
+type PersonResponseDTO struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreditDTO struct {
+	PersonID  uint   `json:"person_id" binding:"required"`
+	Role      string `json:"role" binding:"required,oneof=director actor writer producer"`
+	Character string `json:"character" binding:"max=255"`
+	Position  int    `json:"position" binding:"min=0"`
+}
+
+type CreditResponseDTO struct {
+	PersonID  uint   `json:"person_id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Character string `json:"character,omitempty"`
+	Position  int    `json:"position"`
+}
+
+type FilmographyEntryDTO struct {
+	MovieID   uint   `json:"movie_id"`
+	Title     string `json:"title"`
+	Year      int    `json:"year"`
+	Role      string `json:"role"`
+	Character string `json:"character,omitempty"`
+}
+
+type FilmographyResponseDTO struct {
+	Person  PersonResponseDTO     `json:"person"`
+	Credits []FilmographyEntryDTO `json:"credits"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"itv/pkg/validator"
+)
+
+// ProblemDTO is an RFC 7807 problem document, served as
+// application/problem+json. Code is a stable machine-readable identifier of
+// the error; Errors lists the invalid fields of a validation failure.
+type ProblemDTO struct {
+	Type     string                     `json:"type"`
+	Title    string                     `json:"title"`
+	Status   int                        `json:"status"`
+	Detail   string                     `json:"detail,omitempty"`
+	Instance string                     `json:"instance,omitempty"`
+	Code     string                     `json:"code"`
+	Errors   validator.ValidationErrors `json:"errors,omitempty"`
+}
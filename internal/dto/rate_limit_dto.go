@@ -0,0 +1,17 @@
+package dto
+
+// RateLimitUsageDTO lists the API rate limit buckets of one user, or of one
+// client IP for requests without a token, that are not full.
+type RateLimitUsageDTO struct {
+	UserID   uint                 `json:"user_id,omitempty"`
+	Username string               `json:"username,omitempty"`
+	IP       string               `json:"ip,omitempty"`
+	Buckets  []RateLimitBucketDTO `json:"buckets"`
+}
+
+type RateLimitBucketDTO struct {
+	Group     string `json:"group"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	Reset     int    `json:"reset"` // seconds until the bucket is full
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+)
+
+type UpsertReviewDTO struct {
+	Score int    `json:"score" binding:"required,min=1,max=10"`
+	Text  string `json:"text" binding:"max=5000"`
+}
+
+type FlagReviewDTO struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// ModerateReviewDTO resolves the pending flags on a review, hiding it or
+// keeping it visible.
+type ModerateReviewDTO struct {
+	Hidden *bool `json:"hidden" binding:"required"`
+}
+
+type ReviewResponseDTO struct {
+	ID        uint      `json:"id"`
+	MovieID   uint      `json:"movie_id"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username"`
+	Score     int       `json:"score"`
+	Text      string    `json:"text"`
+	Hidden    bool      `json:"hidden"`
+	FlagCount int       `json:"flag_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
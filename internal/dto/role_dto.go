@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+)
+
+type CreateRoleDTO struct {
+	Name        string   `json:"name" binding:"required,notblank,max=20"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"dive,oneof=movies:write movies:delete users:manage reviews:moderate"`
+}
+
+// UpdateRoleDTO replaces the description and the permissions of a role.
+type UpdateRoleDTO struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required,dive,oneof=movies:write movies:delete users:manage reviews:moderate"`
+}
+
+type RoleResponseDTO struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+)
+
+// TranslationDTO is the title and plot of a movie in the locale given in the
+// path. An empty plot falls back to the original one.
+type TranslationDTO struct {
+	Title string `json:"title" binding:"required,notblank,max=255"`
+	Plot  string `json:"plot"`
+}
+
+type TranslationResponseDTO struct {
+	Locale    string    `json:"locale"`
+	Title     string    `json:"title"`
+	Plot      string    `json:"plot"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
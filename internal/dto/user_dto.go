@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+)
+
+// CreateUserDTO describes a new account. Passwords are capped at 72 bytes,
+// beyond which bcrypt ignores the rest.
+type CreateUserDTO struct {
+	Username string `json:"username" binding:"required,notblank,max=255"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Role     string `json:"role" binding:"required,max=20"`
+}
+
+type UpdateUserRoleDTO struct {
+	Role string `json:"role" binding:"required,max=20"`
+}
+
+type ResetPasswordDTO struct {
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type UserResponseDTO struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+)
+
+type CreateWatchlistDTO struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type UpdateWatchlistDTO struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type AddWatchlistItemDTO struct {
+	MovieID uint `json:"movie_id" binding:"required"`
+}
+
+// ReorderWatchlistDTO lists every movie of the watchlist in the new order.
+type ReorderWatchlistDTO struct {
+	MovieIDs []uint `json:"movie_ids" binding:"required"`
+}
+
+type WatchlistItemResponseDTO struct {
+	Movie    MovieSummaryDTO `json:"movie"`
+	Position int             `json:"position"`
+	AddedAt  time.Time       `json:"added_at"`
+}
+
+type WatchlistResponseDTO struct {
+	ID        uint                       `json:"id"`
+	Name      string                     `json:"name"`
+	IsDefault bool                       `json:"is_default"`
+	ItemCount int                        `json:"item_count"`
+	Items     []WatchlistItemResponseDTO `json:"items,omitempty"`
+	CreatedAt time.Time                  `json:"created_at"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
+
+// MarkWatchedDTO records a viewing; WatchedAt defaults to now.
+type MarkWatchedDTO struct {
+	MovieID   uint       `json:"movie_id" binding:"required"`
+	WatchedAt *time.Time `json:"watched_at"`
+}
+
+type WatchHistoryResponseDTO struct {
+	ID        uint            `json:"id"`
+	Movie     MovieSummaryDTO `json:"movie"`
+	WatchedAt time.Time       `json:"watched_at"`
+}
@@ -0,0 +1,120 @@
+package fixtures
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// fakeSeed keeps the generated movies the same from run to run, so that
+// seeding again finds them by title and year instead of adding new ones.
+const fakeSeed = 0x6d6f76696573
+
+var (
+	titleAdjectives = []string{
+		"Silent", "Broken", "Crimson", "Hidden", "Last", "Golden", "Frozen", "Burning",
+		"Distant", "Forgotten", "Hollow", "Midnight", "Restless", "Savage", "Secret", "Shattered",
+		"Wandering", "Wicked", "Electric", "Endless", "Fading", "Iron", "Lonely", "Northern",
+		"Painted", "Quiet", "Scarlet", "Shadow", "Stolen", "Velvet", "Wild", "Winter",
+		"Ancient", "Bitter", "Cold", "Dark", "Falling", "Glass", "Lost", "Rising",
+	}
+	titleNouns = []string{
+		"Harbor", "Kingdom", "River", "Empire", "Horizon", "Garden", "Frontier", "Mirror",
+		"Station", "Highway", "Orchard", "Lighthouse", "Desert", "Carnival", "Signal", "Island",
+		"Circuit", "Valley", "Protocol", "Symphony", "Tide", "Witness", "Archive", "Border",
+		"Cathedral", "Code", "Crown", "Dynasty", "Echo", "Engine", "Fortress", "Voyage",
+		"Heist", "Inheritance", "Labyrinth", "Machine", "Meridian", "Outpost", "Paradox", "Summit",
+	}
+	// titleStride is coprime with the number of title combinations, so that
+	// stepping through them by it visits each one once in a mixed order.
+	titleStride = 977
+
+	firstNames = []string{
+		"Ada", "Bruno", "Carmen", "Dmitri", "Elena", "Farid", "Greta", "Hiro",
+		"Ingrid", "Jonas", "Keiko", "Luca", "Maya", "Nikolai", "Olivia", "Pablo",
+		"Quinn", "Rosa", "Samuel", "Tara", "Umar", "Vera", "Wen", "Ximena",
+		"Yusuf", "Zoe", "Amara", "Boris", "Chloe", "Diego", "Esther", "Felix",
+	}
+	lastNames = []string{
+		"Abbott", "Berg", "Castillo", "Dubois", "Eriksen", "Fischer", "Garcia", "Hayashi",
+		"Ivanova", "Jensen", "Kowalski", "Laurent", "Moreau", "Nakamura", "Okafor", "Petrov",
+		"Quintero", "Rossi", "Schmidt", "Tanaka", "Ueda", "Varga", "Walsh", "Xu",
+		"Yilmaz", "Zielinski", "Adeyemi", "Bianchi", "Costa", "Novak", "Silva", "Park",
+	}
+
+	plotSubjects = []string{
+		"A retired detective", "A young pilot", "Two estranged sisters", "A small-town mechanic",
+		"An ambitious journalist", "A disgraced scientist", "A band of smugglers", "A reluctant heir",
+		"A lonely lighthouse keeper", "A rookie police officer", "An exiled prince", "A pair of con artists",
+	}
+	plotGoals = []string{
+		"uncovers a conspiracy", "must cross a hostile frontier", "searches for a missing child",
+		"plans one last job", "tries to save a failing family business", "chases a legendary treasure",
+		"is drawn into a deadly game", "fights to clear a stolen name", "races to stop a catastrophe",
+		"falls for a dangerous stranger",
+	}
+	plotTwists = []string{
+		"that reaches the highest levels of power", "before the winter sets in", "while hiding a secret of their own",
+		"with the help of an unlikely ally", "as an old enemy returns", "in a city on the brink of war",
+		"before time runs out", "against the wishes of everyone they love",
+	}
+
+	fakeTags      = []string{"based on a true story", "cult", "dystopia", "ensemble", "heist", "independent", "revenge", "road trip", "twist ending", "slow burn"}
+	fakeLanguages = []string{"en", "en", "en", "en", "fr", "es", "de", "it", "ja", "ko"}
+	fakeCountries = []string{"GB", "DE", "FR", "ES", "IT", "JP", "BR", "AU"}
+)
+
+// FakeMovie generates the i-th fake movie with a few of genres. The same i
+// always yields the same movie, and different ones never share a title and
+// year.
+func FakeMovie(i int, genres []string) Movie {
+	rng := rand.New(rand.NewPCG(fakeSeed, uint64(i)))
+
+	combinations := len(titleAdjectives) * len(titleNouns)
+	k := i % combinations * titleStride % combinations
+	title := fmt.Sprintf("The %s %s", titleAdjectives[k%len(titleAdjectives)], titleNouns[k/len(titleAdjectives)])
+	if sequel := i / combinations; sequel > 0 {
+		title = fmt.Sprintf("%s %d", title, sequel+1)
+	}
+
+	year := 1950 + rng.IntN(75)
+	released := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, rng.IntN(365))
+
+	movie := Movie{
+		Title:            title,
+		Year:             year,
+		Director:         fakeName(rng),
+		Plot:             fmt.Sprintf("%s %s %s.", pick(rng, plotSubjects), pick(rng, plotGoals), pick(rng, plotTwists)),
+		OriginalLanguage: pick(rng, fakeLanguages),
+		Rating:           float32(20+rng.IntN(75)) / 10,
+		Duration:         80 + rng.IntN(100),
+		ReleaseDate:      released.Format(time.DateOnly),
+		Releases:         []Release{{Country: "US", Type: "theatrical", ReleaseDate: released.Format(time.DateOnly)}},
+	}
+
+	for _, j := range rng.Perm(len(genres))[:min(len(genres), 1+rng.IntN(3))] {
+		movie.Genres = append(movie.Genres, genres[j])
+	}
+	for _, j := range rng.Perm(len(fakeTags))[:rng.IntN(4)] {
+		movie.Tags = append(movie.Tags, fakeTags[j])
+	}
+	for n := 2 + rng.IntN(4); n > 0; n-- {
+		movie.Cast = append(movie.Cast, Credit{Name: fakeName(rng), Character: pick(rng, firstNames)})
+	}
+	if rng.IntN(2) == 0 {
+		movie.Releases = append(movie.Releases, Release{
+			Country:     pick(rng, fakeCountries),
+			Type:        pick(rng, []string{"theatrical", "digital", "streaming"}),
+			ReleaseDate: released.AddDate(0, 0, 14+rng.IntN(180)).Format(time.DateOnly),
+		})
+	}
+	return movie
+}
+
+func fakeName(rng *rand.Rand) string {
+	return pick(rng, firstNames) + " " + pick(rng, lastNames)
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.IntN(len(values))]
+}
@@ -0,0 +1,123 @@
+// Package fixtures holds the sample data loaded by the seed command: a set of
+// genres, demo users and well-known movies embedded in the binary, and a
+// generator of fake movies for load testing. Its movie format doubles as the
+// file format of movies import and export.
+package fixtures
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed data/*.yaml
+var data embed.FS
+
+type Genre struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+type User struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Role     string `yaml:"role"`
+}
+
+// Movie is identified by its title and year. Genres are referenced by name
+// and people, who are created as needed, by their full name. It is also the
+// format of movies import and export, hence the JSON names.
+type Movie struct {
+	Title            string    `yaml:"title" json:"title"`
+	Year             int       `yaml:"year" json:"year"`
+	Director         string    `yaml:"director" json:"director,omitempty"`
+	Plot             string    `yaml:"plot" json:"plot,omitempty"`
+	OriginalLanguage string    `yaml:"original_language" json:"original_language,omitempty"`
+	Rating           float32   `yaml:"rating" json:"rating,omitempty"`
+	Duration         int       `yaml:"duration" json:"duration"`
+	ReleaseDate      string    `yaml:"release_date" json:"release_date"`
+	Genres           []string  `yaml:"genres" json:"genres"`
+	Tags             []string  `yaml:"tags" json:"tags,omitempty"`
+	Cast             []Credit  `yaml:"cast" json:"cast,omitempty"`
+	Releases         []Release `yaml:"releases" json:"releases,omitempty"`
+}
+
+// Credit names a person in a role other than the one of Movie.Director. Role
+// defaults to actor.
+type Credit struct {
+	Name      string `yaml:"name" json:"name"`
+	Role      string `yaml:"role" json:"role,omitempty"`
+	Character string `yaml:"character" json:"character,omitempty"`
+}
+
+type Release struct {
+	Country     string `yaml:"country" json:"country"`
+	Type        string `yaml:"type" json:"type"`
+	ReleaseDate string `yaml:"release_date" json:"release_date"`
+}
+
+// Set is the complete embedded sample data.
+type Set struct {
+	Genres []Genre
+	Users  []User
+	Movies []Movie
+}
+
+// Load decodes the embedded fixtures.
+func Load() (*Set, error) {
+	set := &Set{}
+	for _, file := range []struct {
+		name string
+		into any
+	}{
+		{"genres.yaml", &set.Genres},
+		{"users.yaml", &set.Users},
+		{"movies.yaml", &set.Movies},
+	} {
+		content, err := data.ReadFile("data/" + file.name)
+		if err != nil {
+			return nil, err
+		}
+		if err := decode(bytes.NewReader(content), file.into); err != nil {
+			return nil, fmt.Errorf("fixtures %s: %w", file.name, err)
+		}
+	}
+	return set, nil
+}
+
+// ReadMovies decodes a list of movies in YAML or in JSON, which YAML
+// includes.
+func ReadMovies(r io.Reader) ([]Movie, error) {
+	var movies []Movie
+	if err := decode(r, &movies); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+// WriteMovies encodes movies as an indented JSON array.
+func WriteMovies(w io.Writer, movies []Movie) error {
+	if movies == nil {
+		movies = []Movie{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(movies)
+}
+
+// decode rejects unknown keys so that a misspelt field does not silently drop
+// data.
+func decode(r io.Reader, into any) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	err := decoder.Decode(into)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
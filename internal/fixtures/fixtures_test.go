@@ -0,0 +1,81 @@
+package fixtures
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	set, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Genres) == 0 || len(set.Users) == 0 || len(set.Movies) == 0 {
+		t.Fatalf("Load returned %d genres, %d users and %d movies", len(set.Genres), len(set.Users), len(set.Movies))
+	}
+
+	genres := make(map[string]bool)
+	for _, genre := range set.Genres {
+		genres[genre.Name] = true
+	}
+	for _, movie := range set.Movies {
+		for _, genre := range movie.Genres {
+			if !genres[genre] {
+				t.Errorf("%s uses the unknown genre %q", movie.Title, genre)
+			}
+		}
+	}
+}
+
+// TestFakeMovie checks what seeding again relies on: the same index yields
+// the same movie and no two movies share a title and year.
+func TestFakeMovie(t *testing.T) {
+	genres := []string{"Action", "Drama", "Comedy"}
+	seen := make(map[string]int)
+	for i := 0; i < 5000; i++ {
+		movie := FakeMovie(i, genres)
+		if again := FakeMovie(i, genres); !reflect.DeepEqual(movie, again) {
+			t.Fatalf("FakeMovie(%d) is not deterministic", i)
+		}
+		key := movie.Title
+		if j, ok := seen[key]; ok {
+			t.Fatalf("FakeMovie(%d) and FakeMovie(%d) are both titled %q", i, j, key)
+		}
+		seen[key] = i
+		if len(movie.Genres) == 0 || movie.Year < 1950 || movie.Duration <= 0 {
+			t.Fatalf("FakeMovie(%d) = %+v", i, movie)
+		}
+	}
+}
+
+func TestReadMoviesReadsWrittenMovies(t *testing.T) {
+	movies := []Movie{
+		FakeMovie(1, []string{"Drama"}),
+		{
+			Title:    "Heat",
+			Year:     1995,
+			Director: "Michael Mann",
+			Duration: 170,
+			Genres:   []string{"Crime"},
+			Cast:     []Credit{{Name: "Al Pacino", Character: "Vincent Hanna"}, {Name: "Art Linson", Role: "producer"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMovies(&buf, movies); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadMovies(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, movies) {
+		t.Errorf("ReadMovies = %+v, want %+v", got, movies)
+	}
+
+	if _, err := ReadMovies(strings.NewReader(`[{"title": "Heat", "yaer": 1995}]`)); err == nil {
+		t.Error("ReadMovies accepted an unknown key")
+	}
+}
@@ -1,67 +1,147 @@
 package middleware
 
 import (
-	"itv/internal/dto"
+	"errors"
+	"itv/internal/apperror"
+	"itv/internal/service"
 	"itv/pkg/auth"
-	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// APIKeyHeader is the request header that carries an API key.
+const APIKeyHeader = "X-API-Key"
+
 type AuthMiddleware struct {
-	jwtService *auth.JWTService
+	jwtService    *auth.JWTService
+	roleService   *service.RoleService
+	apiKeyService *service.APIKeyService
 }
 
-func NewAuthMiddleware(jwtService *auth.JWTService) *AuthMiddleware {
+func NewAuthMiddleware(jwtService *auth.JWTService, roleService *service.RoleService, apiKeyService *service.APIKeyService) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtService: jwtService,
+		jwtService:    jwtService,
+		roleService:   roleService,
+		apiKeyService: apiKeyService,
 	}
 }
 
+// JWTAuth authenticates requests by a Bearer token or, in its place, an API
+// key in the X-API-Key header. Tokens set the user's ID, name and role in the
+// context; API keys, which act for no user, set apiKeyID and
+// apiKeyPermissions instead. The role is the user's current one rather than
+// the one in the token, so that assigning another role applies at once.
 func (m *AuthMiddleware) JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+			key, err := m.apiKeyService.Authenticate(apiKey)
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+
+			c.Set("apiKeyID", key.ID)
+			c.Set("apiKeyPermissions", key.PermissionNames())
+
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponseDTO{Error: "authorization header is required"})
+			c.Error(apperror.Unauthorized("missing_token", "authorization header or API key is required"))
 			c.Abort()
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponseDTO{Error: "invalid authorization header format"})
+			c.Error(apperror.Unauthorized("invalid_authorization_header", "invalid authorization header format"))
 			c.Abort()
 			return
 		}
 
 		claims, err := m.jwtService.ParseToken(parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponseDTO{Error: "invalid or expired token"})
+			c.Error(apperror.Unauthorized("invalid_token", "invalid or expired token"))
+			c.Abort()
+			return
+		}
+
+		role, err := m.roleService.UserRole(claims.UserID)
+		if err != nil {
+			if apperror.IsNotFound(err) {
+				err = apperror.Unauthorized("invalid_token", "invalid or expired token")
+			}
+			c.Error(err)
 			c.Abort()
 			return
 		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		c.Set("role", role)
+
+		c.Next()
+	}
+}
+
+// RequirePermission lets requests through whose role, or API key, grants
+// permission. It has to run after JWTAuth. Permissions are looked up on each
+// request rather than carried in the token, so that changes to a role apply
+// at once.
+func (m *AuthMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var allowed bool
+		var err error
+		if keyPermissions, isKey := c.Get("apiKeyPermissions"); isKey {
+			allowed = slices.Contains(keyPermissions.([]string), permission)
+		} else if _, isUser := c.Get("userID"); isUser {
+			allowed, err = m.roleService.HasPermission(c.GetString("role"), permission)
+		} else {
+			c.Error(apperror.Unauthorized("unauthorized", "authentication is required"))
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.Error(apperror.Forbidden("forbidden", "you do not have access to this resource"))
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
 
+// RoleAuth lets requests through whose role is one of roles. Routes that
+// a permission covers use RequirePermission instead. API keys have no role
+// and are always refused.
 func (m *AuthMiddleware) RoleAuth(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if _, isKey := c.Get("apiKeyID"); isKey {
+			c.Error(apperror.Forbidden("forbidden", "you do not have access to this resource"))
+			c.Abort()
+			return
+		}
+
 		userRole, exists := c.Get("role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponseDTO{Error: "unauthorized"})
+			c.Error(apperror.Unauthorized("unauthorized", "authentication is required"))
 			c.Abort()
 			return
 		}
 
 		roleStr, ok := userRole.(string)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, dto.ErrorResponseDTO{Error: "server error"})
+			c.Error(errors.New("role in context is not a string"))
 			c.Abort()
 			return
 		}
@@ -75,7 +155,7 @@ func (m *AuthMiddleware) RoleAuth(roles ...string) gin.HandlerFunc {
 		}
 
 		if !allowed {
-			c.JSON(http.StatusForbidden, dto.ErrorResponseDTO{Error: "forbidden"})
+			c.Error(apperror.Forbidden("forbidden", "you do not have access to this resource"))
 			c.Abort()
 			return
 		}
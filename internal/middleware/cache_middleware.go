@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl sets the Cache-Control header of successful GET and HEAD
+// responses to value. An empty value leaves the header unset. Problem
+// documents are never cached, see ErrorHandler.
+func CacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value != "" && (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) {
+			c.Header("Cache-Control", value)
+		}
+		c.Next()
+	}
+}
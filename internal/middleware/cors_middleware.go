@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"fmt"
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSMiddleware answers preflight requests and adds CORS headers for the
+// origins listed in CORS_ALLOWED_ORIGINS. An origin is either exact, such as
+// "https://app.example.com", matches every subdomain, such as
+// "https://*.example.com", or is "*" for any origin. The settings follow
+// configuration reloads.
+type CORSMiddleware struct {
+	policy atomic.Pointer[corsPolicy]
+}
+
+// corsPolicy holds the parsed CORS settings.
+type corsPolicy struct {
+	anyOrigin     bool
+	origins       map[string]bool
+	wildcards     []originPattern
+	methods       map[string]bool
+	allowMethods  string
+	headers       map[string]bool
+	anyHeader     bool
+	allowHeaders  string
+	exposeHeaders string
+	credentials   bool
+	maxAge        string
+}
+
+// originPattern matches the subdomains of suffix with the given scheme and
+// port.
+type originPattern struct {
+	scheme string
+	suffix string
+	port   string
+}
+
+func NewCORSMiddleware(config *config.Config, reloader *config.Reloader) (*CORSMiddleware, error) {
+	policy, err := newCORSPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	m := &CORSMiddleware{}
+	m.policy.Store(policy)
+	reloader.Subscribe(m.reload)
+	return m, nil
+}
+
+func (m *CORSMiddleware) reload(config *config.Config) error {
+	policy, err := newCORSPolicy(config)
+	if err != nil {
+		return err
+	}
+	m.policy.Store(policy)
+	return nil
+}
+
+func newCORSPolicy(config *config.Config) (*corsPolicy, error) {
+	p := &corsPolicy{
+		origins:       make(map[string]bool),
+		methods:       make(map[string]bool),
+		headers:       make(map[string]bool),
+		exposeHeaders: strings.Join(config.CORSExposedHeaders, ", "),
+		credentials:   config.CORSAllowCredentials,
+		maxAge:        strconv.Itoa(int(config.CORSMaxAge.Seconds())),
+	}
+
+	for _, origin := range config.CORSAllowedOrigins {
+		if origin == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		scheme, host, port, err := splitOrigin(origin)
+		if err != nil {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: %w", err)
+		}
+		if suffix, ok := strings.CutPrefix(host, "*."); ok {
+			if suffix == "" || strings.Contains(suffix, "*") {
+				return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: invalid wildcard origin %q", origin)
+			}
+			p.wildcards = append(p.wildcards, originPattern{scheme: scheme, suffix: "." + suffix, port: port})
+			continue
+		}
+		if strings.Contains(host, "*") {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: invalid wildcard origin %q", origin)
+		}
+		p.origins[joinOrigin(scheme, host, port)] = true
+	}
+	if p.anyOrigin && p.credentials {
+		return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: \"*\" cannot be combined with CORS_ALLOW_CREDENTIALS")
+	}
+
+	var methods []string
+	for _, method := range config.CORSAllowedMethods {
+		method = strings.ToUpper(method)
+		p.methods[method] = true
+		methods = append(methods, method)
+	}
+	p.allowMethods = strings.Join(methods, ", ")
+
+	for _, header := range config.CORSAllowedHeaders {
+		if header == "*" {
+			p.anyHeader = true
+			continue
+		}
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	p.allowHeaders = strings.Join(config.CORSAllowedHeaders, ", ")
+
+	return p, nil
+}
+
+// Handler adds the CORS headers to requests from allowed origins and ends
+// preflight requests. Preflights from other origins, or for methods and
+// headers that are not allowed, are rejected with 403.
+func (m *CORSMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		p := m.policy.Load()
+		if !p.anyOrigin || p.credentials {
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !p.allowed(origin) {
+			if preflight {
+				c.Error(apperror.Forbidden("cors_origin_not_allowed", "origin "+origin+" is not allowed"))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !preflight {
+			p.allowOrigin(c, origin)
+			if p.exposeHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", p.exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		if !p.methods[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))] {
+			c.Error(apperror.Forbidden("cors_method_not_allowed", "method "+c.GetHeader("Access-Control-Request-Method")+" is not allowed"))
+			c.Abort()
+			return
+		}
+		requested := c.GetHeader("Access-Control-Request-Headers")
+		if !p.anyHeader {
+			for _, header := range strings.Split(requested, ",") {
+				header = strings.TrimSpace(header)
+				if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+					c.Error(apperror.Forbidden("cors_header_not_allowed", "header "+header+" is not allowed"))
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		p.allowOrigin(c, origin)
+		c.Header("Access-Control-Allow-Methods", p.allowMethods)
+		if p.anyHeader && requested != "" {
+			c.Header("Access-Control-Allow-Headers", requested)
+		} else if p.allowHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", p.allowHeaders)
+		}
+		c.Header("Access-Control-Max-Age", p.maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func (p *corsPolicy) allowOrigin(c *gin.Context, origin string) {
+	if p.anyOrigin && !p.credentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if p.credentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *corsPolicy) allowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	scheme, host, port, err := splitOrigin(origin)
+	if err != nil {
+		return false
+	}
+	if p.origins[joinOrigin(scheme, host, port)] {
+		return true
+	}
+	for _, pattern := range p.wildcards {
+		if scheme == pattern.scheme && port == pattern.port &&
+			strings.HasSuffix(host, pattern.suffix) && !strings.Contains(host, "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOrigin parses an origin of the form scheme://host[:port] into its
+// lowercased parts, leaving out the port when it is the scheme's default.
+func splitOrigin(origin string) (scheme, host, port string, err error) {
+	u, err := url.Parse(strings.ToLower(strings.TrimSpace(origin)))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", "", "", fmt.Errorf("invalid origin %q", origin)
+	}
+
+	host, port = u.Hostname(), u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	return u.Scheme, host, port, nil
+}
+
+func joinOrigin(scheme, host, port string) string {
+	if port == "" {
+		return scheme + "://" + host
+	}
+	return scheme + "://" + host + ":" + port
+}
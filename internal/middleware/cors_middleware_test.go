@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"itv/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSplitOrigin(t *testing.T) {
+	tests := []struct {
+		origin             string
+		scheme, host, port string
+		wantErr            bool
+	}{
+		{"https://app.example.com", "https", "app.example.com", "", false},
+		{" HTTPS://App.Example.com/ ", "https", "app.example.com", "", false},
+		{"https://app.example.com:443", "https", "app.example.com", "", false},
+		{"http://localhost:80", "http", "localhost", "", false},
+		{"http://localhost:3000", "http", "localhost", "3000", false},
+		{"https://localhost:80", "https", "localhost", "80", false},
+		{"http://[::1]:8080", "http", "::1", "8080", false},
+		{"https://*.example.com", "https", "*.example.com", "", false},
+		{"app.example.com", "", "", "", true},
+		{"ftp://app.example.com", "", "", "", true},
+		{"https://", "", "", "", true},
+		{"https://user@app.example.com", "", "", "", true},
+		{"https://app.example.com/path", "", "", "", true},
+		{"https://app.example.com?q=1", "", "", "", true},
+		{"https://app.example.com#top", "", "", "", true},
+		{"null", "", "", "", true},
+	}
+	for _, tt := range tests {
+		scheme, host, port, err := splitOrigin(tt.origin)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitOrigin(%q) error = %v, want error %t", tt.origin, err, tt.wantErr)
+			continue
+		}
+		if scheme != tt.scheme || host != tt.host || port != tt.port {
+			t.Errorf("splitOrigin(%q) = %q, %q, %q, want %q, %q, %q", tt.origin, scheme, host, port, tt.scheme, tt.host, tt.port)
+		}
+	}
+}
+
+func TestNewCORSPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		credentials bool
+		wantErr     bool
+	}{
+		{"exact and wildcard", []string{"https://app.example.com", "https://*.example.com"}, true, false},
+		{"any origin", []string{"*"}, false, false},
+		{"any origin with credentials", []string{"*"}, true, true},
+		{"invalid origin", []string{"app.example.com"}, false, true},
+		{"wildcard without a domain", []string{"https://*."}, false, true},
+		{"wildcard in the middle", []string{"https://app.*.com"}, false, true},
+		{"two wildcards", []string{"https://*.*.example.com"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newCORSPolicy(&config.Config{CORSAllowedOrigins: tt.origins, CORSAllowCredentials: tt.credentials})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newCORSPolicy error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCORSPolicyAllowed(t *testing.T) {
+	p, err := newCORSPolicy(&config.Config{CORSAllowedOrigins: []string{
+		"https://app.example.com",
+		"http://localhost:3000",
+		"https://*.preview.example.com",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com:443", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://evil.com", false},
+		{"https://app.example.com.evil.com", false},
+		{"http://localhost:3000", true},
+		{"http://localhost", false},
+		{"https://pr-1.preview.example.com", true},
+		{"https://a.b.preview.example.com", true},
+		{"https://preview.example.com", false},
+		{"https://pr-1preview.example.com", false},
+		{"http://pr-1.preview.example.com", false},
+		{"https://pr-1.preview.example.com:8443", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		if got := p.allowed(tt.origin); got != tt.want {
+			t.Errorf("allowed(%q) = %t, want %t", tt.origin, got, tt.want)
+		}
+	}
+
+	anyOrigin, err := newCORSPolicy(&config.Config{CORSAllowedOrigins: []string{"*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !anyOrigin.allowed("https://evil.com") {
+		t.Error("\"*\" does not allow every origin")
+	}
+}
+
+func TestCORSMiddlewareHandler(t *testing.T) {
+	cfg := &config.Config{
+		CORSAllowedOrigins:   []string{"https://app.example.com"},
+		CORSAllowedMethods:   []string{"get", "POST"},
+		CORSAllowedHeaders:   []string{"Authorization", "Content-Type"},
+		CORSExposedHeaders:   []string{"ETag"},
+		CORSAllowCredentials: true,
+		CORSMaxAge:           time.Hour,
+	}
+	m, err := NewCORSMiddleware(cfg, config.NewReloader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		method     string
+		header     http.Header
+		wantStatus int
+		wantOrigin string
+		wantCode   string
+	}{
+		{"same origin", http.MethodGet, http.Header{}, http.StatusOK, "", ""},
+		{"allowed origin", http.MethodGet, http.Header{"Origin": {"https://app.example.com"}},
+			http.StatusOK, "https://app.example.com", ""},
+		{"other origin", http.MethodGet, http.Header{"Origin": {"https://evil.com"}}, http.StatusOK, "", ""},
+		{"preflight", http.MethodOptions, http.Header{
+			"Origin":                         {"https://app.example.com"},
+			"Access-Control-Request-Method":  {"POST"},
+			"Access-Control-Request-Headers": {"authorization, content-type"},
+		}, http.StatusNoContent, "https://app.example.com", ""},
+		{"preflight from another origin", http.MethodOptions, http.Header{
+			"Origin":                        {"https://evil.com"},
+			"Access-Control-Request-Method": {"GET"},
+		}, http.StatusForbidden, "", "cors_origin_not_allowed"},
+		{"preflight for another method", http.MethodOptions, http.Header{
+			"Origin":                        {"https://app.example.com"},
+			"Access-Control-Request-Method": {"DELETE"},
+		}, http.StatusForbidden, "", "cors_method_not_allowed"},
+		{"preflight for another header", http.MethodOptions, http.Header{
+			"Origin":                         {"https://app.example.com"},
+			"Access-Control-Request-Method":  {"GET"},
+			"Access-Control-Request-Headers": {"X-Debug"},
+		}, http.StatusForbidden, "", "cors_header_not_allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler(), m.Handler())
+			router.Any("/movies", func(c *gin.Context) {
+				c.String(http.StatusOK, "movies")
+			})
+			req := httptest.NewRequest(tt.method, "/movies", nil)
+			req.Header = tt.header
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if tt.wantCode != "" && !strings.Contains(rec.Body.String(), `"code":"`+tt.wantCode+`"`) {
+				t.Errorf("body = %s, want code %s", rec.Body, tt.wantCode)
+			}
+			if tt.wantStatus == http.StatusNoContent {
+				want := map[string]string{
+					"Access-Control-Allow-Methods":     "GET, POST",
+					"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+					"Access-Control-Allow-Credentials": "true",
+					"Access-Control-Max-Age":           "3600",
+				}
+				for name, value := range want {
+					if got := rec.Header().Get(name); got != value {
+						t.Errorf("%s = %q, want %q", name, got, value)
+					}
+				}
+			}
+			wantExpose := ""
+			if tt.wantOrigin != "" && tt.method != http.MethodOptions {
+				wantExpose = "ETag"
+			}
+			if got := rec.Header().Get("Access-Control-Expose-Headers"); got != wantExpose {
+				t.Errorf("Access-Control-Expose-Headers = %q, want %q", got, wantExpose)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/pkg/validator"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// ErrorHandler renders the last error that a handler attached with ctx.Error
+// as an RFC 7807 problem document. Errors other than *apperror.Error are
+// logged and reported as internal errors without their message. Field errors
+// are translated into the language preferred by Accept-Language. Problems are
+// marked as not cacheable, overriding CacheControl.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		renderError(c, c.Errors.Last().Err)
+	}
+}
+
+// renderError writes err as a problem document.
+func renderError(c *gin.Context, err error) {
+	problem := newProblem(err)
+	problem.Instance = c.Request.URL.Path
+	if problem.Status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) && appErr.RetryAfter > 0 {
+		c.Header("Retry-After", retryAfterSeconds(appErr.RetryAfter))
+	}
+	if len(problem.Errors) > 0 {
+		lang := validator.MatchLanguage(c.GetHeader("Accept-Language"))
+		problem.Errors = problem.Errors.Localize(lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(problem.Status, problem)
+}
+
+// retryAfterSeconds formats d for the Retry-After header, rounding up to
+// whole seconds.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
+
+// NoRoute reports unknown routes as problem documents.
+func NoRoute(c *gin.Context) {
+	c.Error(apperror.NotFound("route_not_found", "no route matches "+c.Request.Method+" "+c.Request.URL.Path))
+}
+
+// NoMethod reports unsupported methods on known routes as problem documents.
+func NoMethod(c *gin.Context) {
+	c.Header("Content-Type", problemContentType)
+	c.JSON(http.StatusMethodNotAllowed, dto.ProblemDTO{
+		Type:     "about:blank",
+		Title:    http.StatusText(http.StatusMethodNotAllowed),
+		Status:   http.StatusMethodNotAllowed,
+		Detail:   "method " + c.Request.Method + " is not allowed on " + c.Request.URL.Path,
+		Instance: c.Request.URL.Path,
+		Code:     "method_not_allowed",
+	})
+}
+
+var kindStatuses = map[apperror.Kind]int{
+	apperror.KindValidation:       http.StatusBadRequest,
+	apperror.KindUnauthorized:     http.StatusUnauthorized,
+	apperror.KindForbidden:        http.StatusForbidden,
+	apperror.KindNotFound:         http.StatusNotFound,
+	apperror.KindConflict:         http.StatusConflict,
+	apperror.KindTooLarge:         http.StatusRequestEntityTooLarge,
+	apperror.KindUnsupportedMedia: http.StatusUnsupportedMediaType,
+	apperror.KindTooManyRequests:  http.StatusTooManyRequests,
+}
+
+func newProblem(err error) dto.ProblemDTO {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		appErr = &apperror.Error{Kind: apperror.KindInternal}
+	}
+
+	status, ok := kindStatuses[appErr.Kind]
+	if !ok {
+		return dto.ProblemDTO{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+			Detail: "an unexpected error occurred",
+			Code:   "internal_error",
+		}
+	}
+
+	return dto.ProblemDTO{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: appErr.Message,
+		Code:   appErr.Code,
+		Errors: appErr.Fields,
+	}
+}
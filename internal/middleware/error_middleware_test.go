@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"itv/internal/apperror"
+	"itv/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serveError answers a request to a handler failing with err through
+// ErrorHandler.
+func serveError(err error) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/movies/1", func(c *gin.Context) {
+		c.Error(err)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/movies/1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{"not found", apperror.NotFound("movie_not_found", "movie not found"), http.StatusNotFound, "movie_not_found", "movie not found"},
+		{"conflict", apperror.Conflict("movie_exists", "movie exists"), http.StatusConflict, "movie_exists", "movie exists"},
+		{"validation", apperror.Validation("invalid_id", "invalid ID format"), http.StatusBadRequest, "invalid_id", "invalid ID format"},
+		{"unauthorized", apperror.Unauthorized("invalid_token", "invalid token"), http.StatusUnauthorized, "invalid_token", "invalid token"},
+		{"forbidden", apperror.Forbidden("forbidden", "no access"), http.StatusForbidden, "forbidden", "no access"},
+		{"too large", apperror.TooLarge("request_too_large", "too large"), http.StatusRequestEntityTooLarge, "request_too_large", "too large"},
+		{"unsupported media", apperror.UnsupportedMedia("unsupported_media_type", "not an image"), http.StatusUnsupportedMediaType, "unsupported_media_type", "not an image"},
+		{"wrapped", errors.Join(errors.New("loading"), apperror.NotFound("movie_not_found", "movie not found")), http.StatusNotFound, "movie_not_found", "movie not found"},
+		{"internal", errors.New("dial tcp: connection refused"), http.StatusInternalServerError, "internal_error", "an unexpected error occurred"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveError(tt.err)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != problemContentType {
+				t.Errorf("Content-Type = %q, want %q", got, problemContentType)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+
+			var problem dto.ProblemDTO
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			want := dto.ProblemDTO{
+				Type:     "about:blank",
+				Title:    http.StatusText(tt.wantStatus),
+				Status:   tt.wantStatus,
+				Detail:   tt.wantDetail,
+				Instance: "/movies/1",
+				Code:     tt.wantCode,
+			}
+			if problem.Type != want.Type || problem.Title != want.Title || problem.Status != want.Status ||
+				problem.Detail != want.Detail || problem.Instance != want.Instance || problem.Code != want.Code {
+				t.Errorf("problem = %+v, want %+v", problem, want)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerLocalizesFields(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		wantLanguage   string
+		wantMessage    string
+	}{
+		{"", "en", "Must be at least 1888"},
+		{"ru-RU,ru;q=0.9", "ru", "Должно быть не меньше 1888"},
+		{"uz", "uz", "Kamida 1888 bo'lishi kerak"},
+		{"de", "en", "Must be at least 1888"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantLanguage+" for "+tt.acceptLanguage, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/movies", func(c *gin.Context) {
+				c.Error(apperror.InvalidField("year", "min", "1888"))
+			})
+			req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Language"); got != tt.wantLanguage {
+				t.Errorf("Content-Language = %q, want %q", got, tt.wantLanguage)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Language" {
+				t.Errorf("Vary = %q, want Accept-Language", got)
+			}
+			var problem dto.ProblemDTO
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if len(problem.Errors) != 1 || problem.Errors[0].Field != "year" || problem.Errors[0].Message != tt.wantMessage {
+				t.Errorf("errors = %+v, want year: %q", problem.Errors, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{time.Minute, "60"},
+		{1500 * time.Millisecond, "2"},
+		{time.Millisecond, "1"},
+	}
+	for _, tt := range tests {
+		rec := serveError(apperror.TooManyRequests("login_throttled", "too many failed logins", tt.retryAfter))
+		if rec.Code != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+		}
+		if got := rec.Header().Get("Retry-After"); got != tt.want {
+			t.Errorf("Retry-After for %s = %q, want %q", tt.retryAfter, got, tt.want)
+		}
+	}
+	if got := serveError(apperror.NotFound("movie_not_found", "movie not found")).Header().Get("Retry-After"); got != "" {
+		t.Errorf("Retry-After = %q without a delay", got)
+	}
+}
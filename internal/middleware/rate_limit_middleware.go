@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"itv/internal/model"
+	"itv/pkg/ratelimit"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loginPeekSize bounds how much of a login request body is read to find the
+// username.
+const loginPeekSize = 4 << 10
+
+// anonymousRole is the role whose limits apply to requests without a token.
+const anonymousRole = "anonymous"
+
+// apiKeyRole is the role whose limits apply to requests with an API key.
+// Policies that do not name it limit API keys like users.
+const apiKeyRole = "api_key"
+
+// RateLimitMiddleware applies the configured rate limits, following
+// configuration reloads.
+type RateLimitMiddleware struct {
+	store  ratelimit.Store
+	limits atomic.Pointer[rateLimits]
+}
+
+// rateLimits holds the parsed login limits and route group policies.
+type rateLimits struct {
+	loginIP   ratelimit.Limit
+	loginUser ratelimit.Limit
+	policies  map[string]ratelimit.Policy
+}
+
+func NewRateLimitMiddleware(store ratelimit.Store, config *config.Config, reloader *config.Reloader) (*RateLimitMiddleware, error) {
+	limits, err := newRateLimits(config)
+	if err != nil {
+		return nil, err
+	}
+	m := &RateLimitMiddleware{store: store}
+	m.limits.Store(limits)
+	reloader.Subscribe(m.reload)
+	return m, nil
+}
+
+func (m *RateLimitMiddleware) reload(config *config.Config) error {
+	limits, err := newRateLimits(config)
+	if err != nil {
+		return err
+	}
+	m.limits.Store(limits)
+	return nil
+}
+
+func newRateLimits(config *config.Config) (*rateLimits, error) {
+	loginIP, err := ratelimit.ParseLimit(config.LoginIPLimit)
+	if err != nil {
+		return nil, fmt.Errorf("LOGIN_IP_LIMIT: %w", err)
+	}
+	loginUser, err := ratelimit.ParseLimit(config.LoginUserLimit)
+	if err != nil {
+		return nil, fmt.Errorf("LOGIN_USER_LIMIT: %w", err)
+	}
+
+	policies := make(map[string]ratelimit.Policy)
+	for group, value := range map[string]string{
+		"auth":    config.RateLimitAuth,
+		"movies":  config.RateLimitMovies,
+		"search":  config.RateLimitSearch,
+		"genres":  config.RateLimitGenres,
+		"people":  config.RateLimitPeople,
+		"reviews": config.RateLimitReviews,
+		"me":      config.RateLimitMe,
+		"admin":   config.RateLimitAdmin,
+	} {
+		policy, err := ratelimit.ParsePolicy(value)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_%s: %w", strings.ToUpper(group), err)
+		}
+		policies[group] = policy
+	}
+
+	return &rateLimits{
+		loginIP:   loginIP,
+		loginUser: loginUser,
+		policies:  policies,
+	}, nil
+}
+
+// Limit applies the rate limit policy of the route group with one bucket per
+// user or API key, or per client IP for anonymous requests, under the key
+// "api:<group>:user:<id>", "api:<group>:key:<id>" or "api:<group>:ip:<address>". It has to run after
+// AuthMiddleware.JWTAuth to tell users and roles apart. Responses describe
+// the emptiest bucket that the request went through in RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers.
+func (m *RateLimitMiddleware) Limit(group string) gin.HandlerFunc {
+	if _, ok := m.limits.Load().policies[group]; !ok {
+		panic("no rate limit policy for route group " + group)
+	}
+
+	return func(c *gin.Context) {
+		policy := m.limits.Load().policies[group]
+		role, subject := anonymousRole, "ip:"+c.ClientIP()
+		if userID, ok := c.Get("userID"); ok {
+			role, subject = c.GetString("role"), fmt.Sprintf("user:%d", userID)
+		} else if keyID, ok := c.Get("apiKeyID"); ok {
+			role, subject = apiKeyRole, fmt.Sprintf("key:%d", keyID)
+		}
+		limit := policyFor(policy, role)
+		if limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		result := m.store.Take("api:"+group+":"+subject, limit)
+		if previous, ok := c.Get("rateLimit"); !ok || result.Remaining < previous.(ratelimit.Result).Remaining {
+			c.Set("rateLimit", result)
+			c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+			c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))))
+			c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, int(limit.Period.Seconds()), limit.Burst))
+		}
+		if !result.Allowed {
+			c.Error(apperror.TooManyRequests("rate_limited", "too many requests, try again later", result.RetryAfter))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// policyFor returns the limit of role in policy. Roles created by admins
+// that the policy neither names nor covers with a bare limit are limited like
+// users rather than not at all.
+func policyFor(policy ratelimit.Policy, role string) ratelimit.Limit {
+	_, listed := policy[role]
+	_, anyRole := policy[ratelimit.AnyRole]
+	if !listed && !anyRole && role != anonymousRole && role != model.RoleAdmin {
+		role = model.RoleUser
+	}
+	return policy.For(role)
+}
+
+// Login throttles login attempts with one token bucket per client IP and
+// another per username, so that neither many accounts from one address nor
+// one account from many addresses can be guessed quickly.
+func (m *RateLimitMiddleware) Login() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits := m.limits.Load()
+		if !m.take(c, "login:ip:"+c.ClientIP(), limits.loginIP) {
+			return
+		}
+		if username := loginUsername(c); username != "" {
+			if !m.take(c, "login:user:"+username, limits.loginUser) {
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// take consumes a token from the bucket under key and aborts the request
+// when there was none left.
+func (m *RateLimitMiddleware) take(c *gin.Context, key string, limit ratelimit.Limit) bool {
+	result := m.store.Take(key, limit)
+	if !result.Allowed {
+		c.Error(apperror.TooManyRequests("rate_limited", "too many requests, try again later", result.RetryAfter))
+		c.Abort()
+	}
+	return result.Allowed
+}
+
+// loginUsername reads the lowercased username from a JSON login body and puts
+// the body back for the handler. Unreadable bodies yield an empty username
+// and are left for the handler to reject.
+func loginUsername(c *gin.Context) string {
+	peeked, err := io.ReadAll(io.LimitReader(c.Request.Body, loginPeekSize))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), c.Request.Body), c.Request.Body}
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		Username string `json:"username"`
+	}
+	if json.Unmarshal(peeked, &body) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(body.Username))
+}
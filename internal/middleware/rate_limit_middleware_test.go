@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"itv/internal/config"
+	"itv/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPolicyFor(t *testing.T) {
+	user := ratelimit.Limit{Requests: 120, Period: time.Minute, Burst: 30}
+	editor := ratelimit.Limit{Requests: 300, Period: time.Minute, Burst: 50}
+	other := ratelimit.Limit{Requests: 10, Period: time.Minute, Burst: 10}
+	policy := ratelimit.Policy{"user": user, "editor": editor}
+	withAnyRole := ratelimit.Policy{"user": user, ratelimit.AnyRole: other}
+	tests := []struct {
+		name   string
+		policy ratelimit.Policy
+		role   string
+		want   ratelimit.Limit
+	}{
+		{"listed role", policy, "editor", editor},
+		{"unlisted custom role", policy, "moderator", user},
+		{"API key", policy, apiKeyRole, user},
+		{"admin", policy, "admin", ratelimit.Limit{}},
+		{"anonymous", policy, anonymousRole, ratelimit.Limit{}},
+		{"bare limit", withAnyRole, "moderator", other},
+		{"bare limit for admins", withAnyRole, "admin", other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policyFor(tt.policy, tt.role); got != tt.want {
+				t.Errorf("policyFor(%q) = %+v, want %+v", tt.role, got, tt.want)
+			}
+		})
+	}
+}
+
+// newRateLimitRouter serves /movies limited by policy, with the identity set
+// by the X-User, X-Role and X-Key headers standing in for AuthMiddleware.
+func newRateLimitRouter(t *testing.T, policy string) *gin.Engine {
+	t.Helper()
+	cfg := &config.Config{RateLimitMovies: policy}
+	m, err := NewRateLimitMiddleware(ratelimit.NewMemoryStore(), cfg, config.NewReloader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(ErrorHandler(), func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set("userID", user)
+			c.Set("role", c.GetHeader("X-Role"))
+		} else if key := c.GetHeader("X-Key"); key != "" {
+			c.Set("apiKeyID", key)
+		}
+	})
+	router.GET("/movies", m.Limit("movies"), func(c *gin.Context) {
+		c.String(http.StatusOK, "movies")
+	})
+	return router
+}
+
+func TestRateLimitMiddlewareLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		requests []http.Header
+		want     []int
+	}{
+		{"anonymous by IP", "anonymous=2/1m", []http.Header{{}, {}, {}},
+			[]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}},
+		{"one bucket per user", "user=1/1m", []http.Header{
+			{"X-User": {"1"}, "X-Role": {"user"}},
+			{"X-User": {"2"}, "X-Role": {"user"}},
+			{"X-User": {"1"}, "X-Role": {"user"}},
+		}, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}},
+		{"users apart from anonymous", "anonymous=1/1m,user=1/1m", []http.Header{
+			{},
+			{"X-User": {"1"}, "X-Role": {"user"}},
+			{},
+		}, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}},
+		{"API keys like users", "user=1/1m", []http.Header{
+			{"X-Key": {"1"}},
+			{"X-Key": {"2"}},
+			{"X-Key": {"1"}},
+		}, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}},
+		{"unlimited admins", "user=1/1m", []http.Header{
+			{"X-User": {"1"}, "X-Role": {"admin"}},
+			{"X-User": {"1"}, "X-Role": {"admin"}},
+		}, []int{http.StatusOK, http.StatusOK}},
+		{"custom role like users", "user=1/1m", []http.Header{
+			{"X-User": {"1"}, "X-Role": {"moderator"}},
+			{"X-User": {"1"}, "X-Role": {"moderator"}},
+		}, []int{http.StatusOK, http.StatusTooManyRequests}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRateLimitRouter(t, tt.policy)
+			for i, header := range tt.requests {
+				req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+				req.Header = header
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				if rec.Code != tt.want[i] {
+					t.Errorf("request %d: status = %d, want %d", i+1, rec.Code, tt.want[i])
+				}
+				if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+					t.Errorf("request %d: Retry-After is missing", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareHeaders(t *testing.T) {
+	router := newRateLimitRouter(t, "anonymous=6/1m:3")
+	want := []struct {
+		remaining string
+		status    int
+	}{
+		{"2", http.StatusOK},
+		{"1", http.StatusOK},
+		{"0", http.StatusOK},
+		{"0", http.StatusTooManyRequests},
+	}
+	for i, w := range want {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/movies", nil))
+		if rec.Code != w.status {
+			t.Errorf("request %d: status = %d, want %d", i+1, rec.Code, w.status)
+		}
+		headers := map[string]string{
+			"RateLimit-Limit":     "3",
+			"RateLimit-Remaining": w.remaining,
+			"RateLimit-Policy":    "6;w=60;burst=3",
+		}
+		for name, value := range headers {
+			if got := rec.Header().Get(name); got != value {
+				t.Errorf("request %d: %s = %q, want %q", i+1, name, got, value)
+			}
+		}
+		if rec.Header().Get("RateLimit-Reset") == "" {
+			t.Errorf("request %d: RateLimit-Reset is missing", i+1)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareReload(t *testing.T) {
+	cfg := &config.Config{RateLimitMovies: "anonymous=1/1m"}
+	m, err := NewRateLimitMiddleware(ratelimit.NewMemoryStore(), cfg, config.NewReloader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.reload(&config.Config{RateLimitMovies: "anonymous=x"}); err == nil {
+		t.Error("reload accepted an invalid policy")
+	}
+	if got := m.limits.Load().policies["movies"][anonymousRole].Requests; got != 1 {
+		t.Errorf("limit after a failed reload = %d, want 1", got)
+	}
+	if err := m.reload(&config.Config{RateLimitMovies: "anonymous=5/1m"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.limits.Load().policies["movies"][anonymousRole].Requests; got != 5 {
+		t.Errorf("limit after a reload = %d, want 5", got)
+	}
+}
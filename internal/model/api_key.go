@@ -0,0 +1,51 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey lets a program call the API without logging in. Only a hash of the
+// key is stored; Prefix, the start of the key, identifies it in listings and
+// logs. Revoked keys are kept so that their use stays traceable.
+type APIKey struct {
+	ID          uint               `gorm:"primaryKey" json:"id"`
+	Name        string             `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix      string             `gorm:"type:varchar(20);uniqueIndex;not null" json:"prefix"`
+	Hash        string             `gorm:"type:varchar(64);not null" json:"-"`
+	Permissions []APIKeyPermission `json:"permissions"`
+	CreatedByID uint               `gorm:"not null" json:"created_by_id"`
+	CreatedBy   User               `gorm:"foreignKey:CreatedByID" json:"-"`
+	ExpiresAt   *time.Time         `json:"expires_at"`
+	LastUsedAt  *time.Time         `json:"last_used_at"`
+	RevokedAt   *time.Time         `json:"revoked_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// APIKeyPermission grants a permission to an API key.
+type APIKeyPermission struct {
+	APIKeyID   uint   `gorm:"primaryKey" json:"-"`
+	Permission string `gorm:"type:varchar(50);primaryKey" json:"permission"`
+}
+
+// Active reports whether the key is neither revoked nor expired at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// PermissionNames returns the permissions granted to the key in order.
+func (k *APIKey) PermissionNames() []string {
+	names := make([]string, 0, len(k.Permissions))
+	for _, permission := range k.Permissions {
+		names = append(names, permission.Permission)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	k.CreatedAt = time.Now()
+	return nil
+}
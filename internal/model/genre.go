@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Genre is an admin-managed movie classification. Genres are hard-deleted so
+// that a removed name can be created again without clashing with the unique index.
+type Genre struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Tag is a free-form lowercase label attached to movies. Tags are created on
+// demand when a movie references them.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (g *Genre) BeforeCreate(tx *gorm.DB) error {
+	g.CreatedAt = time.Now()
+	g.UpdatedAt = time.Now()
+	return nil
+}
+
+func (g *Genre) BeforeUpdate(tx *gorm.DB) error {
+	g.UpdatedAt = time.Now()
+	return nil
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	t.CreatedAt = time.Now()
+	return nil
+}
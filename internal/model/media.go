@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MediaKindPoster   = "poster"
+	MediaKindBackdrop = "backdrop"
+	MediaKindStill    = "still"
+)
+
+// MediaAsset is an uploaded image of a movie. A movie has at most one poster
+// and one backdrop; stills form an ordered gallery.
+type MediaAsset struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	MovieID     uint           `gorm:"not null;index:idx_media_assets_movie_kind" json:"movie_id"`
+	Kind        string         `gorm:"type:varchar(20);not null;index:idx_media_assets_movie_kind" json:"kind"`
+	Key         string         `gorm:"type:varchar(255);not null" json:"key"`
+	ContentType string         `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size        int64          `gorm:"not null" json:"size"`
+	Width       int            `gorm:"not null" json:"width"`
+	Height      int            `gorm:"not null" json:"height"`
+	Position    int            `gorm:"not null;default:0" json:"position"`
+	Variants    []MediaVariant `json:"variants"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// MediaVariant is a downscaled copy of a media asset.
+type MediaVariant struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	MediaAssetID uint   `gorm:"not null;index" json:"media_asset_id"`
+	Name         string `gorm:"type:varchar(20);not null" json:"name"`
+	Key          string `gorm:"type:varchar(255);not null" json:"key"`
+	Width        int    `gorm:"not null" json:"width"`
+	Height       int    `gorm:"not null" json:"height"`
+}
+
+func (m *MediaAsset) BeforeCreate(tx *gorm.DB) error {
+	m.CreatedAt = time.Now()
+	return nil
+}
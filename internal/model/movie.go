@@ -6,18 +6,29 @@ import (
 	"gorm.io/gorm"
 )
 
+// Movie is a catalog entry. Its decimal columns are sized like their
+// precision because the SQLite driver reports the precision as the column
+// length, and AutoMigrate would otherwise rebuild the table on every start.
 type Movie struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Title       string         `gorm:"type:varchar(255);not null" json:"title"`
-	Director    string         `gorm:"type:varchar(255);not null" json:"director"`
-	Year        int            `gorm:"not null" json:"year"`
-	Plot        string         `gorm:"type:text" json:"plot"`
-	Rating      float32        `gorm:"type:decimal(3,1)" json:"rating"`
-	Duration    int            `gorm:"not null" json:"duration"` // in minutes
-	ReleaseDate string         `json:"release_date"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uint               `gorm:"primaryKey" json:"id"`
+	Title            string             `gorm:"type:varchar(255);not null" json:"title"`
+	Year             int                `gorm:"not null" json:"year"`
+	Plot             string             `gorm:"type:text" json:"plot"`
+	OriginalLanguage string             `gorm:"type:varchar(35)" json:"original_language"` // BCP 47 tag of Title and Plot
+	Rating           float32            `gorm:"type:decimal(3,1);size:3" json:"rating"`
+	UserRating       float64            `gorm:"type:decimal(4,2);size:4;not null;default:0" json:"user_rating"` // average review score
+	UserRatingCount  int                `gorm:"not null;default:0" json:"user_rating_count"`
+	Duration         int                `gorm:"not null" json:"duration"` // in minutes
+	ReleaseDate      *time.Time         `gorm:"type:date;index" json:"release_date"`
+	Genres           []Genre            `gorm:"many2many:movie_genres;" json:"genres"`
+	Tags             []Tag              `gorm:"many2many:movie_tags;" json:"tags"`
+	Credits          []MovieCredit      `json:"credits"`
+	Media            []MediaAsset       `json:"media"`
+	Releases         []MovieRelease     `json:"releases"`
+	Translations     []MovieTranslation `json:"translations"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt     `gorm:"index" json:"-"`
 }
 
 type User struct {
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CreditRoleDirector = "director"
+	CreditRoleActor    = "actor"
+	CreditRoleWriter   = "writer"
+	CreditRoleProducer = "producer"
+)
+
+type Person struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"type:varchar(255);not null;index" json:"name"`
+	Bio       string         `gorm:"type:text" json:"bio"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// MovieCredit links a person to a movie in a given role. Character is only
+// meaningful for actors; Position orders the credits within a movie.
+type MovieCredit struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	MovieID   uint   `gorm:"not null;index" json:"movie_id"`
+	PersonID  uint   `gorm:"not null;index" json:"person_id"`
+	Role      string `gorm:"type:varchar(20);not null" json:"role"`
+	Character string `gorm:"type:varchar(255)" json:"character"`
+	Position  int    `gorm:"not null;default:0" json:"position"`
+	Movie     Movie  `json:"-"`
+	Person    Person `json:"person"`
+}
+
+func (p *Person) BeforeCreate(tx *gorm.DB) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (p *Person) BeforeUpdate(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	ReleaseTypePremiere   = "premiere"
+	ReleaseTypeTheatrical = "theatrical"
+	ReleaseTypeDigital    = "digital"
+	ReleaseTypePhysical   = "physical"
+	ReleaseTypeStreaming  = "streaming"
+	ReleaseTypeTV         = "tv"
+)
+
+// MovieRelease is the release of a movie in one country through one channel,
+// e.g. its theatrical run in the US. Country is an ISO 3166-1 alpha-2 code.
+type MovieRelease struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	MovieID     uint      `gorm:"not null;uniqueIndex:idx_movie_releases_movie_country_type" json:"movie_id"`
+	Country     string    `gorm:"type:varchar(2);not null;uniqueIndex:idx_movie_releases_movie_country_type;index:idx_movie_releases_country_date" json:"country"`
+	Type        string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_movie_releases_movie_country_type" json:"type"`
+	ReleaseDate time.Time `gorm:"type:date;not null;index:idx_movie_releases_country_date" json:"release_date"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Review is a user's 1-10 score for a movie with optional text; each user has
+// at most one per movie. Hidden reviews keep counting towards the movie's
+// aggregate score but are not listed publicly.
+type Review struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MovieID   uint      `gorm:"not null;uniqueIndex:idx_reviews_movie_user" json:"movie_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_reviews_movie_user;index" json:"user_id"`
+	Score     int       `gorm:"not null" json:"score"`
+	Text      string    `gorm:"type:text" json:"text"`
+	Hidden    bool      `gorm:"not null;default:false" json:"hidden"`
+	FlagCount int       `gorm:"not null;default:0;index" json:"flag_count"`
+	User      User      `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReviewFlag records that a user reported a review, at most once per user.
+type ReviewFlag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ReviewID  uint      `gorm:"not null;uniqueIndex:idx_review_flags_review_user" json:"review_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_review_flags_review_user" json:"user_id"`
+	Reason    string    `gorm:"type:varchar(255)" json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r *Review) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Review) BeforeUpdate(tx *gorm.DB) error {
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+func (f *ReviewFlag) BeforeCreate(tx *gorm.DB) error {
+	f.CreatedAt = time.Now()
+	return nil
+}
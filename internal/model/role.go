@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Permissions a role can grant. Routes that change data require one of them
+// instead of a particular role.
+const (
+	PermissionMoviesWrite     = "movies:write"     // create and edit movies, genres, people, media and translations
+	PermissionMoviesDelete    = "movies:delete"    // delete movies, genres and people
+	PermissionUsersManage     = "users:manage"     // manage roles and assign them to users
+	PermissionReviewsModerate = "reviews:moderate" // see hidden reviews, moderate and delete reviews
+)
+
+// Permissions lists every permission.
+var Permissions = []string{
+	PermissionMoviesWrite,
+	PermissionMoviesDelete,
+	PermissionUsersManage,
+	PermissionReviewsModerate,
+}
+
+// The built-in roles. Admin always holds every permission; user is the role
+// of new accounts and holds none until granted.
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Role is a named set of permissions. Users refer to their role by name,
+// which therefore cannot change.
+type Role struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Name        string           `gorm:"type:varchar(20);uniqueIndex;not null" json:"name"`
+	Description string           `gorm:"type:text" json:"description"`
+	Permissions []RolePermission `json:"permissions"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// RolePermission grants a permission to a role.
+type RolePermission struct {
+	RoleID     uint   `gorm:"primaryKey" json:"-"`
+	Permission string `gorm:"type:varchar(50);primaryKey" json:"permission"`
+}
+
+// BuiltIn reports whether the role is created by the application and cannot
+// be deleted.
+func (r *Role) BuiltIn() bool {
+	return r.Name == RoleAdmin || r.Name == RoleUser
+}
+
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Role) BeforeUpdate(tx *gorm.DB) error {
+	r.UpdatedAt = time.Now()
+	return nil
+}
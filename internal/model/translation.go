@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// MovieTranslation holds the title and plot of a movie in one locale, a
+// BCP 47 language tag such as "ru" or "pt-BR".
+type MovieTranslation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MovieID   uint      `gorm:"not null;uniqueIndex:idx_movie_translation" json:"movie_id"`
+	Locale    string    `gorm:"type:varchar(35);not null;uniqueIndex:idx_movie_translation" json:"locale"`
+	Title     string    `gorm:"type:varchar(255);not null;index" json:"title"`
+	Plot      string    `gorm:"type:text" json:"plot"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Watchlist is a user's named list of movies. Every user has exactly one
+// default list, created on first use, which cannot be renamed or deleted.
+type Watchlist struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	UserID    uint            `gorm:"not null;uniqueIndex:idx_watchlists_user_name" json:"user_id"`
+	Name      string          `gorm:"type:varchar(100);not null;uniqueIndex:idx_watchlists_user_name" json:"name"`
+	IsDefault bool            `gorm:"not null;default:false" json:"is_default"`
+	ItemCount int             `gorm:"->;-:migration" json:"item_count"`
+	Items     []WatchlistItem `json:"items"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type WatchlistItem struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WatchlistID uint      `gorm:"not null;uniqueIndex:idx_watchlist_items_list_movie" json:"watchlist_id"`
+	MovieID     uint      `gorm:"not null;uniqueIndex:idx_watchlist_items_list_movie" json:"movie_id"`
+	Position    int       `gorm:"not null;default:0" json:"position"`
+	Movie       Movie     `json:"movie"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// WatchHistory records one viewing of a movie; rewatches add new entries.
+type WatchHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_watch_histories_user_watched" json:"user_id"`
+	MovieID   uint      `gorm:"not null;index" json:"movie_id"`
+	Movie     Movie     `json:"movie"`
+	WatchedAt time.Time `gorm:"not null;index:idx_watch_histories_user_watched" json:"watched_at"`
+}
+
+func (w *Watchlist) BeforeCreate(tx *gorm.DB) error {
+	w.CreatedAt = time.Now()
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (w *Watchlist) BeforeUpdate(tx *gorm.DB) error {
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (i *WatchlistItem) BeforeCreate(tx *gorm.DB) error {
+	i.AddedAt = time.Now()
+	return nil
+}
+
+func (h *WatchHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.WatchedAt.IsZero() {
+		h.WatchedAt = time.Now()
+	}
+	return nil
+}
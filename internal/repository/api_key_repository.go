@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"itv/internal/apperror"
+	"itv/internal/model"
+	"time"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+// FindAll returns every key, revoked and expired ones included, newest first.
+func (r *APIKeyRepository) FindAll() ([]model.APIKey, error) {
+	var keys []model.APIKey
+	result := r.preloaded().Order("id DESC").Find(&keys)
+	return keys, result.Error
+}
+
+func (r *APIKeyRepository) FindByID(id uint) (*model.APIKey, error) {
+	return r.find(r.preloaded().Where("id = ?", id))
+}
+
+func (r *APIKeyRepository) FindByPrefix(prefix string) (*model.APIKey, error) {
+	return r.find(r.preloaded().Where("prefix = ?", prefix))
+}
+
+// Create inserts the key with its permissions.
+func (r *APIKeyRepository) Create(key *model.APIKey) error {
+	return r.db.Omit("CreatedBy").Create(key).Error
+}
+
+// Revoke marks the key as revoked at the given time unless it already is.
+func (r *APIKeyRepository) Revoke(id uint, at time.Time) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", at).Error
+}
+
+// Touch records that the key was used at the given time.
+func (r *APIKeyRepository) Touch(id uint, at time.Time) error {
+	return r.db.Model(&model.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+func (r *APIKeyRepository) preloaded() *gorm.DB {
+	return r.db.Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("api_key_permissions.permission")
+	})
+}
+
+func (r *APIKeyRepository) find(query *gorm.DB) (*model.APIKey, error) {
+	var key model.APIKey
+	result := query.First(&key)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("api_key_not_found", "API key not found")
+		}
+		return nil, result.Error
+	}
+	return &key, nil
+}
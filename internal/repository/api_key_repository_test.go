@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"itv/internal/apperror"
+	"itv/internal/model"
+)
+
+func TestAPIKeyRepositoryRevoke(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewAPIKeyRepository(db)
+		admin := createUser(t, db, "root")
+		key := &model.APIKey{
+			Name:        "ingest",
+			Prefix:      "itv_000000000001",
+			Hash:        "hash",
+			CreatedByID: admin.ID,
+			Permissions: []model.APIKeyPermission{
+				{Permission: model.PermissionMoviesWrite},
+				{Permission: model.PermissionMoviesDelete},
+			},
+		}
+		if err := repo.Create(key); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.FindByPrefix(key.Prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{model.PermissionMoviesDelete, model.PermissionMoviesWrite}
+		if !slices.Equal(got.PermissionNames(), want) {
+			t.Errorf("FindByPrefix permissions = %q, want %q", got.PermissionNames(), want)
+		}
+		if _, err := repo.FindByPrefix("itv_000000000002"); !apperror.IsNotFound(err) {
+			t.Errorf("FindByPrefix of a missing key: %v, want not found", err)
+		}
+
+		used := time.Now().Add(-time.Hour).Truncate(time.Second)
+		if err := repo.Touch(key.ID, used); err != nil {
+			t.Fatal(err)
+		}
+		revoked := used.Add(time.Minute)
+		if err := repo.Revoke(key.ID, revoked); err != nil {
+			t.Fatal(err)
+		}
+		// Revoking again keeps the time it was first revoked.
+		if err := repo.Revoke(key.ID, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = repo.FindByID(key.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.LastUsedAt == nil || !got.LastUsedAt.Equal(used) {
+			t.Errorf("LastUsedAt = %v, want %v", got.LastUsedAt, used)
+		}
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(revoked) {
+			t.Errorf("RevokedAt = %v, want %v", got.RevokedAt, revoked)
+		}
+		if got.Active(time.Now()) {
+			t.Error("revoked key is active")
+		}
+	})
+}
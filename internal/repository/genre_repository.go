@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"itv/internal/apperror"
+	"itv/internal/model"
+	"itv/pkg/database"
+)
+
+type GenreRepository struct {
+	db *gorm.DB
+}
+
+func NewGenreRepository(db *gorm.DB) *GenreRepository {
+	return &GenreRepository{
+		db: db,
+	}
+}
+
+// Replica returns the repository reading from a read replica, for reads that
+// tolerate replication lag.
+func (r *GenreRepository) Replica() *GenreRepository {
+	return &GenreRepository{db: database.Replica(r.db)}
+}
+
+func (r *GenreRepository) FindAll() ([]model.Genre, error) {
+	var genres []model.Genre
+	result := r.db.Order("name").Find(&genres)
+	return genres, result.Error
+}
+
+func (r *GenreRepository) FindByID(id uint) (*model.Genre, error) {
+	var genre model.Genre
+	result := r.db.First(&genre, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("genre_not_found", "genre not found")
+		}
+		return nil, result.Error
+	}
+	return &genre, nil
+}
+
+// FindByIDs returns the genres matching ids. Unknown ids are silently skipped,
+// so callers compare the result length to detect them.
+func (r *GenreRepository) FindByIDs(ids []uint) ([]model.Genre, error) {
+	var genres []model.Genre
+	if len(ids) == 0 {
+		return genres, nil
+	}
+	result := r.db.Where("id IN ?", ids).Find(&genres)
+	return genres, result.Error
+}
+
+// ExistsByName reports whether a genre other than excludeID already uses name,
+// compared case-insensitively.
+func (r *GenreRepository) ExistsByName(name string, excludeID uint) (bool, error) {
+	var count int64
+	result := r.db.Model(&model.Genre{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+func (r *GenreRepository) Create(genre *model.Genre) error {
+	return r.db.Create(genre).Error
+}
+
+func (r *GenreRepository) Update(genre *model.Genre) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(genre).Error; err != nil {
+			return err
+		}
+		return touchGenreMovies(tx, genre.ID)
+	})
+}
+
+// Delete removes the genre together with its movie links.
+func (r *GenreRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := touchGenreMovies(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM movie_genres WHERE genre_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Genre{}, id).Error
+	})
+}
+
+func touchGenreMovies(tx *gorm.DB, genreID uint) error {
+	return touchMovies(tx, "id IN (?)", tx.Table("movie_genres").Select("movie_id").Where("genre_id = ?", genreID))
+}
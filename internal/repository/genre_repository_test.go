@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+func TestGenreRepositoryExistsByName(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewGenreRepository(db)
+		drama := &model.Genre{Name: "Drama"}
+		if err := repo.Create(drama); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range []struct {
+			name      string
+			excludeID uint
+			want      bool
+		}{
+			{"Drama", 0, true},
+			{"DRAMA", 0, true},
+			{"drama", drama.ID, false},
+			{"Comedy", 0, false},
+		} {
+			got, err := repo.ExistsByName(tc.name, tc.excludeID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("ExistsByName(%q, %d) = %v, want %v", tc.name, tc.excludeID, got, tc.want)
+			}
+		}
+	})
+}
+
+func TestGenreRepositoryDelete(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewGenreRepository(db)
+		drama := &model.Genre{Name: "Drama"}
+		if err := repo.Create(drama); err != nil {
+			t.Fatal(err)
+		}
+		movie := createMovie(t, db, model.Movie{Title: "The Piano", Genres: []model.Genre{*drama}})
+
+		if err := repo.Delete(drama.ID); err != nil {
+			t.Fatal(err)
+		}
+		got, err := NewMovieRepository(db).FindByID(movie.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Genres) != 0 {
+			t.Errorf("movie still has genres %+v", got.Genres)
+		}
+		if err := repo.Create(&model.Genre{Name: "Drama"}); err != nil {
+			t.Errorf("creating a deleted genre again: %v", err)
+		}
+	})
+}
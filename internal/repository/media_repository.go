@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"itv/internal/apperror"
+	"itv/internal/model"
+	"itv/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type MediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) *MediaRepository {
+	return &MediaRepository{
+		db: db,
+	}
+}
+
+// Replica returns the repository reading from a read replica, for reads that
+// tolerate replication lag.
+func (r *MediaRepository) Replica() *MediaRepository {
+	return &MediaRepository{db: database.Replica(r.db)}
+}
+
+func (r *MediaRepository) FindByMovie(movieID uint) ([]model.MediaAsset, error) {
+	var assets []model.MediaAsset
+	result := r.withVariants().
+		Where("movie_id = ?", movieID).
+		Order("kind, position, id").
+		Find(&assets)
+	return assets, result.Error
+}
+
+func (r *MediaRepository) FindByID(movieID, id uint) (*model.MediaAsset, error) {
+	var asset model.MediaAsset
+	result := r.withVariants().Where("movie_id = ?", movieID).First(&asset, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("media_not_found", "media not found")
+		}
+		return nil, result.Error
+	}
+	return &asset, nil
+}
+
+// Create stores the asset with its variants, placing it after the movie's
+// other assets of the same kind.
+func (r *MediaRepository) Create(asset *model.MediaAsset) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createAsset(tx, asset)
+	})
+}
+
+// Replace stores the asset and deletes the movie's other assets of the same
+// kind in one transaction. It returns the deleted assets, whose files the
+// caller removes once nothing refers to them.
+func (r *MediaRepository) Replace(asset *model.MediaAsset) ([]model.MediaAsset, error) {
+	var replaced []model.MediaAsset
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Preload("Variants").
+			Where("movie_id = ? AND kind = ?", asset.MovieID, asset.Kind).
+			Find(&replaced).Error
+		if err != nil {
+			return err
+		}
+
+		if err := createAsset(tx, asset); err != nil {
+			return err
+		}
+		for _, old := range replaced {
+			if err := deleteAsset(tx, old.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+func (r *MediaRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteAsset(tx, id)
+	})
+}
+
+func (r *MediaRepository) withVariants() *gorm.DB {
+	return r.db.Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("media_variants.width")
+	})
+}
+
+func createAsset(tx *gorm.DB, asset *model.MediaAsset) error {
+	var position int
+	err := tx.Model(&model.MediaAsset{}).
+		Where("movie_id = ? AND kind = ?", asset.MovieID, asset.Kind).
+		Select("COALESCE(MAX(position), 0) + 1").
+		Scan(&position).Error
+	if err != nil {
+		return err
+	}
+
+	asset.Position = position
+	if err := tx.Create(asset).Error; err != nil {
+		return err
+	}
+	return touchMovies(tx, "id = ?", asset.MovieID)
+}
+
+func deleteAsset(tx *gorm.DB, id uint) error {
+	movieID := tx.Model(&model.MediaAsset{}).Select("movie_id").Where("id = ?", id)
+	if err := touchMovies(tx, "id IN (?)", movieID); err != nil {
+		return err
+	}
+	if err := tx.Where("media_asset_id = ?", id).Delete(&model.MediaVariant{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.MediaAsset{}, id).Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+func TestMediaRepositoryReplace(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewMediaRepository(db)
+		movie := createMovie(t, db, model.Movie{Title: "Heat"})
+		asset := func(kind, key string) *model.MediaAsset {
+			return &model.MediaAsset{
+				MovieID:     movie.ID,
+				Kind:        kind,
+				Key:         key,
+				ContentType: "image/jpeg",
+				Size:        1,
+				Width:       1,
+				Height:      1,
+				Variants:    []model.MediaVariant{{Name: "small", Key: key + "_small", Width: 1, Height: 1}},
+			}
+		}
+
+		for _, a := range []*model.MediaAsset{asset(model.MediaKindPoster, "old"), asset(model.MediaKindStill, "still")} {
+			if err := repo.Create(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		replaced, err := repo.Replace(asset(model.MediaKindPoster, "new"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(replaced) != 1 || replaced[0].Key != "old" || len(replaced[0].Variants) != 1 {
+			t.Errorf("Replace returned %+v, want the old poster with its variant", replaced)
+		}
+
+		assets, err := repo.FindByMovie(movie.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, a := range assets {
+			keys = append(keys, a.Key)
+		}
+		if want := []string{"new", "still"}; !slices.Equal(keys, want) {
+			t.Errorf("FindByMovie after Replace = %q, want %q", keys, want)
+		}
+
+		var variants int64
+		if err := db.Model(&model.MediaVariant{}).Count(&variants).Error; err != nil {
+			t.Fatal(err)
+		}
+		if variants != 2 {
+			t.Errorf("%d variants left, want 2", variants)
+		}
+	})
+}
@@ -3,9 +3,29 @@ package repository
 import (
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv/internal/apperror"
 	"itv/internal/model"
+	"itv/pkg/database"
+	"slices"
+	"strings"
+	"time"
 )
 
+// MovieFilter narrows movie listings by genre and tag names. Names must be
+// lowercase. With MatchAll a movie has to carry every listed genre and tag,
+// otherwise any one of them is enough. The inclusive release window applies
+// to the original release date, or to the regional releases in Country when
+// it is set.
+type MovieFilter struct {
+	Genres       []string
+	Tags         []string
+	MatchAll     bool
+	ReleasedFrom *time.Time
+	ReleasedTo   *time.Time
+	Country      string
+}
+
 type MovieRepository struct {
 	db *gorm.DB
 }
@@ -16,39 +36,329 @@ func NewMovieRepository(db *gorm.DB) *MovieRepository {
 	}
 }
 
-func (r *MovieRepository) FindAll() ([]model.Movie, error) {
+// Replica returns the repository reading from a read replica, for reads that
+// tolerate replication lag.
+func (r *MovieRepository) Replica() *MovieRepository {
+	return &MovieRepository{db: database.Replica(r.db)}
+}
+
+func (r *MovieRepository) FindAll(filter MovieFilter) ([]model.Movie, error) {
 	var movies []model.Movie
-	result := r.db.Find(&movies)
+	result := r.filtered(filter).Find(&movies)
 	return movies, result.Error
 }
 
 func (r *MovieRepository) FindByID(id uint) (*model.Movie, error) {
 	var movie model.Movie
-	result := r.db.First(&movie, id)
+	result := r.preloaded().First(&movie, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("movie not found")
+			return nil, apperror.NotFound("movie_not_found", "movie not found")
 		}
 		return nil, result.Error
 	}
 	return &movie, nil
 }
 
+// ExistsByTitleAndYear reports whether a movie with the title, compared
+// case-insensitively, came out in year.
+func (r *MovieRepository) ExistsByTitleAndYear(title string, year int) (bool, error) {
+	var count int64
+	result := r.db.Model(&model.Movie{}).
+		Where("LOWER(title) = LOWER(?) AND year = ?", title, year).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// Create inserts the movie with its credits and links it to the already
+// persisted movie.Genres and movie.Tags.
 func (r *MovieRepository) Create(movie *model.Movie) error {
-	return r.db.Create(movie).Error
+	return r.db.Omit("Genres.*", "Tags.*").Create(movie).Error
 }
 
+// Update saves the movie columns and replaces its genre and tag links, its
+// credits and its releases with movie.Genres, movie.Tags, movie.Credits and
+// movie.Releases. The review aggregates are left alone since ReviewRepository
+// owns them.
 func (r *MovieRepository) Update(movie *model.Movie) error {
-	return r.db.Save(movie).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations, "UserRating", "UserRatingCount").Save(movie).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(movie).Omit("Genres.*").Association("Genres").Replace(movie.Genres); err != nil {
+			return err
+		}
+		if err := tx.Model(movie).Omit("Tags.*").Association("Tags").Replace(movie.Tags); err != nil {
+			return err
+		}
+		if err := tx.Where("movie_id = ?", movie.ID).Delete(&model.MovieCredit{}).Error; err != nil {
+			return err
+		}
+		for i := range movie.Credits {
+			movie.Credits[i].ID = 0
+			movie.Credits[i].MovieID = movie.ID
+		}
+		if len(movie.Credits) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&movie.Credits).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("movie_id = ?", movie.ID).Delete(&model.MovieRelease{}).Error; err != nil {
+			return err
+		}
+		for i := range movie.Releases {
+			movie.Releases[i].ID = 0
+			movie.Releases[i].MovieID = movie.ID
+		}
+		if len(movie.Releases) == 0 {
+			return nil
+		}
+		return tx.Create(&movie.Releases).Error
+	})
 }
 
+// Delete soft-deletes the movie. Its updated_at is bumped first so that the
+// latest change of a listing it belonged to moves forward.
 func (r *MovieRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Movie{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := touchMovies(tx, "id = ?", id); err != nil {
+			return err
+		}
+		return tx.Delete(&model.Movie{}, id).Error
+	})
+}
+
+// Version returns the number of movies matching the filter and the latest
+// updated_at among them, deleted movies included, which together change
+// whenever the listing does.
+func (r *MovieRepository) Version(filter MovieFilter) (int64, time.Time, error) {
+	return r.version(func(db *gorm.DB) *gorm.DB {
+		return r.applyFilter(db, filter)
+	})
+}
+
+// SearchVersion is Version for the results of SearchMovies.
+func (r *MovieRepository) SearchVersion(query string, filter MovieFilter) (int64, time.Time, error) {
+	return r.version(func(db *gorm.DB) *gorm.DB {
+		return r.applySearch(r.applyFilter(db, filter), query)
+	})
 }
 
-func (r *MovieRepository) SearchMovies(query string) ([]model.Movie, error) {
+// FindOriginalLanguages maps the given movie IDs to their original language.
+func (r *MovieRepository) FindOriginalLanguages(ids []uint) (map[uint]string, error) {
+	languages := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return languages, nil
+	}
+
+	var rows []struct {
+		ID               uint
+		OriginalLanguage string
+	}
+	err := r.db.Model(&model.Movie{}).Select("id, original_language").Where("id IN ?", ids).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		languages[row.ID] = row.OriginalLanguage
+	}
+	return languages, nil
+}
+
+// SearchMovies matches the query against the title, plot and people of a
+// movie and against its translated titles.
+func (r *MovieRepository) SearchMovies(query string, filter MovieFilter) ([]model.Movie, error) {
 	var movies []model.Movie
-	result := r.db.Where("title LIKE ? OR director LIKE ? OR plot LIKE ?",
-		"%"+query+"%", "%"+query+"%", "%"+query+"%").Find(&movies)
+	result := r.applySearch(r.filtered(filter), query).Find(&movies)
 	return movies, result.Error
 }
+
+// FindRelated returns up to limit movies other than ids that share a person,
+// tag or genre with any of them, with what recommendations score preloaded.
+// Movies sharing a person or tag come before those sharing only a genre, the
+// most rated first, so that the strongest candidates survive the limit.
+func (r *MovieRepository) FindRelated(ids []uint, limit int) ([]model.Movie, error) {
+	if len(ids) == 0 {
+		return []model.Movie{}, nil
+	}
+
+	sharing := func(table, column string) *gorm.DB {
+		return r.db.Table(table).Select(table+".movie_id").
+			Where(table+"."+column+" IN (?)", r.db.Table(table).Select(column).Where("movie_id IN ?", ids))
+	}
+	related := func(exclude []uint, limit int, query interface{}, args ...interface{}) ([]uint, error) {
+		var found []uint
+		err := r.db.Model(&model.Movie{}).
+			Where("movies.id NOT IN ?", exclude).
+			Where(query, args...).
+			Order("movies.user_rating_count DESC, movies.id").
+			Limit(limit).
+			Pluck("movies.id", &found).Error
+		return found, err
+	}
+
+	found, err := related(ids, limit, "movies.id IN (?) OR movies.id IN (?)",
+		sharing("movie_credits", "person_id"), sharing("movie_tags", "tag_id"))
+	if err != nil {
+		return nil, err
+	}
+	if len(found) < limit {
+		more, err := related(slices.Concat(ids, found), limit-len(found), "movies.id IN (?)", sharing("movie_genres", "genre_id"))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, more...)
+	}
+	return r.FindFeatures(found)
+}
+
+// FindPopular returns up to limit movies other than exclude, best first by
+// the Bayesian average rating recommendations fall back to.
+func (r *MovieRepository) FindPopular(exclude []uint, limit int) ([]model.Movie, error) {
+	var movies []model.Movie
+	query := r.features()
+	if len(exclude) > 0 {
+		query = query.Where("movies.id NOT IN ?", exclude)
+	}
+	result := query.
+		Order("(movies.user_rating * movies.user_rating_count + 30.0) / (movies.user_rating_count + 5) DESC, movies.id").
+		Limit(limit).
+		Find(&movies)
+	return movies, result.Error
+}
+
+// FindFeatures returns the movies with the given IDs with their genres, tags
+// and credits, which is what recommendations score.
+func (r *MovieRepository) FindFeatures(ids []uint) ([]model.Movie, error) {
+	var movies []model.Movie
+	if len(ids) == 0 {
+		return movies, nil
+	}
+	result := r.features().Where("movies.id IN ?", ids).Find(&movies)
+	return movies, result.Error
+}
+
+func (r *MovieRepository) applySearch(db *gorm.DB, query string) *gorm.DB {
+	people := r.db.Table("movie_credits").
+		Select("movie_credits.movie_id").
+		Joins("JOIN people ON people.id = movie_credits.person_id AND people.deleted_at IS NULL").
+		Where("LOWER(people.name) LIKE ? ESCAPE '!'", containing(query))
+	translations := r.db.Table("movie_translations").
+		Select("movie_translations.movie_id").
+		Where("LOWER(movie_translations.title) LIKE ? ESCAPE '!'", containing(query))
+	return db.Where("LOWER(title) LIKE ? ESCAPE '!' OR LOWER(plot) LIKE ? ESCAPE '!' OR movies.id IN (?) OR movies.id IN (?)",
+		containing(query), containing(query), people, translations)
+}
+
+// version counts the movies selected by scope and finds their latest change.
+// The latter is read from the newest row rather than with MAX so that the
+// column keeps its type on every driver.
+func (r *MovieRepository) version(scope func(db *gorm.DB) *gorm.DB) (int64, time.Time, error) {
+	var count int64
+	if err := scope(r.db.Model(&model.Movie{})).Count(&count).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+
+	var latest []time.Time
+	err := scope(r.db.Unscoped().Model(&model.Movie{})).
+		Where("movies.updated_at IS NOT NULL").
+		Order("movies.updated_at DESC").
+		Limit(1).
+		Pluck("movies.updated_at", &latest).Error
+	if err != nil || len(latest) == 0 {
+		return count, time.Time{}, err
+	}
+	return count, latest[0], nil
+}
+
+func (r *MovieRepository) preloaded() *gorm.DB {
+	return r.db.Preload("Genres", func(db *gorm.DB) *gorm.DB {
+		return db.Order("genres.name")
+	}).Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("tags.name")
+	}).Preload("Credits", func(db *gorm.DB) *gorm.DB {
+		return db.Order("movie_credits.position, movie_credits.id")
+	}).Preload("Credits.Person").Preload("Releases", func(db *gorm.DB) *gorm.DB {
+		return db.Order("movie_releases.release_date, movie_releases.country")
+	}).Preload("Media", func(db *gorm.DB) *gorm.DB {
+		return db.Order("media_assets.position, media_assets.id")
+	}).Preload("Media.Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("media_variants.width")
+	}).Preload("Translations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("movie_translations.locale")
+	})
+}
+
+func (r *MovieRepository) features() *gorm.DB {
+	return r.db.Preload("Genres").Preload("Tags").Preload("Credits").Preload("Credits.Person")
+}
+
+func (r *MovieRepository) filtered(filter MovieFilter) *gorm.DB {
+	return r.applyFilter(r.preloaded(), filter)
+}
+
+func (r *MovieRepository) applyFilter(query *gorm.DB, filter MovieFilter) *gorm.DB {
+	if len(filter.Genres) > 0 {
+		sub := r.db.Table("movie_genres").
+			Select("movie_genres.movie_id").
+			Joins("JOIN genres ON genres.id = movie_genres.genre_id").
+			Where("LOWER(genres.name) IN ?", filter.Genres)
+		if filter.MatchAll {
+			sub = sub.Group("movie_genres.movie_id").
+				Having("COUNT(DISTINCT genres.id) = ?", len(filter.Genres))
+		}
+		query = query.Where("movies.id IN (?)", sub)
+	}
+
+	if len(filter.Tags) > 0 {
+		sub := r.db.Table("movie_tags").
+			Select("movie_tags.movie_id").
+			Joins("JOIN tags ON tags.id = movie_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags)
+		if filter.MatchAll {
+			sub = sub.Group("movie_tags.movie_id").
+				Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		}
+		query = query.Where("movies.id IN (?)", sub)
+	}
+
+	if filter.Country != "" {
+		sub := r.db.Table("movie_releases").
+			Select("movie_releases.movie_id").
+			Where("movie_releases.country = ?", filter.Country)
+		if filter.ReleasedFrom != nil {
+			sub = sub.Where("movie_releases.release_date >= ?", *filter.ReleasedFrom)
+		}
+		if filter.ReleasedTo != nil {
+			sub = sub.Where("movie_releases.release_date <= ?", *filter.ReleasedTo)
+		}
+		query = query.Where("movies.id IN (?)", sub)
+	} else {
+		if filter.ReleasedFrom != nil {
+			query = query.Where("movies.release_date >= ?", *filter.ReleasedFrom)
+		}
+		if filter.ReleasedTo != nil {
+			query = query.Where("movies.release_date <= ?", *filter.ReleasedTo)
+		}
+	}
+
+	return query
+}
+
+// containing returns a pattern for LOWER(column) LIKE ? ESCAPE '!' that
+// matches values containing query regardless of case. ILIKE exists only in
+// PostgreSQL and the case sensitivity of LIKE differs between drivers, so
+// both sides are lowercased instead. The escape character is ! because MySQL
+// treats a backslash in a string literal as an escape of its own.
+func containing(query string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(query)) + "%"
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// touchMovies bumps updated_at of the movies matching the condition, for
+// changes to related rows that show up in movie listings.
+func touchMovies(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	return tx.Model(&model.Movie{}).Where(query, args...).UpdateColumn("updated_at", time.Now()).Error
+}
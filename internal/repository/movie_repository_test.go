@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+func TestMovieRepositorySearch(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		director := &model.Person{Name: "Lana Wachowski"}
+		if err := NewPersonRepository(db).Create(director); err != nil {
+			t.Fatal(err)
+		}
+		createMovie(t, db, model.Movie{
+			Title:   "The Matrix",
+			Plot:    "A hacker learns what reality is.",
+			Credits: []model.MovieCredit{{PersonID: director.ID, Role: model.CreditRoleDirector}},
+		})
+		createMovie(t, db, model.Movie{Title: "Heat", Plot: "A crew that gives 100% to every job."})
+		up := createMovie(t, db, model.Movie{Title: "Up", Plot: "An old man ties balloons to his house."})
+		err := NewTranslationRepository(db).Save(&model.MovieTranslation{MovieID: up.ID, Locale: "fr", Title: "Là-haut"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		repo := NewMovieRepository(db)
+		for _, tc := range []struct {
+			query string
+			want  []string
+		}{
+			{"matrix", []string{"The Matrix"}},
+			{"THE MATRIX", []string{"The Matrix"}},
+			{"REALITY", []string{"The Matrix"}},
+			{"wachowski", []string{"The Matrix"}},
+			{"HAUT", []string{"Up"}},
+			{"100%", []string{"Heat"}},
+			{"%", []string{"Heat"}},
+			{"_", nil},
+			{"!", nil},
+			{"nothing like it", nil},
+		} {
+			movies, err := repo.SearchMovies(tc.query, MovieFilter{})
+			if err != nil {
+				t.Fatalf("SearchMovies(%q): %v", tc.query, err)
+			}
+			got := movieTitles(movies)
+			sort.Strings(got)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("SearchMovies(%q) = %q, want %q", tc.query, got, tc.want)
+			}
+
+			count, _, err := repo.SearchVersion(tc.query, MovieFilter{})
+			if err != nil {
+				t.Fatalf("SearchVersion(%q): %v", tc.query, err)
+			}
+			if count != int64(len(tc.want)) {
+				t.Errorf("SearchVersion(%q) counted %d movies, want %d", tc.query, count, len(tc.want))
+			}
+		}
+	})
+}
+
+func TestMovieRepositoryFilter(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		genres := []model.Genre{{Name: "Drama"}, {Name: "Crime"}}
+		for i := range genres {
+			if err := NewGenreRepository(db).Create(&genres[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tags, err := NewTagRepository(db).FindOrCreate([]string{"heist", "classic"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		createMovie(t, db, model.Movie{
+			Title:       "Heat",
+			ReleaseDate: date("1995-12-15"),
+			Genres:      genres,
+			Tags:        tags,
+			Releases:    []model.MovieRelease{{Country: "DE", Type: "theatrical", ReleaseDate: *date("1996-02-22")}},
+		})
+		createMovie(t, db, model.Movie{Title: "The Piano", ReleaseDate: date("1993-05-19"), Genres: genres[:1]})
+		createMovie(t, db, model.Movie{Title: "Up", ReleaseDate: date("2009-05-29")})
+
+		repo := NewMovieRepository(db)
+		for _, tc := range []struct {
+			name   string
+			filter MovieFilter
+			want   []string
+		}{
+			{"no filter", MovieFilter{}, []string{"Heat", "The Piano", "Up"}},
+			{"any genre", MovieFilter{Genres: []string{"drama", "crime"}}, []string{"Heat", "The Piano"}},
+			{"all genres", MovieFilter{Genres: []string{"drama", "crime"}, MatchAll: true}, []string{"Heat"}},
+			{"tag", MovieFilter{Tags: []string{"classic"}}, []string{"Heat"}},
+			{"released from", MovieFilter{ReleasedFrom: date("1995-12-15")}, []string{"Heat", "Up"}},
+			{"released window", MovieFilter{ReleasedFrom: date("1993-01-01"), ReleasedTo: date("1995-12-15")}, []string{"Heat", "The Piano"}},
+			{"country release", MovieFilter{Country: "DE", ReleasedFrom: date("1996-02-22"), ReleasedTo: date("1996-02-22")}, []string{"Heat"}},
+			{"country outside window", MovieFilter{Country: "DE", ReleasedTo: date("1995-12-31")}, nil},
+		} {
+			movies, err := repo.FindAll(tc.filter)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			got := movieTitles(movies)
+			sort.Strings(got)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+			}
+		}
+	})
+}
+
+func TestMovieRepositoryUpdateAndDelete(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		genre := &model.Genre{Name: "Drama"}
+		if err := NewGenreRepository(db).Create(genre); err != nil {
+			t.Fatal(err)
+		}
+		movie := createMovie(t, db, model.Movie{Title: "Heat", Genres: []model.Genre{*genre}})
+
+		repo := NewMovieRepository(db)
+		_, before, err := repo.Version(MovieFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		movie.Title = "Heat (1995)"
+		movie.Genres = nil
+		if err := repo.Update(movie); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.FindByID(movie.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != "Heat (1995)" || len(got.Genres) != 0 {
+			t.Errorf("after Update got title %q and %d genres", got.Title, len(got.Genres))
+		}
+
+		if err := repo.Delete(movie.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.FindByID(movie.ID); err == nil {
+			t.Error("FindByID found the deleted movie")
+		}
+		count, after, err := repo.Version(MovieFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 || after.Before(before) {
+			t.Errorf("Version after Delete = %d, %s; want 0 and no earlier than %s", count, after, before)
+		}
+	})
+}
+
+func TestMovieRepositoryExistsByTitleAndYear(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		createMovie(t, db, model.Movie{Title: "Solaris", Year: 1972})
+		repo := NewMovieRepository(db)
+
+		for _, tc := range []struct {
+			title string
+			year  int
+			want  bool
+		}{
+			{"Solaris", 1972, true},
+			{"SOLARIS", 1972, true},
+			{"Solaris", 2002, false},
+			{"Stalker", 1972, false},
+		} {
+			got, err := repo.ExistsByTitleAndYear(tc.title, tc.year)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("ExistsByTitleAndYear(%q, %d) = %v, want %v", tc.title, tc.year, got, tc.want)
+			}
+		}
+	})
+}
+
+func TestMovieRepositoryFindRelated(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		director := &model.Person{Name: "Michael Mann"}
+		if err := NewPersonRepository(db).Create(director); err != nil {
+			t.Fatal(err)
+		}
+		drama, heist := &model.Genre{Name: "Drama"}, &model.Tag{Name: "heist"}
+		if err := db.Create(drama).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(heist).Error; err != nil {
+			t.Fatal(err)
+		}
+		directed := func() []model.MovieCredit {
+			return []model.MovieCredit{{PersonID: director.ID, Role: model.CreditRoleDirector}}
+		}
+
+		heat := createMovie(t, db, model.Movie{Title: "Heat", Genres: []model.Genre{*drama}, Tags: []model.Tag{*heist}, Credits: directed()})
+		createMovie(t, db, model.Movie{Title: "Collateral", Credits: directed(), UserRating: 10, UserRatingCount: 3})
+		createMovie(t, db, model.Movie{Title: "Inception", Tags: []model.Tag{*heist}, UserRatingCount: 1})
+		createMovie(t, db, model.Movie{Title: "Up", Genres: []model.Genre{*drama}, UserRating: 9, UserRatingCount: 10})
+		createMovie(t, db, model.Movie{Title: "Amelie", Genres: []model.Genre{*drama}, UserRating: 8, UserRatingCount: 5})
+		createMovie(t, db, model.Movie{Title: "Alien"})
+		ronin := createMovie(t, db, model.Movie{Title: "Ronin", Credits: directed(), UserRatingCount: 50})
+
+		repo := NewMovieRepository(db)
+		if err := repo.Delete(ronin.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range []struct {
+			limit int
+			want  []string
+		}{
+			{1, []string{"Collateral"}},
+			{3, []string{"Collateral", "Inception", "Up"}},
+			{10, []string{"Amelie", "Collateral", "Inception", "Up"}},
+		} {
+			movies, err := repo.FindRelated([]uint{heat.ID}, tc.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := movieTitles(movies)
+			sort.Strings(got)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("FindRelated(limit %d) = %q, want %q", tc.limit, got, tc.want)
+			}
+			for _, movie := range movies {
+				if movie.Title == "Collateral" && (len(movie.Credits) != 1 || movie.Credits[0].Person.Name != "Michael Mann") {
+					t.Errorf("FindRelated did not preload the credits: %+v", movie.Credits)
+				}
+			}
+		}
+
+		popular, err := repo.FindPopular([]uint{heat.ID}, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := movieTitles(popular), []string{"Up", "Collateral", "Amelie"}; !slices.Equal(got, want) {
+			t.Errorf("FindPopular = %q, want %q", got, want)
+		}
+	})
+}
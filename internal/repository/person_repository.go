@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv/internal/apperror"
+	"itv/internal/model"
+	"itv/pkg/database"
+)
+
+type PersonRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonRepository(db *gorm.DB) *PersonRepository {
+	return &PersonRepository{
+		db: db,
+	}
+}
+
+// Replica returns the repository reading from a read replica, for reads that
+// tolerate replication lag.
+func (r *PersonRepository) Replica() *PersonRepository {
+	return &PersonRepository{db: database.Replica(r.db)}
+}
+
+func (r *PersonRepository) FindAll(query string) ([]model.Person, error) {
+	var people []model.Person
+	db := r.db.Order("name")
+	if query != "" {
+		db = db.Where("LOWER(name) LIKE ? ESCAPE '!'", containing(query))
+	}
+	result := db.Find(&people)
+	return people, result.Error
+}
+
+func (r *PersonRepository) FindByID(id uint) (*model.Person, error) {
+	var person model.Person
+	result := r.db.First(&person, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("person_not_found", "person not found")
+		}
+		return nil, result.Error
+	}
+	return &person, nil
+}
+
+// FindByIDs returns the people matching ids. Unknown ids are silently skipped,
+// so callers compare the result length to detect them.
+func (r *PersonRepository) FindByIDs(ids []uint) ([]model.Person, error) {
+	var people []model.Person
+	if len(ids) == 0 {
+		return people, nil
+	}
+	result := r.db.Where("id IN ?", ids).Find(&people)
+	return people, result.Error
+}
+
+// FindOrCreateByName returns the oldest person whose name matches
+// case-insensitively, creating one when there is none.
+func (r *PersonRepository) FindOrCreateByName(name string) (*model.Person, error) {
+	var person model.Person
+	result := r.db.Where("LOWER(name) = LOWER(?)", name).Order("id").Limit(1).Find(&person)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if person.ID != 0 {
+		return &person, nil
+	}
+
+	person = model.Person{Name: name}
+	if err := r.db.Create(&person).Error; err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+// FindCredits returns the person's credits on movies that still exist,
+// newest movies first.
+func (r *PersonRepository) FindCredits(personID uint) ([]model.MovieCredit, error) {
+	var credits []model.MovieCredit
+	result := r.db.InnerJoins("Movie").
+		Where("movie_credits.person_id = ?", personID).
+		Order(clause.OrderByColumn{Column: clause.Column{Table: "Movie", Name: "year"}, Desc: true}).
+		Order("movie_credits.position").
+		Find(&credits)
+	return credits, result.Error
+}
+
+func (r *PersonRepository) Create(person *model.Person) error {
+	return r.db.Create(person).Error
+}
+
+func (r *PersonRepository) Update(person *model.Person) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(person).Error; err != nil {
+			return err
+		}
+		return touchPersonMovies(tx, person.ID)
+	})
+}
+
+// Delete removes the person together with all of their credits.
+func (r *PersonRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := touchPersonMovies(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Where("person_id = ?", id).Delete(&model.MovieCredit{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Person{}, id).Error
+	})
+}
+
+func touchPersonMovies(tx *gorm.DB, personID uint) error {
+	return touchMovies(tx, "id IN (?)", tx.Table("movie_credits").Select("movie_id").Where("person_id = ?", personID))
+}
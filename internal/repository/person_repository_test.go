@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+func TestPersonRepositoryFindAll(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewPersonRepository(db)
+		for _, name := range []string{"Lana Wachowski", "Lilly Wachowski", "Michael Mann"} {
+			if err := repo.Create(&model.Person{Name: name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for query, want := range map[string]int{
+			"":          3,
+			"WACHOWSKI": 2,
+			"mann":      1,
+			"%":         0,
+		} {
+			people, err := repo.FindAll(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(people) != want {
+				t.Errorf("FindAll(%q) found %d people, want %d", query, len(people), want)
+			}
+		}
+	})
+}
+
+func TestPersonRepositoryFindOrCreateByName(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewPersonRepository(db)
+		first, err := repo.FindOrCreateByName("Michael Mann")
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := repo.FindOrCreateByName("michael mann")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first.ID != second.ID {
+			t.Errorf("FindOrCreateByName created %d and %d for the same name", first.ID, second.ID)
+		}
+	})
+}
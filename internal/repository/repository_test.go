@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"itv/internal/model"
+	"itv/pkg/database"
+)
+
+// testDatabases are the databases every repository test runs against. SQLite
+// always runs, in memory. PostgreSQL and MySQL run when TEST_POSTGRES_DSN or
+// TEST_MYSQL_DSN name a database the tests may wipe, for example
+//
+//	TEST_POSTGRES_DSN="host=localhost user=postgres password=postgres dbname=movies_test sslmode=disable"
+//	TEST_MYSQL_DSN="root:root@tcp(localhost:3306)/movies_test?parseTime=true&loc=UTC"
+var testDatabases = []struct {
+	driver string
+	env    string
+	open   func(dsn string) gorm.Dialector
+}{
+	{driver: "sqlite", open: sqlite.Open},
+	{driver: "postgres", env: "TEST_POSTGRES_DSN", open: postgres.Open},
+	{driver: "mysql", env: "TEST_MYSQL_DSN", open: mysql.Open},
+}
+
+// eachDatabase runs test as a subtest per database, each on a freshly
+// migrated, empty schema.
+func eachDatabase(t *testing.T, test func(t *testing.T, db *gorm.DB)) {
+	for _, target := range testDatabases {
+		t.Run(target.driver, func(t *testing.T) {
+			dsn := "file::memory:?_pragma=foreign_keys(1)"
+			if target.env != "" {
+				dsn = os.Getenv(target.env)
+				if dsn == "" {
+					t.Skipf("%s is not set", target.env)
+				}
+			}
+
+			db, err := gorm.Open(target.open(dsn), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("opening %s: %v", target.driver, err)
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { sqlDB.Close() })
+
+			if target.driver == "sqlite" {
+				// Every connection to :memory: has a database of its own.
+				sqlDB.SetMaxOpenConns(1)
+			} else {
+				tables, err := db.Migrator().GetTables()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, table := range tables {
+					if err := db.Migrator().DropTable(table); err != nil {
+						t.Fatalf("dropping %s: %v", table, err)
+					}
+				}
+			}
+
+			if err := database.Migrate(db); err != nil {
+				t.Fatal(err)
+			}
+			test(t, db)
+		})
+	}
+}
+
+func createMovie(t *testing.T, db *gorm.DB, movie model.Movie) *model.Movie {
+	t.Helper()
+	if movie.Year == 0 {
+		movie.Year = 2000
+	}
+	if movie.Duration == 0 {
+		movie.Duration = 120
+	}
+	if err := NewMovieRepository(db).Create(&movie); err != nil {
+		t.Fatalf("creating movie %q: %v", movie.Title, err)
+	}
+	return &movie
+}
+
+func createUser(t *testing.T, db *gorm.DB, username string) *model.User {
+	t.Helper()
+	user := &model.User{Username: username, Password: "hash", Role: "user"}
+	if err := NewUserRepository(db).Create(user); err != nil {
+		t.Fatalf("creating user %q: %v", username, err)
+	}
+	return user
+}
+
+func date(value string) *time.Time {
+	d, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return &d
+}
+
+func movieTitles(movies []model.Movie) []string {
+	titles := make([]string, 0, len(movies))
+	for _, movie := range movies {
+		titles = append(titles, movie.Title)
+	}
+	return titles
+}
+
+// TestMigrateAgain guards against AutoMigrate seeing differences that are not
+// there, which makes it rebuild tables on drivers without ALTER COLUMN.
+func TestMigrateAgain(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		person := &model.Person{Name: "Michael Mann"}
+		if err := NewPersonRepository(db).Create(person); err != nil {
+			t.Fatal(err)
+		}
+		movie := createMovie(t, db, model.Movie{
+			Title:   "Heat",
+			Rating:  8.3,
+			Credits: []model.MovieCredit{{PersonID: person.ID, Role: model.CreditRoleDirector}},
+		})
+
+		// The SQLite migrator alters a column by copying the table into a
+		// new one named with a __temp suffix.
+		var rebuilt []string
+		err := db.Callback().Raw().After("gorm:raw").Register("test:rebuilds", func(tx *gorm.DB) {
+			if sql := tx.Statement.SQL.String(); strings.Contains(sql, "__temp") {
+				rebuilt = append(rebuilt, sql)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := database.Migrate(db); err != nil {
+			t.Fatalf("migrating again: %v", err)
+		}
+		if len(rebuilt) > 0 {
+			t.Errorf("migrating again rebuilt tables: %q", rebuilt)
+		}
+		got, err := NewMovieRepository(db).FindByID(movie.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Rating != 8.3 || len(got.Credits) != 1 {
+			t.Errorf("after migrating again got rating %v and %d credits", got.Rating, len(got.Credits))
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv/internal/apperror"
+	"itv/internal/model"
+	"itv/pkg/database"
+	"time"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{
+		db: db,
+	}
+}
+
+// Replica returns the repository reading from a read replica, for reads that
+// tolerate replication lag.
+func (r *ReviewRepository) Replica() *ReviewRepository {
+	return &ReviewRepository{db: database.Replica(r.db)}
+}
+
+// FindByMovie returns the movie's reviews, newest first. Hidden reviews are
+// only included when includeHidden is set.
+func (r *ReviewRepository) FindByMovie(movieID uint, includeHidden bool) ([]model.Review, error) {
+	var reviews []model.Review
+	db := r.db.Preload("User").Where("movie_id = ?", movieID)
+	if !includeHidden {
+		db = db.Where("hidden = ?", false)
+	}
+	result := db.Order("created_at DESC").Find(&reviews)
+	return reviews, result.Error
+}
+
+// FindFlagged returns the reviews with unresolved flags, most flagged first.
+func (r *ReviewRepository) FindFlagged() ([]model.Review, error) {
+	var reviews []model.Review
+	result := r.db.Preload("User").
+		Where("flag_count > ?", 0).
+		Order("flag_count DESC, created_at").
+		Find(&reviews)
+	return reviews, result.Error
+}
+
+// FindByUser returns every review written by the user.
+func (r *ReviewRepository) FindByUser(userID uint) ([]model.Review, error) {
+	var reviews []model.Review
+	result := r.db.Where("user_id = ?", userID).Find(&reviews)
+	return reviews, result.Error
+}
+
+// FindLikesOfMovies returns the reviews scoring at least minScore on any of
+// movieIDs, written by users other than excludeUserID.
+func (r *ReviewRepository) FindLikesOfMovies(movieIDs []uint, minScore int, excludeUserID uint) ([]model.Review, error) {
+	var reviews []model.Review
+	if len(movieIDs) == 0 {
+		return reviews, nil
+	}
+	result := r.db.Select("movie_id", "user_id", "score").
+		Where("movie_id IN ? AND score >= ? AND user_id <> ?", movieIDs, minScore, excludeUserID).
+		Find(&reviews)
+	return reviews, result.Error
+}
+
+// FindLikesByUsers returns the reviews scoring at least minScore written by
+// any of userIDs.
+func (r *ReviewRepository) FindLikesByUsers(userIDs []uint, minScore int) ([]model.Review, error) {
+	var reviews []model.Review
+	if len(userIDs) == 0 {
+		return reviews, nil
+	}
+	result := r.db.Select("movie_id", "user_id", "score").
+		Where("user_id IN ? AND score >= ?", userIDs, minScore).
+		Find(&reviews)
+	return reviews, result.Error
+}
+
+func (r *ReviewRepository) FindByID(id uint) (*model.Review, error) {
+	var review model.Review
+	result := r.db.Preload("User").First(&review, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("review_not_found", "review not found")
+		}
+		return nil, result.Error
+	}
+	return &review, nil
+}
+
+func (r *ReviewRepository) FindByMovieAndUser(movieID, userID uint) (*model.Review, error) {
+	var review model.Review
+	result := r.db.Preload("User").Where("movie_id = ? AND user_id = ?", movieID, userID).First(&review)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("review_not_found", "review not found")
+		}
+		return nil, result.Error
+	}
+	return &review, nil
+}
+
+// Upsert creates the user's review of the movie or overwrites its score and
+// text, and refreshes the movie's aggregates in the same transaction. It
+// reports whether a new review was created.
+func (r *ReviewRepository) Upsert(review *model.Review) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+
+		var existing model.Review
+		result := tx.Where("movie_id = ? AND user_id = ?", review.MovieID, review.UserID).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if existing.ID == 0 {
+			created = true
+			if err := tx.Omit("User").Create(review).Error; err != nil {
+				return err
+			}
+		} else {
+			existing.Score = review.Score
+			existing.Text = review.Text
+			if err := tx.Omit("User").Save(&existing).Error; err != nil {
+				return err
+			}
+			*review = existing
+		}
+
+		return refreshMovieRating(tx, review.MovieID)
+	})
+	return created, err
+}
+
+// Delete removes the review and its flags and refreshes the movie's aggregates.
+func (r *ReviewRepository) Delete(review *model.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", review.ID).Delete(&model.ReviewFlag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Review{}, review.ID).Error; err != nil {
+			return err
+		}
+		return refreshMovieRating(tx, review.MovieID)
+	})
+}
+
+// AddFlag records a user's report on a review. Repeated reports by the same
+// user are ignored.
+func (r *ReviewRepository) AddFlag(flag *model.ReviewFlag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(flag)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&model.Review{}).Where("id = ?", flag.ReviewID).
+			UpdateColumn("flag_count", gorm.Expr("flag_count + ?", 1)).Error
+	})
+}
+
+// Moderate sets the review's visibility, clears its flags and refreshes the
+// movie's aggregates, which leave hidden reviews out.
+func (r *ReviewRepository) Moderate(review *model.Review, hidden bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockMovie(tx, review.MovieID); err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", review.ID).Delete(&model.ReviewFlag{}).Error; err != nil {
+			return err
+		}
+		review.Hidden = hidden
+		review.FlagCount = 0
+		if err := tx.Model(review).Select("Hidden", "FlagCount", "UpdatedAt").Updates(review).Error; err != nil {
+			return err
+		}
+		return refreshMovieRating(tx, review.MovieID)
+	})
+}
+
+// lockMovie serialises rating changes of one movie so that the aggregates
+// computed by refreshMovieRating always see every committed review.
+func lockMovie(tx *gorm.DB, movieID uint) error {
+	var movie model.Movie
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&movie, movieID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return apperror.NotFound("movie_not_found", "movie not found")
+	}
+	return result.Error
+}
+
+// refreshMovieRating recomputes the movie's average score and review count
+// from its visible reviews.
+func refreshMovieRating(tx *gorm.DB, movieID uint) error {
+	return tx.Model(&model.Movie{}).Where("id = ?", movieID).UpdateColumns(map[string]interface{}{
+		"user_rating":       gorm.Expr("(SELECT COALESCE(AVG(score), 0) FROM reviews WHERE movie_id = ? AND hidden = ?)", movieID, false),
+		"user_rating_count": gorm.Expr("(SELECT COUNT(*) FROM reviews WHERE movie_id = ? AND hidden = ?)", movieID, false),
+		"updated_at":        time.Now(),
+	}).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+func TestReviewRepositoryAggregates(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		movie := createMovie(t, db, model.Movie{Title: "Heat"})
+		alice := createUser(t, db, "alice")
+		bob := createUser(t, db, "bob")
+
+		repo := NewReviewRepository(db)
+		for _, step := range []struct {
+			user        *model.User
+			score       int
+			wantCreated bool
+		}{
+			{alice, 8, true},
+			{bob, 9, true},
+			{alice, 6, false},
+		} {
+			created, err := repo.Upsert(&model.Review{MovieID: movie.ID, UserID: step.user.ID, Score: step.score})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created != step.wantCreated {
+				t.Errorf("Upsert by %s created = %v, want %v", step.user.Username, created, step.wantCreated)
+			}
+		}
+
+		assertRating := func(wantRating float64, wantCount int) {
+			t.Helper()
+			var got model.Movie
+			if err := db.First(&got, movie.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if got.UserRating != wantRating || got.UserRatingCount != wantCount {
+				t.Errorf("rating = %v over %d reviews, want %v over %d", got.UserRating, got.UserRatingCount, wantRating, wantCount)
+			}
+		}
+		assertRating(7.5, 2)
+
+		review, err := repo.FindByMovieAndUser(movie.ID, bob.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.AddFlag(&model.ReviewFlag{ReviewID: review.ID, UserID: alice.ID}); err != nil {
+			t.Fatal(err)
+		}
+		flagged, err := repo.FindFlagged()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(flagged) != 1 || flagged[0].FlagCount != 1 {
+			t.Errorf("FindFlagged = %+v, want bob's review with one flag", flagged)
+		}
+
+		if err := repo.Moderate(review, true); err != nil {
+			t.Fatal(err)
+		}
+		visible, err := repo.FindByMovie(movie.ID, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(visible) != 1 || visible[0].UserID != alice.ID {
+			t.Errorf("FindByMovie without hidden = %+v, want only alice's review", visible)
+		}
+		// Hidden reviews do not count towards the aggregates.
+		assertRating(6, 1)
+
+		if err := repo.Moderate(review, false); err != nil {
+			t.Fatal(err)
+		}
+		assertRating(7.5, 2)
+
+		if err := repo.Delete(review); err != nil {
+			t.Fatal(err)
+		}
+		assertRating(6, 1)
+	})
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"itv/internal/apperror"
+	"itv/internal/model"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+func (r *RoleRepository) FindAll() ([]model.Role, error) {
+	var roles []model.Role
+	result := r.preloaded().Order("name").Find(&roles)
+	return roles, result.Error
+}
+
+func (r *RoleRepository) FindByID(id uint) (*model.Role, error) {
+	return r.find(r.preloaded().Where("id = ?", id))
+}
+
+func (r *RoleRepository) FindByName(name string) (*model.Role, error) {
+	return r.find(r.preloaded().Where("name = ?", name))
+}
+
+// FindPermissions returns the permissions of the role with the given name,
+// which is empty for a role that does not exist.
+func (r *RoleRepository) FindPermissions(name string) ([]string, error) {
+	var permissions []string
+	result := r.db.Model(&model.RolePermission{}).
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ?", name).
+		Order("role_permissions.permission").
+		Pluck("role_permissions.permission", &permissions)
+	return permissions, result.Error
+}
+
+// CountUsers returns the number of users holding the role.
+func (r *RoleRepository) CountUsers(name string) (int64, error) {
+	var count int64
+	result := r.db.Model(&model.User{}).Where("role = ?", name).Count(&count)
+	return count, result.Error
+}
+
+// Create inserts the role with its permissions.
+func (r *RoleRepository) Create(role *model.Role) error {
+	return r.db.Create(role).Error
+}
+
+// Update saves the role and replaces its permissions with role.Permissions.
+func (r *RoleRepository) Update(role *model.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for i := range role.Permissions {
+			role.Permissions[i].RoleID = role.ID
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+}
+
+// Delete removes the role together with its permissions.
+func (r *RoleRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, id).Error
+	})
+}
+
+func (r *RoleRepository) preloaded() *gorm.DB {
+	return r.db.Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("role_permissions.permission")
+	})
+}
+
+func (r *RoleRepository) find(query *gorm.DB) (*model.Role, error) {
+	var role model.Role
+	result := query.First(&role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("role_not_found", "role not found")
+		}
+		return nil, result.Error
+	}
+	return &role, nil
+}
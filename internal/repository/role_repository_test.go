@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+	"itv/pkg/database"
+)
+
+func TestRoleRepositoryBuiltInRoles(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewRoleRepository(db)
+
+		admin, err := repo.FindPermissions(model.RoleAdmin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(admin) != len(model.Permissions) {
+			t.Errorf("admin holds %q, want every permission", admin)
+		}
+		user, err := repo.FindPermissions(model.RoleUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(user) != 0 {
+			t.Errorf("user holds %q, want none", user)
+		}
+
+		// Roles that users hold from before roles were stored are created
+		// when migrating.
+		legacy := createUser(t, db, "mod")
+		if err := db.Model(legacy).Update("role", "moderator").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := database.Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.FindByName("moderator"); err != nil {
+			t.Errorf("FindByName(moderator) after migrating: %v", err)
+		}
+	})
+}
+
+func TestRoleRepositoryUpdateAndDelete(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewRoleRepository(db)
+		role := &model.Role{
+			Name:        "editor",
+			Permissions: []model.RolePermission{{Permission: model.PermissionMoviesWrite}},
+		}
+		if err := repo.Create(role); err != nil {
+			t.Fatal(err)
+		}
+
+		role.Permissions = []model.RolePermission{
+			{Permission: model.PermissionMoviesDelete},
+			{Permission: model.PermissionReviewsModerate},
+		}
+		if err := repo.Update(role); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.FindPermissions("editor")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{model.PermissionMoviesDelete, model.PermissionReviewsModerate}
+		if !slices.Equal(got, want) {
+			t.Errorf("FindPermissions after Update = %q, want %q", got, want)
+		}
+
+		editor := createUser(t, db, "ed")
+		if err := db.Model(editor).Update("role", "editor").Error; err != nil {
+			t.Fatal(err)
+		}
+		if count, err := repo.CountUsers("editor"); err != nil || count != 1 {
+			t.Errorf("CountUsers(editor) = %d, %v, want 1", count, err)
+		}
+
+		if err := repo.Delete(role.ID); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := repo.FindPermissions("editor"); err != nil || len(got) != 0 {
+			t.Errorf("FindPermissions after Delete = %q, %v, want none", got, err)
+		}
+	})
+}
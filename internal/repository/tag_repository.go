@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv/internal/model"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{
+		db: db,
+	}
+}
+
+// FindOrCreate returns a tag for every name, inserting the missing ones.
+// Names are expected to be normalized by the caller.
+func (r *TagRepository) FindOrCreate(names []string) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		newTags := make([]model.Tag, 0, len(names))
+		for _, name := range names {
+			newTags = append(newTags, model.Tag{Name: name})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+			return err
+		}
+		return tx.Where("name IN ?", names).Order("name").Find(&tags).Error
+	})
+	return tags, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/model"
+	"itv/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationRepository struct {
+	db *gorm.DB
+}
+
+func NewTranslationRepository(db *gorm.DB) *TranslationRepository {
+	return &TranslationRepository{
+		db: db,
+	}
+}
+
+// Replica returns the repository reading from a read replica, for reads that
+// tolerate replication lag.
+func (r *TranslationRepository) Replica() *TranslationRepository {
+	return &TranslationRepository{db: database.Replica(r.db)}
+}
+
+func (r *TranslationRepository) FindByMovie(movieID uint) ([]model.MovieTranslation, error) {
+	var translations []model.MovieTranslation
+	result := r.db.Where("movie_id = ?", movieID).Order("locale").Find(&translations)
+	return translations, result.Error
+}
+
+func (r *TranslationRepository) FindByMovies(movieIDs []uint) ([]model.MovieTranslation, error) {
+	var translations []model.MovieTranslation
+	if len(movieIDs) == 0 {
+		return translations, nil
+	}
+	result := r.db.Where("movie_id IN ?", movieIDs).Order("movie_id, locale").Find(&translations)
+	return translations, result.Error
+}
+
+// Save inserts the translation or replaces the title and plot of the existing
+// one for the same movie and locale.
+func (r *TranslationRepository) Save(translation *model.MovieTranslation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "movie_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", "plot", "updated_at"}),
+		}).Create(translation).Error
+		if err != nil {
+			return err
+		}
+		return touchMovies(tx, "id = ?", translation.MovieID)
+	})
+}
+
+func (r *TranslationRepository) Delete(movieID uint, locale string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("movie_id = ? AND locale = ?", movieID, locale).Delete(&model.MovieTranslation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.NotFound("translation_not_found", "translation not found")
+		}
+		return touchMovies(tx, "id = ?", movieID)
+	})
+}
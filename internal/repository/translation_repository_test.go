@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+func TestTranslationRepositorySave(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		movie := createMovie(t, db, model.Movie{Title: "Up"})
+
+		repo := NewTranslationRepository(db)
+		for _, title := range []string{"Oben", "Oben (2009)"} {
+			if err := repo.Save(&model.MovieTranslation{MovieID: movie.ID, Locale: "de", Title: title}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repo.Save(&model.MovieTranslation{MovieID: movie.ID, Locale: "fr", Title: "Là-haut"}); err != nil {
+			t.Fatal(err)
+		}
+
+		translations, err := repo.FindByMovie(movie.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(translations) != 2 || translations[0].Locale != "de" || translations[0].Title != "Oben (2009)" {
+			t.Errorf("FindByMovie = %+v, want de with the latest title and fr", translations)
+		}
+
+		if err := repo.Delete(movie.ID, "de"); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(movie.ID, "de"); err == nil {
+			t.Error("deleting a missing translation succeeded")
+		}
+	})
+}
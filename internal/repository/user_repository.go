@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"gorm.io/gorm"
+	"itv/internal/apperror"
 	"itv/internal/model"
 	"itv/pkg/auth"
 )
@@ -22,20 +23,60 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	result := r.db.Where("username = ?", username).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, apperror.NotFound("user_not_found", "user not found")
 		}
 		return nil, result.Error
 	}
 	return &user, nil
 }
 
+func (r *UserRepository) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	result := r.db.First(&user, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("user_not_found", "user not found")
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// FindByIDs returns the users matching ids. Unknown ids are silently skipped.
+func (r *UserRepository) FindByIDs(ids []uint) ([]model.User, error) {
+	var users []model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	result := r.db.Where("id IN ?", ids).Find(&users)
+	return users, result.Error
+}
+
+// FindAll returns every user in the order they signed up.
+func (r *UserRepository) FindAll() ([]model.User, error) {
+	var users []model.User
+	result := r.db.Order("id").Find(&users)
+	return users, result.Error
+}
+
+// CountByRole returns the number of users holding role.
+func (r *UserRepository) CountByRole(role string) (int64, error) {
+	var count int64
+	result := r.db.Model(&model.User{}).Where("role = ?", role).Count(&count)
+	return count, result.Error
+}
+
 func (r *UserRepository) Create(user *model.User) error {
 	return r.db.Create(user).Error
 }
 
+func (r *UserRepository) Update(user *model.User) error {
+	return r.db.Save(user).Error
+}
+
 func (r *UserRepository) EnsureAdminExists(username, password string) error {
 	var count int64
-	r.db.Model(&model.User{}).Where("role = ?", "admin").Count(&count)
+	r.db.Model(&model.User{}).Where("role = ?", model.RoleAdmin).Count(&count)
 
 	if count == 0 {
 		hashedPassword, err := auth.HashPassword(password)
@@ -46,7 +87,7 @@ func (r *UserRepository) EnsureAdminExists(username, password string) error {
 		admin := model.User{
 			Username: username,
 			Password: hashedPassword,
-			Role:     "admin",
+			Role:     model.RoleAdmin,
 		}
 
 		return r.db.Create(&admin).Error
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/apperror"
+)
+
+func TestUserRepositoryRoles(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		repo := NewUserRepository(db)
+		createUser(t, db, "carol")
+		alice := createUser(t, db, "alice")
+
+		alice.Role = "admin"
+		if err := repo.Update(alice); err != nil {
+			t.Fatal(err)
+		}
+		admins, err := repo.CountByRole("admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if admins != 1 {
+			t.Errorf("CountByRole(admin) = %d, want 1", admins)
+		}
+
+		users, err := repo.FindAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 2 || users[0].Username != "carol" || users[1].Role != "admin" {
+			t.Errorf("FindAll = %+v, want carol then alice as admin", users)
+		}
+
+		if _, err := repo.FindByUsername("bob"); !apperror.IsNotFound(err) {
+			t.Errorf("FindByUsername(bob) error = %v, want not found", err)
+		}
+	})
+}
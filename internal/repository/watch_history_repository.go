@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+type WatchHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewWatchHistoryRepository(db *gorm.DB) *WatchHistoryRepository {
+	return &WatchHistoryRepository{
+		db: db,
+	}
+}
+
+// FindByUser returns the user's viewings of movies that still exist, most
+// recent first.
+func (r *WatchHistoryRepository) FindByUser(userID uint) ([]model.WatchHistory, error) {
+	var history []model.WatchHistory
+	result := r.db.InnerJoins("Movie").
+		Where("watch_histories.user_id = ?", userID).
+		Order("watch_histories.watched_at DESC, watch_histories.id DESC").
+		Find(&history)
+	return history, result.Error
+}
+
+// FindWatchedMovieIDs returns the distinct movies the user has watched.
+func (r *WatchHistoryRepository) FindWatchedMovieIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	result := r.db.Model(&model.WatchHistory{}).
+		Where("user_id = ?", userID).
+		Distinct().
+		Pluck("movie_id", &ids)
+	return ids, result.Error
+}
+
+func (r *WatchHistoryRepository) Create(entry *model.WatchHistory) error {
+	return r.db.Omit("Movie").Create(entry).Error
+}
+
+// Delete removes the user's history entry and reports whether it existed.
+func (r *WatchHistoryRepository) Delete(userID, id uint) (bool, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&model.WatchHistory{}, id)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv/internal/apperror"
+	"itv/internal/model"
+	"time"
+)
+
+const defaultWatchlistName = "Watchlist"
+
+type WatchlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWatchlistRepository(db *gorm.DB) *WatchlistRepository {
+	return &WatchlistRepository{
+		db: db,
+	}
+}
+
+// FindByUser returns the user's watchlists with their item counts, the
+// default list first.
+func (r *WatchlistRepository) FindByUser(userID uint) ([]model.Watchlist, error) {
+	var watchlists []model.Watchlist
+	result := r.withItemCount().
+		Where("user_id = ?", userID).
+		Order("is_default DESC, name").
+		Find(&watchlists)
+	return watchlists, result.Error
+}
+
+// FindByID returns the watchlist only if it belongs to the user.
+func (r *WatchlistRepository) FindByID(userID, id uint) (*model.Watchlist, error) {
+	var watchlist model.Watchlist
+	result := r.withItemCount().Where("user_id = ?", userID).First(&watchlist, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("watchlist_not_found", "watchlist not found")
+		}
+		return nil, result.Error
+	}
+	return &watchlist, nil
+}
+
+// EnsureDefault returns the user's default watchlist, creating it on first use.
+func (r *WatchlistRepository) EnsureDefault(userID uint) (*model.Watchlist, error) {
+	var watchlist model.Watchlist
+	result := r.withItemCount().Where("user_id = ? AND is_default = ?", userID, true).Limit(1).Find(&watchlist)
+	if result.Error != nil || watchlist.ID != 0 {
+		return &watchlist, result.Error
+	}
+
+	// A concurrent request may create it first; the unique name index makes
+	// the insert a no-op then.
+	watchlist = model.Watchlist{UserID: userID, Name: defaultWatchlistName, IsDefault: true}
+	result = r.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Items").Create(&watchlist)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	watchlist = model.Watchlist{}
+	result = r.withItemCount().Where("user_id = ? AND is_default = ?", userID, true).First(&watchlist)
+	return &watchlist, result.Error
+}
+
+// ExistsByName reports whether the user has a list other than excludeID with
+// the given name.
+func (r *WatchlistRepository) ExistsByName(userID uint, name string, excludeID uint) (bool, error) {
+	var count int64
+	result := r.db.Model(&model.Watchlist{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+func (r *WatchlistRepository) Create(watchlist *model.Watchlist) error {
+	return r.db.Omit("Items").Create(watchlist).Error
+}
+
+func (r *WatchlistRepository) Update(watchlist *model.Watchlist) error {
+	return r.db.Omit("Items", "ItemCount").Save(watchlist).Error
+}
+
+// Delete removes the watchlist together with its items.
+func (r *WatchlistRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("watchlist_id = ?", id).Delete(&model.WatchlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Watchlist{}, id).Error
+	})
+}
+
+// FindItems returns the list's items on movies that still exist, in order.
+func (r *WatchlistRepository) FindItems(watchlistID uint) ([]model.WatchlistItem, error) {
+	var items []model.WatchlistItem
+	result := r.db.InnerJoins("Movie").
+		Where("watchlist_items.watchlist_id = ?", watchlistID).
+		Order("watchlist_items.position, watchlist_items.id").
+		Find(&items)
+	return items, result.Error
+}
+
+func (r *WatchlistRepository) HasItem(watchlistID, movieID uint) (bool, error) {
+	var count int64
+	result := r.db.Model(&model.WatchlistItem{}).
+		Where("watchlist_id = ? AND movie_id = ?", watchlistID, movieID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// AddItem appends the movie to the end of the list.
+func (r *WatchlistRepository) AddItem(item *model.WatchlistItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxPosition *int
+		err := tx.Model(&model.WatchlistItem{}).
+			Where("watchlist_id = ?", item.WatchlistID).
+			Select("MAX(position)").
+			Scan(&maxPosition).Error
+		if err != nil {
+			return err
+		}
+
+		item.Position = 0
+		if maxPosition != nil {
+			item.Position = *maxPosition + 1
+		}
+		if err := tx.Omit("Movie").Create(item).Error; err != nil {
+			return err
+		}
+		return touchWatchlist(tx, item.WatchlistID)
+	})
+}
+
+// RemoveItem deletes the movie from the list and reports whether it was there.
+func (r *WatchlistRepository) RemoveItem(watchlistID, movieID uint) (bool, error) {
+	removed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("watchlist_id = ? AND movie_id = ?", watchlistID, movieID).
+			Delete(&model.WatchlistItem{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+		return touchWatchlist(tx, watchlistID)
+	})
+	return removed, err
+}
+
+// Reorder assigns positions following the order of movieIDs.
+func (r *WatchlistRepository) Reorder(watchlistID uint, movieIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for position, movieID := range movieIDs {
+			err := tx.Model(&model.WatchlistItem{}).
+				Where("watchlist_id = ? AND movie_id = ?", watchlistID, movieID).
+				UpdateColumn("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return touchWatchlist(tx, watchlistID)
+	})
+}
+
+// withItemCount selects watchlists with the number of items that FindItems
+// returns, which leaves out deleted movies.
+func (r *WatchlistRepository) withItemCount() *gorm.DB {
+	return r.db.Model(&model.Watchlist{}).Select("watchlists.*, " +
+		"(SELECT COUNT(*) FROM watchlist_items JOIN movies ON movies.id = watchlist_items.movie_id " +
+		"WHERE watchlist_items.watchlist_id = watchlists.id AND movies.deleted_at IS NULL) AS item_count")
+}
+
+func touchWatchlist(tx *gorm.DB, watchlistID uint) error {
+	return tx.Model(&model.Watchlist{}).Where("id = ?", watchlistID).
+		UpdateColumn("updated_at", time.Now()).Error
+}
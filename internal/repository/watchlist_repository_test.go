@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+	"itv/internal/model"
+)
+
+func TestWatchlistRepositoryItems(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		user := createUser(t, db, "alice")
+		heat := createMovie(t, db, model.Movie{Title: "Heat"})
+		up := createMovie(t, db, model.Movie{Title: "Up"})
+		piano := createMovie(t, db, model.Movie{Title: "The Piano"})
+
+		repo := NewWatchlistRepository(db)
+		list, err := repo.EnsureDefault(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := repo.EnsureDefault(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.ID != list.ID {
+			t.Errorf("EnsureDefault created a second default list")
+		}
+
+		for _, movie := range []*model.Movie{heat, up, piano} {
+			if err := repo.AddItem(&model.WatchlistItem{WatchlistID: list.ID, MovieID: movie.ID}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repo.Reorder(list.ID, []uint{piano.ID, heat.ID, up.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if removed, err := repo.RemoveItem(list.ID, heat.ID); err != nil || !removed {
+			t.Fatalf("RemoveItem = %v, %v", removed, err)
+		}
+		if removed, err := repo.RemoveItem(list.ID, heat.ID); err != nil || removed {
+			t.Fatalf("RemoveItem of a missing item = %v, %v", removed, err)
+		}
+
+		items, err := repo.FindItems(list.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		for _, item := range items {
+			titles = append(titles, item.Movie.Title)
+		}
+		if want := []string{"The Piano", "Up"}; !slices.Equal(titles, want) {
+			t.Errorf("FindItems = %q, want %q", titles, want)
+		}
+
+		lists, err := repo.FindByUser(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lists) != 1 || lists[0].ItemCount != 2 {
+			t.Errorf("FindByUser = %+v, want one list with 2 items", lists)
+		}
+
+		exists, err := repo.ExistsByName(user.ID, list.Name, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists {
+			t.Errorf("ExistsByName(%q) = false", list.Name)
+		}
+
+		// Items on deleted movies are neither listed nor counted.
+		if err := db.Delete(up).Error; err != nil {
+			t.Fatal(err)
+		}
+		items, err = repo.FindItems(list.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, err := repo.FindByID(user.ID, list.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || found.ItemCount != 1 {
+			t.Errorf("after deleting a movie, FindItems returned %d items and FindByID counted %d, want 1", len(items), found.ItemCount)
+		}
+	})
+}
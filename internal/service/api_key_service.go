@@ -0,0 +1,167 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyPrefix starts every key so that leaked keys are easy to spot.
+	apiKeyPrefix = "itv_"
+	// apiKeyIDLength is the number of hex digits after apiKeyPrefix that
+	// identify a key, as the prefix stored in plain text.
+	apiKeyIDLength = 12
+	// lastUsedInterval limits how often the last use of a key is written.
+	lastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey = apperror.Unauthorized("invalid_api_key", "invalid, expired or revoked API key")
+	ErrAPIKeyExpiry  = apperror.Validation("invalid_expiry", "expires_at must be in the future")
+)
+
+// APIKeyService issues and checks the API keys that programs use instead of
+// logging in. A key looks like itv_<id>_<secret>, where itv_<id> is stored as
+// its prefix to find it and only the SHA-256 hash of the whole key is kept.
+// Keys carry 256 random bits, so a fast hash is as safe as bcrypt for them
+// and keeps checking a key cheap enough to do on every request.
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (s *APIKeyService) GetAllAPIKeys() ([]dto.APIKeyResponseDTO, error) {
+	keys, err := s.apiKeyRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	keysDTO := make([]dto.APIKeyResponseDTO, 0, len(keys))
+	for _, key := range keys {
+		keysDTO = append(keysDTO, mapAPIKeyToDTO(&key))
+	}
+	return keysDTO, nil
+}
+
+// CreateAPIKey issues a key on behalf of the user with the given ID. The
+// returned key cannot be retrieved again.
+func (s *APIKeyService) CreateAPIKey(userID uint, keyDTO dto.CreateAPIKeyDTO) (*dto.CreatedAPIKeyResponseDTO, error) {
+	if keyDTO.ExpiresAt != nil && !keyDTO.ExpiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyExpiry
+	}
+
+	id := make([]byte, apiKeyIDLength/2)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	prefix := apiKeyPrefix + hex.EncodeToString(id)
+	plain := prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := model.APIKey{
+		Name:        strings.TrimSpace(keyDTO.Name),
+		Prefix:      prefix,
+		Hash:        hashAPIKey(plain),
+		Permissions: mapAPIKeyPermissions(keyDTO.Permissions),
+		CreatedByID: userID,
+		ExpiresAt:   keyDTO.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(&key); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedAPIKeyResponseDTO{
+		APIKeyResponseDTO: mapAPIKeyToDTO(&key),
+		Key:               plain,
+	}, nil
+}
+
+// RevokeAPIKey stops a key from being accepted. Revoking a revoked key does
+// nothing.
+func (s *APIKeyService) RevokeAPIKey(id uint) error {
+	if _, err := s.apiKeyRepo.FindByID(id); err != nil {
+		return err
+	}
+	return s.apiKeyRepo.Revoke(id, time.Now())
+}
+
+// Authenticate returns the key that plain is, if it is active, and records
+// its use at most once per lastUsedInterval.
+func (s *APIKeyService) Authenticate(plain string) (*model.APIKey, error) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(plain, apiKeyPrefix), "_")
+	if !strings.HasPrefix(plain, apiKeyPrefix) || !ok || len(id) != apiKeyIDLength {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.FindByPrefix(apiKeyPrefix + id)
+	if apperror.IsNotFound(err) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(plain)), []byte(key.Hash)) != 1 || !key.Active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		if err := s.apiKeyRepo.Touch(key.ID, now); err != nil {
+			log.Printf("recording the use of API key %s: %v", key.Prefix, err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
+
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// mapAPIKeyPermissions turns permission names into grants, dropping
+// duplicates.
+func mapAPIKeyPermissions(names []string) []model.APIKeyPermission {
+	permissions := make([]model.APIKeyPermission, 0, len(names))
+	for _, name := range names {
+		if !slices.ContainsFunc(permissions, func(p model.APIKeyPermission) bool { return p.Permission == name }) {
+			permissions = append(permissions, model.APIKeyPermission{Permission: name})
+		}
+	}
+	return permissions
+}
+
+func mapAPIKeyToDTO(key *model.APIKey) dto.APIKeyResponseDTO {
+	return dto.APIKeyResponseDTO{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Permissions: key.PermissionNames(),
+		CreatedByID: key.CreatedByID,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		RevokedAt:   key.RevokedAt,
+		CreatedAt:   key.CreatedAt,
+	}
+}
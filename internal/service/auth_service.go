@@ -1,33 +1,52 @@
 package service
 
 import (
-	"errors"
+	"itv/internal/apperror"
 	"itv/internal/dto"
 	"itv/internal/repository"
 	"itv/pkg/auth"
+	"time"
 )
 
+var errInvalidCredentials = apperror.Unauthorized("invalid_credentials", "invalid credentials")
+
 type AuthService struct {
 	userRepo   *repository.UserRepository
 	jwtService *auth.JWTService
+	guard      *LoginGuard
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtService *auth.JWTService) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, jwtService *auth.JWTService, guard *LoginGuard) *AuthService {
 	return &AuthService{
 		userRepo:   userRepo,
 		jwtService: jwtService,
+		guard:      guard,
 	}
 }
 
-func (s *AuthService) Login(loginDTO dto.LoginDTO) (*dto.TokenResponseDTO, error) {
+// Login checks the credentials of a client at clientIP and issues a token.
+// Repeated failures for the same username from the same client are throttled
+// by the LoginGuard before any password is compared.
+func (s *AuthService) Login(loginDTO dto.LoginDTO, clientIP string) (*dto.TokenResponseDTO, error) {
+	if err := s.guard.Begin(clientIP, loginDTO.Username); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByUsername(loginDTO.Username)
+	if apperror.IsNotFound(err) {
+		s.guard.Failed(clientIP, loginDTO.Username)
+		return nil, errInvalidCredentials
+	}
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		s.guard.Release(clientIP, loginDTO.Username)
+		return nil, err
 	}
 
 	if !auth.CheckPasswordHash(loginDTO.Password, user.Password) {
-		return nil, errors.New("invalid credentials")
+		s.guard.Failed(clientIP, loginDTO.Username)
+		return nil, errInvalidCredentials
 	}
+	s.guard.Succeeded(clientIP, loginDTO.Username)
 
 	token, err := s.jwtService.GenerateToken(user)
 	if err != nil {
@@ -37,6 +56,27 @@ func (s *AuthService) Login(loginDTO dto.LoginDTO) (*dto.TokenResponseDTO, error
 	return &dto.TokenResponseDTO{Token: token}, nil
 }
 
+// IssueToken issues a token for the user without checking a password, for
+// operators debugging the API. A zero ttl means JWT_EXPIRY.
+func (s *AuthService) IssueToken(username string, ttl time.Duration) (*dto.TokenResponseDTO, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	var token string
+	if ttl > 0 {
+		token, err = s.jwtService.GenerateTokenWithExpiry(user, ttl)
+	} else {
+		token, err = s.jwtService.GenerateToken(user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponseDTO{Token: token}, nil
+}
+
 func (s *AuthService) EnsureAdminExists(username, password string) error {
 	return s.userRepo.EnsureAdminExists(username, password)
 }
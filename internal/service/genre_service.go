@@ -0,0 +1,128 @@
+package service
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"strings"
+)
+
+var ErrGenreExists = apperror.Conflict("genre_exists", "genre with this name already exists")
+
+type GenreService struct {
+	genreRepo  *repository.GenreRepository
+	movieCache *MovieCache
+}
+
+func NewGenreService(genreRepo *repository.GenreRepository, movieCache *MovieCache) *GenreService {
+	return &GenreService{
+		genreRepo:  genreRepo,
+		movieCache: movieCache,
+	}
+}
+
+func (s *GenreService) GetAllGenres() ([]dto.GenreResponseDTO, error) {
+	genres, err := s.genreRepo.Replica().FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	genresDTO := make([]dto.GenreResponseDTO, 0, len(genres))
+	for _, genre := range genres {
+		genresDTO = append(genresDTO, mapGenreToDTO(&genre))
+	}
+
+	return genresDTO, nil
+}
+
+func (s *GenreService) GetGenreByID(id uint) (*dto.GenreResponseDTO, error) {
+	genre, err := s.genreRepo.Replica().FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	genreDTO := mapGenreToDTO(genre)
+	return &genreDTO, nil
+}
+
+func (s *GenreService) CreateGenre(genreDTO dto.CreateGenreDTO) (*dto.GenreResponseDTO, error) {
+	name := strings.TrimSpace(genreDTO.Name)
+	if err := s.ensureNameAvailable(name, 0); err != nil {
+		return nil, err
+	}
+
+	genre := model.Genre{
+		Name:        name,
+		Description: genreDTO.Description,
+	}
+
+	err := s.genreRepo.Create(&genre)
+	if err != nil {
+		return nil, err
+	}
+
+	responseDTO := mapGenreToDTO(&genre)
+	return &responseDTO, nil
+}
+
+func (s *GenreService) UpdateGenre(id uint, genreDTO dto.UpdateGenreDTO) (*dto.GenreResponseDTO, error) {
+	genre, err := s.genreRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(genreDTO.Name); name != "" {
+		if err := s.ensureNameAvailable(name, genre.ID); err != nil {
+			return nil, err
+		}
+		genre.Name = name
+	}
+	if genreDTO.Description != "" {
+		genre.Description = genreDTO.Description
+	}
+
+	err = s.genreRepo.Update(genre)
+	if err != nil {
+		return nil, err
+	}
+	s.movieCache.InvalidateAll()
+
+	responseDTO := mapGenreToDTO(genre)
+	return &responseDTO, nil
+}
+
+func (s *GenreService) DeleteGenre(id uint) error {
+	_, err := s.genreRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.genreRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	s.movieCache.InvalidateAll()
+	return nil
+}
+
+func (s *GenreService) ensureNameAvailable(name string, excludeID uint) error {
+	exists, err := s.genreRepo.ExistsByName(name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrGenreExists
+	}
+	return nil
+}
+
+func mapGenreToDTO(genre *model.Genre) dto.GenreResponseDTO {
+	return dto.GenreResponseDTO{
+		ID:          genre.ID,
+		Name:        genre.Name,
+		Description: genre.Description,
+		CreatedAt:   genre.CreatedAt,
+		UpdatedAt:   genre.UpdatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"itv/pkg/ratelimit"
+	"log"
+	"strings"
+	"time"
+)
+
+// LoginGuard slows down password guessing against a single account. Every
+// failed login doubles the time the account has to wait before the next
+// attempt, starting at LOGIN_FAILURE_DELAY, and after LOGIN_MAX_FAILURES
+// failures in a row the account is locked for LOGIN_LOCKOUT. Failures are
+// counted per client IP and submitted username, so that one client cannot
+// lock an account for everyone; the per-username rate limit bounds guessing
+// from many addresses. Usernames are tracked whether or not they exist, so
+// the guard does not reveal which are taken.
+type LoginGuard struct {
+	store       ratelimit.Store
+	maxFailures int
+	delay       time.Duration
+	lockout     time.Duration
+}
+
+func NewLoginGuard(store ratelimit.Store, config *config.Config) *LoginGuard {
+	return &LoginGuard{
+		store:       store,
+		maxFailures: config.LoginMaxFailures,
+		delay:       config.LoginFailureDelay,
+		lockout:     config.LoginLockout,
+	}
+}
+
+// Begin returns an error while the account has to wait before another
+// attempt from clientIP. Otherwise the attempt is counted as a failure until
+// Succeeded or Release is called, so that concurrent attempts wait for each
+// other like consecutive failures do.
+func (g *LoginGuard) Begin(clientIP, username string) error {
+	failures, wait := g.store.Attempt(guardKey(clientIP, username), g.lockout, g.wait)
+	if wait <= 0 {
+		return nil
+	}
+	if g.locked(failures) {
+		return apperror.TooManyRequests("account_locked", "too many failed login attempts, the account is temporarily locked", wait)
+	}
+	return apperror.TooManyRequests("login_throttled", "too many failed login attempts, try again later", wait)
+}
+
+// Failed logs when a failed attempt, which Begin already counted, locks the
+// account.
+func (g *LoginGuard) Failed(clientIP, username string) {
+	if failures := g.store.Failures(guardKey(clientIP, username)); g.maxFailures > 0 && failures.Count == g.maxFailures {
+		log.Printf("login for %q from %s locked for %s after %d failed attempts", username, clientIP, g.lockout, failures.Count)
+	}
+}
+
+// Succeeded clears the failures of the account from clientIP.
+func (g *LoginGuard) Succeeded(clientIP, username string) {
+	g.store.Reset(guardKey(clientIP, username))
+}
+
+// Release takes back an attempt that could not be decided, such as one
+// that failed on a database error.
+func (g *LoginGuard) Release(clientIP, username string) {
+	g.store.Release(guardKey(clientIP, username))
+}
+
+// wait returns how long to wait after failures before the next attempt.
+func (g *LoginGuard) wait(failures ratelimit.Failures) time.Duration {
+	if failures.Count == 0 {
+		return 0
+	}
+	if g.locked(failures) {
+		return time.Until(failures.Last.Add(g.lockout))
+	}
+	if g.maxFailures > 0 && failures.Count >= g.maxFailures {
+		return 0
+	}
+	return time.Until(failures.Last.Add(g.backoff(failures.Count)))
+}
+
+// locked reports whether failures lock the account at the moment.
+func (g *LoginGuard) locked(failures ratelimit.Failures) bool {
+	return g.maxFailures > 0 && failures.Count >= g.maxFailures && time.Now().Before(failures.Last.Add(g.lockout))
+}
+
+// backoff is the delay after count failures, capped at the lockout.
+func (g *LoginGuard) backoff(count int) time.Duration {
+	delay := g.delay
+	for i := 1; i < count && delay < g.lockout; i++ {
+		delay *= 2
+	}
+	return min(delay, g.lockout)
+}
+
+func guardKey(clientIP, username string) string {
+	return "login:failures:" + clientIP + ":" + strings.ToLower(strings.TrimSpace(username))
+}
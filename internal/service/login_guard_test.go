@@ -0,0 +1,105 @@
+package service
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"itv/pkg/ratelimit"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLoginGuard(maxFailures int) *LoginGuard {
+	return NewLoginGuard(ratelimit.NewMemoryStore(), &config.Config{
+		LoginMaxFailures:  maxFailures,
+		LoginFailureDelay: time.Minute,
+		LoginLockout:      time.Hour,
+	})
+}
+
+func TestLoginGuardBackoff(t *testing.T) {
+	g := newTestLoginGuard(5)
+	tests := []struct {
+		count int
+		want  time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := g.backoff(tt.count); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestLoginGuardBegin(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxFailures int
+		// failures is the number of failed attempts before the last one.
+		failures int
+		ip       string
+		wantCode string
+	}{
+		{"first attempt", 5, 0, "1.1.1.1", ""},
+		{"after a failure", 5, 1, "1.1.1.1", "login_throttled"},
+		{"locked", 2, 2, "1.1.1.1", "account_locked"},
+		{"locked for another client", 2, 2, "2.2.2.2", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestLoginGuard(tt.maxFailures)
+			for i := 0; i < tt.failures; i++ {
+				// Count failures without waiting out their backoff.
+				g.store.Attempt(guardKey("1.1.1.1", "admin"), g.lockout, func(ratelimit.Failures) time.Duration { return 0 })
+			}
+
+			err := g.Begin(tt.ip, "Admin ")
+			code := ""
+			if problem, ok := err.(*apperror.Error); ok {
+				code = problem.Code
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Begin = %v, want code %q", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+// TestLoginGuardConcurrentAttempts checks that parallel attempts cannot all
+// pass before the first of them fails.
+func TestLoginGuardConcurrentAttempts(t *testing.T) {
+	g := newTestLoginGuard(5)
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if g.Begin("1.1.1.1", "admin") == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := allowed.Load(); n != 1 {
+		t.Errorf("%d parallel attempts were let through, want 1", n)
+	}
+
+	g.Release("1.1.1.1", "admin")
+	if err := g.Begin("1.1.1.1", "admin"); err != nil {
+		t.Errorf("Begin after Release = %v", err)
+	}
+	g.Succeeded("1.1.1.1", "admin")
+	if err := g.Begin("1.1.1.1", "admin"); err != nil {
+		t.Errorf("Begin after Succeeded = %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/storage"
+	"log"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	ErrMediaTooLarge        = apperror.TooLarge("media_too_large", "file exceeds the maximum upload size")
+	ErrUnsupportedMediaType = apperror.UnsupportedMedia("unsupported_media_type", "file must be a JPEG, PNG or WebP image")
+	ErrInvalidImage         = apperror.Validation("invalid_image", "file is not a valid image or is too large to process")
+)
+
+// mediaExtensions lists the accepted content types, detected from the file
+// bytes rather than the client's headers.
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type MediaService struct {
+	mediaRepo  *repository.MediaRepository
+	movieRepo  *repository.MovieRepository
+	store      storage.Storage
+	movieCache *MovieCache
+	maxSize    int64
+}
+
+func NewMediaService(
+	mediaRepo *repository.MediaRepository,
+	movieRepo *repository.MovieRepository,
+	store storage.Storage,
+	movieCache *MovieCache,
+	config *config.Config,
+) *MediaService {
+	return &MediaService{
+		mediaRepo:  mediaRepo,
+		movieRepo:  movieRepo,
+		store:      store,
+		movieCache: movieCache,
+		maxSize:    config.MediaMaxSize,
+	}
+}
+
+// MaxSize returns the largest accepted upload in bytes.
+func (s *MediaService) MaxSize() int64 {
+	return s.maxSize
+}
+
+func (s *MediaService) GetMovieMedia(movieID uint) ([]dto.MediaResponseDTO, error) {
+	_, err := s.movieRepo.Replica().FindByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := s.mediaRepo.Replica().FindByMovie(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaDTO := make([]dto.MediaResponseDTO, 0, len(assets))
+	for _, asset := range assets {
+		mediaDTO = append(mediaDTO, mapMediaToDTO(&asset, s.store))
+	}
+	return mediaDTO, nil
+}
+
+// UploadMedia validates the image, stores it with its thumbnails and records
+// it. A new poster or backdrop replaces the previous one.
+func (s *MediaService) UploadMedia(ctx context.Context, movieID uint, kind string, file io.Reader) (*dto.MediaResponseDTO, error) {
+	_, err := s.movieRepo.FindByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	contentType := mimetype.Detect(data).String()
+	ext, ok := mediaExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || imgConfig.Width*imgConfig.Height > maxImagePixels {
+		return nil, ErrInvalidImage
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	thumbnails, thumbContentType, thumbExt, err := makeThumbnails(img, kind, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("movies/%d/%s/%s", movieID, kind, name)
+
+	asset := model.MediaAsset{
+		MovieID:     movieID,
+		Kind:        kind,
+		Key:         prefix + ext,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       imgConfig.Width,
+		Height:      imgConfig.Height,
+	}
+	for _, thumb := range thumbnails {
+		asset.Variants = append(asset.Variants, model.MediaVariant{
+			Name:   thumb.name,
+			Key:    fmt.Sprintf("%s_%s%s", prefix, thumb.name, thumbExt),
+			Width:  thumb.width,
+			Height: thumb.height,
+		})
+	}
+
+	err = s.store.Put(ctx, asset.Key, bytes.NewReader(data), asset.Size, contentType)
+	if err != nil {
+		return nil, err
+	}
+	for i, thumb := range thumbnails {
+		err = s.store.Put(ctx, asset.Variants[i].Key, bytes.NewReader(thumb.data), int64(len(thumb.data)), thumbContentType)
+		if err != nil {
+			s.deleteFiles(&asset)
+			return nil, err
+		}
+	}
+
+	// Stills add up; a new poster or backdrop replaces the old one.
+	var replaced []model.MediaAsset
+	if kind == model.MediaKindStill {
+		err = s.mediaRepo.Create(&asset)
+	} else {
+		replaced, err = s.mediaRepo.Replace(&asset)
+	}
+	if err != nil {
+		s.deleteFiles(&asset)
+		return nil, err
+	}
+	s.movieCache.InvalidateMovie(movieID)
+
+	for _, old := range replaced {
+		s.deleteFiles(&old)
+	}
+
+	responseDTO := mapMediaToDTO(&asset, s.store)
+	return &responseDTO, nil
+}
+
+func (s *MediaService) DeleteMedia(movieID, id uint) error {
+	asset, err := s.mediaRepo.FindByID(movieID, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.mediaRepo.Delete(asset.ID)
+	if err != nil {
+		return err
+	}
+	s.movieCache.InvalidateMovie(movieID)
+
+	s.deleteFiles(asset)
+	return nil
+}
+
+// deleteFiles removes the stored files of the asset. Failures only leave
+// orphaned files behind, so they are logged rather than returned.
+func (s *MediaService) deleteFiles(asset *model.MediaAsset) {
+	keys := []string{asset.Key}
+	for _, variant := range asset.Variants {
+		keys = append(keys, variant.Key)
+	}
+
+	for _, key := range keys {
+		if err := s.store.Delete(context.Background(), key); err != nil {
+			log.Printf("failed to delete media file %s: %v", key, err)
+		}
+	}
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func mapMediaToDTO(asset *model.MediaAsset, store storage.Storage) dto.MediaResponseDTO {
+	thumbnails := make([]dto.MediaThumbnailDTO, 0, len(asset.Variants))
+	for _, variant := range asset.Variants {
+		thumbnails = append(thumbnails, dto.MediaThumbnailDTO{
+			Name:   variant.Name,
+			URL:    store.URL(variant.Key),
+			Width:  variant.Width,
+			Height: variant.Height,
+		})
+	}
+
+	return dto.MediaResponseDTO{
+		ID:          asset.ID,
+		Kind:        asset.Kind,
+		URL:         store.URL(asset.Key),
+		ContentType: asset.ContentType,
+		Size:        asset.Size,
+		Width:       asset.Width,
+		Height:      asset.Height,
+		Thumbnails:  thumbnails,
+		CreatedAt:   asset.CreatedAt,
+	}
+}
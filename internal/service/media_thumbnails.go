@@ -0,0 +1,80 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"itv/internal/model"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// maxImagePixels rejects decompression bombs before decoding.
+	maxImagePixels   = 40_000_000
+	thumbnailQuality = 85
+)
+
+type thumbnailSize struct {
+	name  string
+	width int
+}
+
+// thumbnailSizes follow common poster, backdrop and still widths. Sizes that
+// are not smaller than the original are skipped.
+var thumbnailSizes = map[string][]thumbnailSize{
+	model.MediaKindPoster:   {{"small", 185}, {"medium", 342}, {"large", 780}},
+	model.MediaKindBackdrop: {{"small", 300}, {"medium", 780}, {"large", 1280}},
+	model.MediaKindStill:    {{"small", 185}, {"medium", 300}, {"large", 780}},
+}
+
+type thumbnail struct {
+	name   string
+	data   []byte
+	width  int
+	height int
+}
+
+// makeThumbnails scales img down to the sizes of the media kind. PNG sources
+// stay PNG to keep transparency; everything else is encoded as JPEG. It
+// returns the thumbnails together with their content type and extension.
+func makeThumbnails(img image.Image, kind, contentType string) ([]thumbnail, string, string, error) {
+	thumbContentType, ext := "image/jpeg", ".jpg"
+	if contentType == "image/png" {
+		thumbContentType, ext = "image/png", ".png"
+	}
+
+	bounds := img.Bounds()
+	var thumbnails []thumbnail
+	for _, size := range thumbnailSizes[kind] {
+		if size.width >= bounds.Dx() {
+			continue
+		}
+
+		height := max(1, bounds.Dy()*size.width/bounds.Dx())
+		dst := image.NewRGBA(image.Rect(0, 0, size.width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+		var buf bytes.Buffer
+		var err error
+		if thumbContentType == "image/png" {
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality})
+		}
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		thumbnails = append(thumbnails, thumbnail{
+			name:   size.name,
+			data:   buf.Bytes(),
+			width:  size.width,
+			height: height,
+		})
+	}
+
+	return thumbnails, thumbContentType, ext, nil
+}
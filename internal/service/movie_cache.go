@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"itv/internal/config"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/cache"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	movieKeyPrefix     = "movies:item:"
+	movieListKeyPrefix = "movies:list:"
+	// similarKeyPrefix holds the similar-movie lists, which any movie may
+	// appear in, so every movie change drops all of them.
+	similarKeyPrefix = "movies:similar:"
+	// recommendationKeyPrefix holds the personal recommendations, which only
+	// the user's own ratings and views drop; other changes wait for the TTL.
+	recommendationKeyPrefix = "movies:recommendations:"
+	// movieGenerationKey holds a token that every catalog invalidation
+	// replaces, so that instances sharing a backend see each other's
+	// invalidations.
+	movieGenerationKey = "movies:generation"
+	// recommendationGenerationKey does the same for recommendations, which
+	// change with every rating and view and so must not hold up catalog
+	// writes.
+	recommendationGenerationKey = "movies:generation:recommendations"
+	// cacheTimeout bounds each cache round trip so that a slow backend
+	// degrades to database reads instead of stalling requests.
+	cacheTimeout = 500 * time.Millisecond
+)
+
+// MovieCache keeps loaded movies, movie listings, similar-movie lists and
+// personal recommendations in a cache.Cache.
+// Concurrent misses for the same key share one database load, and backend
+// failures fall through to the database. Entries are stored as JSON and every
+// caller decodes its own copy, shared loads included, so callers may modify
+// what they get.
+//
+// A load that an invalidation overtakes is not written back, so it cannot
+// resurrect stale data. Invalidations are tracked in the process and, for
+// other instances using the same backend, by a shared token: movies,
+// listings and similar-movie lists follow the catalog generation and
+// recommendations their own. Only an invalidation landing between that check
+// and the write can still let a stale entry in until it expires.
+type MovieCache struct {
+	cache cache.Cache
+	ttl   time.Duration
+	group singleflight.Group
+
+	catalog         generation
+	recommendations generation
+	hits            atomic.Uint64
+	misses          atomic.Uint64
+	errors          atomic.Uint64
+}
+
+// generation tracks the invalidations of one kind of entry.
+type generation struct {
+	// key holds the token shared with other instances.
+	key string
+	// local changes on every invalidation in this process.
+	local atomic.Uint64
+}
+
+func NewMovieCache(cache cache.Cache, config *config.Config) *MovieCache {
+	c := &MovieCache{
+		cache: cache,
+		ttl:   config.CacheTTL,
+	}
+	c.catalog.key = movieGenerationKey
+	c.recommendations.key = recommendationGenerationKey
+	return c
+}
+
+// Movie returns the cached movie with the given ID or loads and caches it.
+func (c *MovieCache) Movie(id uint, load func() (*model.Movie, error)) (*model.Movie, error) {
+	return cached(c, &c.catalog, movieKeyPrefix+strconv.FormatUint(uint64(id), 10), load)
+}
+
+// Movies returns the cached listing for filter or loads and caches it.
+func (c *MovieCache) Movies(filter repository.MovieFilter, load func() ([]model.Movie, error)) ([]model.Movie, error) {
+	key, err := json.Marshal(filter)
+	if err != nil {
+		return load()
+	}
+	sum := sha1.Sum(key)
+	return cached(c, &c.catalog, movieListKeyPrefix+hex.EncodeToString(sum[:]), load)
+}
+
+// Similar returns the cached similar-movie list of the movie with the given
+// ID or loads and caches it.
+func (c *MovieCache) Similar(id uint, load func() ([]dto.RecommendationDTO, error)) ([]dto.RecommendationDTO, error) {
+	return cached(c, &c.catalog, similarKeyPrefix+strconv.FormatUint(uint64(id), 10), load)
+}
+
+// Recommendations returns the cached recommendations of the user or loads
+// and caches them.
+func (c *MovieCache) Recommendations(userID uint, load func() ([]dto.RecommendationDTO, error)) ([]dto.RecommendationDTO, error) {
+	return cached(c, &c.recommendations, recommendationKeyPrefix+strconv.FormatUint(uint64(userID), 10), load)
+}
+
+// InvalidateRecommendations drops the recommendations of the user. Cached
+// movies and listings are left alone.
+func (c *MovieCache) InvalidateRecommendations(userID uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	c.nextGeneration(ctx, &c.recommendations)
+	c.report(c.cache.Delete(ctx, recommendationKeyPrefix+strconv.FormatUint(uint64(userID), 10)))
+}
+
+// InvalidateMovie drops the movie with the given ID, every listing and every
+// similar-movie list. Pass 0 after creating a movie, which has no entry of
+// its own yet.
+func (c *MovieCache) InvalidateMovie(id uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	c.nextGeneration(ctx, &c.catalog)
+	if id != 0 {
+		c.report(c.cache.Delete(ctx, movieKeyPrefix+strconv.FormatUint(uint64(id), 10)))
+	}
+	c.report(c.cache.DeletePrefix(ctx, movieListKeyPrefix))
+	c.report(c.cache.DeletePrefix(ctx, similarKeyPrefix))
+}
+
+// InvalidateAll drops every cached movie, listing and similar-movie list,
+// for changes such as a renamed genre or person that show up in many movies.
+func (c *MovieCache) InvalidateAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	c.nextGeneration(ctx, &c.catalog)
+	c.report(c.cache.DeletePrefix(ctx, movieKeyPrefix))
+	c.report(c.cache.DeletePrefix(ctx, movieListKeyPrefix))
+	c.report(c.cache.DeletePrefix(ctx, similarKeyPrefix))
+}
+
+func (c *MovieCache) Stats() dto.CacheStatsDTO {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	stats := dto.CacheStatsDTO{
+		Hits:   hits,
+		Misses: misses,
+		Errors: c.errors.Load(),
+	}
+	if hits+misses > 0 {
+		stats.HitRatio = float64(hits) / float64(hits+misses)
+	}
+	return stats
+}
+
+func (c *MovieCache) report(err error) {
+	if err != nil {
+		c.errors.Add(1)
+		log.Printf("movie cache: %v", err)
+	}
+}
+
+// nextGeneration marks that the data g covers changed, for loads in this
+// process and in other instances.
+func (c *MovieCache) nextGeneration(ctx context.Context, g *generation) {
+	g.local.Add(1)
+	token, err := randomName()
+	if err != nil {
+		token = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	// Entries written before this outlive it by no more than the TTL.
+	c.report(c.cache.Set(ctx, g.key, []byte(token), c.ttl))
+}
+
+// sharedGeneration returns the token of the latest invalidation of any
+// instance that g covers, empty when there was none within the TTL.
+func (c *MovieCache) sharedGeneration(g *generation) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	token, _, err := c.cache.Get(ctx, g.key)
+	return string(token), err
+}
+
+// cached returns the entry under key or loads it, writing the result back
+// unless an invalidation that g covers overtook the load.
+func cached[T any](c *MovieCache, g *generation, key string, load func() (T, error)) (T, error) {
+	var value T
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	data, ok, err := c.cache.Get(ctx, key)
+	cancel()
+	c.report(err)
+	if ok && json.Unmarshal(data, &value) == nil {
+		c.hits.Add(1)
+		return value, nil
+	}
+	c.misses.Add(1)
+
+	// The load is shared as JSON so that every caller decodes its own copy.
+	result, err, _ := c.group.Do(key, func() (interface{}, error) {
+		local := g.local.Load()
+		shared, sharedErr := c.sharedGeneration(g)
+		loaded, err := load()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, err
+		}
+
+		if sharedErr == nil && g.local.Load() == local {
+			if current, err := c.sharedGeneration(g); err == nil && current == shared {
+				ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+				defer cancel()
+				c.report(c.cache.Set(ctx, key, data, c.ttl))
+			}
+		}
+		return data, nil
+	})
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(result.([]byte), &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
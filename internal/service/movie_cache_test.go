@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"itv/internal/config"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/pkg/cache"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestMovieCache(backend cache.Cache) *MovieCache {
+	return NewMovieCache(backend, &config.Config{CacheTTL: time.Minute})
+}
+
+func TestMovieCacheCopies(t *testing.T) {
+	c := newTestMovieCache(cache.NewMemoryCache(10))
+	release := make(chan struct{})
+	load := func() (*model.Movie, error) {
+		<-release
+		return &model.Movie{ID: 1, Title: "Heat"}, nil
+	}
+
+	// Concurrent misses share one load but not its result.
+	movies := make([]*model.Movie, 4)
+	var wg sync.WaitGroup
+	for i := range movies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			movie, err := c.Movie(1, load)
+			if err != nil {
+				t.Error(err)
+			}
+			movies[i] = movie
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	movies[0].Title = "changed"
+	for i, movie := range movies[1:] {
+		if movie == nil || movie.Title != "Heat" {
+			t.Errorf("caller %d got %+v after another caller changed its copy", i+1, movie)
+		}
+	}
+
+	cached, err := c.Movie(1, func() (*model.Movie, error) { return nil, errors.New("not cached") })
+	if err != nil || cached.Title != "Heat" {
+		t.Errorf("second read = %+v, %v, want the cached movie", cached, err)
+	}
+}
+
+func TestMovieCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(self, other *MovieCache)
+	}{
+		{"same instance", func(self, other *MovieCache) { self.InvalidateMovie(1) }},
+		{"other instance", func(self, other *MovieCache) { other.InvalidateMovie(1) }},
+		{"other instance, everything", func(self, other *MovieCache) { other.InvalidateAll() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := cache.NewMemoryCache(10)
+			self, other := newTestMovieCache(backend), newTestMovieCache(backend)
+
+			// The movie changes while it is being loaded, so what was loaded
+			// must not be cached.
+			_, err := self.Movie(1, func() (*model.Movie, error) {
+				tt.invalidate(self, other)
+				return &model.Movie{ID: 1, Title: "stale"}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			movie, err := self.Movie(1, func() (*model.Movie, error) {
+				return &model.Movie{ID: 1, Title: "fresh"}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if movie.Title != "fresh" {
+				t.Errorf("read after invalidation = %q, want fresh", movie.Title)
+			}
+		})
+	}
+}
+
+func TestMovieCacheRecommendations(t *testing.T) {
+	tests := []struct {
+		name                string
+		invalidate          func(c *MovieCache)
+		wantSimilar, wantMe bool
+	}{
+		{"nothing", func(c *MovieCache) {}, true, true},
+		{"another movie", func(c *MovieCache) { c.InvalidateMovie(2) }, false, true},
+		{"new movie", func(c *MovieCache) { c.InvalidateMovie(0) }, false, true},
+		{"everything", func(c *MovieCache) { c.InvalidateAll() }, false, true},
+		{"the user", func(c *MovieCache) { c.InvalidateRecommendations(1) }, true, false},
+		{"another user", func(c *MovieCache) { c.InvalidateRecommendations(2) }, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestMovieCache(cache.NewMemoryCache(10))
+			list := func(title string) func() ([]dto.RecommendationDTO, error) {
+				return func() ([]dto.RecommendationDTO, error) {
+					return []dto.RecommendationDTO{{Movie: dto.MovieSummaryDTO{Title: title}}}, nil
+				}
+			}
+			if _, err := c.Similar(1, list("old")); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Recommendations(1, list("old")); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.invalidate(c)
+
+			similar, err := c.Similar(1, list("new"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kept := similar[0].Movie.Title == "old"; kept != tt.wantSimilar {
+				t.Errorf("similar list kept = %t, want %t", kept, tt.wantSimilar)
+			}
+			mine, err := c.Recommendations(1, list("new"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kept := mine[0].Movie.Title == "old"; kept != tt.wantMe {
+				t.Errorf("recommendations kept = %t, want %t", kept, tt.wantMe)
+			}
+		})
+	}
+}
+
+func TestMovieCacheGenerations(t *testing.T) {
+	tests := []struct {
+		name                string
+		invalidate          func(self, other *MovieCache)
+		wantMovie, wantMine bool
+	}{
+		{"recommendations", func(self, other *MovieCache) { self.InvalidateRecommendations(2) }, true, false},
+		{"recommendations elsewhere", func(self, other *MovieCache) { other.InvalidateRecommendations(2) }, true, false},
+		{"catalog", func(self, other *MovieCache) { self.InvalidateMovie(2) }, false, true},
+		{"catalog elsewhere", func(self, other *MovieCache) { other.InvalidateAll() }, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := cache.NewMemoryCache(10)
+			self, other := newTestMovieCache(backend), newTestMovieCache(backend)
+
+			// Each load is overtaken by the invalidation, which must only
+			// keep the loads it covers out of the cache.
+			_, err := self.Movie(1, func() (*model.Movie, error) {
+				tt.invalidate(self, other)
+				return &model.Movie{ID: 1, Title: "loaded"}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = self.Recommendations(1, func() ([]dto.RecommendationDTO, error) {
+				tt.invalidate(self, other)
+				return []dto.RecommendationDTO{{Movie: dto.MovieSummaryDTO{Title: "loaded"}}}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			movie, err := self.Movie(1, func() (*model.Movie, error) {
+				return &model.Movie{ID: 1, Title: "reloaded"}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cached := movie.Title == "loaded"; cached != tt.wantMovie {
+				t.Errorf("movie cached = %t, want %t", cached, tt.wantMovie)
+			}
+			mine, err := self.Recommendations(1, func() ([]dto.RecommendationDTO, error) {
+				return []dto.RecommendationDTO{{Movie: dto.MovieSummaryDTO{Title: "reloaded"}}}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cached := mine[0].Movie.Title == "loaded"; cached != tt.wantMine {
+				t.Errorf("recommendations cached = %t, want %t", cached, tt.wantMine)
+			}
+		})
+	}
+}
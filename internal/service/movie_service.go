@@ -2,65 +2,155 @@ package service
 
 import (
 	"gorm.io/gorm"
+	"itv/internal/apperror"
 	"itv/internal/dto"
 	"itv/internal/model"
 	"itv/internal/repository"
+	"itv/pkg/locale"
+	"itv/pkg/storage"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownGenre         = apperror.Validation("unknown_genre", "one or more genres do not exist")
+	ErrUnknownPerson        = apperror.Validation("unknown_person", "one or more people do not exist")
+	ErrReleaseYearMismatch  = apperror.Validation("release_year_mismatch", "release_date must fall in the movie's year")
+	ErrDuplicateRelease     = apperror.Validation("duplicate_release", "releases must not repeat a country and type")
+	ErrInvalidReleaseWindow = apperror.Validation("invalid_release_window", "released_from must not be after released_to")
 )
 
 type MovieService struct {
-	movieRepo *repository.MovieRepository
-	db        *gorm.DB
+	movieRepo  *repository.MovieRepository
+	genreRepo  *repository.GenreRepository
+	tagRepo    *repository.TagRepository
+	personRepo *repository.PersonRepository
+	cache      *MovieCache
+	store      storage.Storage
+	db         *gorm.DB
 }
 
-func NewMovieService(movieRepo *repository.MovieRepository, db *gorm.DB) *MovieService {
+func NewMovieService(
+	movieRepo *repository.MovieRepository,
+	genreRepo *repository.GenreRepository,
+	tagRepo *repository.TagRepository,
+	personRepo *repository.PersonRepository,
+	cache *MovieCache,
+	store storage.Storage,
+	db *gorm.DB,
+) *MovieService {
 	return &MovieService{
-		movieRepo: movieRepo,
-		db:        db,
+		movieRepo:  movieRepo,
+		genreRepo:  genreRepo,
+		tagRepo:    tagRepo,
+		personRepo: personRepo,
+		cache:      cache,
+		store:      store,
+		db:         db,
 	}
 }
 
-func (s *MovieService) GetAllMovies() ([]dto.MovieResponseDTO, error) {
-	movies, err := s.movieRepo.FindAll()
+// GetAllMovies lists the movies matching the filter with their titles and
+// plots in the language that prefs favour.
+func (s *MovieService) GetAllMovies(filterDTO dto.MovieFilterDTO, prefs locale.Preferences) ([]dto.MovieResponseDTO, error) {
+	filter, err := mapFilter(filterDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	movies, err := s.cache.Movies(filter, func() ([]model.Movie, error) {
+		return s.movieRepo.Replica().FindAll(filter)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	var moviesDTO []dto.MovieResponseDTO
 	for _, movie := range movies {
-		moviesDTO = append(moviesDTO, mapMovieToDTO(&movie))
+		moviesDTO = append(moviesDTO, mapMovieToDTO(&movie, s.store, prefs))
 	}
 
 	return moviesDTO, nil
 }
 
-func (s *MovieService) GetMovieByID(id uint) (*dto.MovieResponseDTO, error) {
-	movie, err := s.movieRepo.FindByID(id)
+// GetMoviesVersion returns the number of movies matching the filter and when
+// the latest of them changed, for conditional requests on GetAllMovies.
+func (s *MovieService) GetMoviesVersion(filterDTO dto.MovieFilterDTO) (int64, time.Time, error) {
+	filter, err := mapFilter(filterDTO)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return s.movieRepo.Replica().Version(filter)
+}
+
+func (s *MovieService) GetMovieByID(id uint, prefs locale.Preferences) (*dto.MovieResponseDTO, error) {
+	movie, err := s.cache.Movie(id, func() (*model.Movie, error) {
+		return s.movieRepo.Replica().FindByID(id)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	movieDTO := mapMovieToDTO(movie)
+	movieDTO := mapMovieToDTO(movie, s.store, prefs)
 	return &movieDTO, nil
 }
 
 func (s *MovieService) CreateMovie(movieDTO dto.CreateMovieDTO) (*dto.MovieResponseDTO, error) {
+	genres, err := s.resolveGenres(movieDTO.GenreIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagRepo.FindOrCreate(normalizeNames(movieDTO.Tags))
+	if err != nil {
+		return nil, err
+	}
+
+	credits, err := s.resolveCredits(movieDTO.Credits)
+	if err != nil {
+		return nil, err
+	}
+	credits, err = s.withDirector(credits, movieDTO.Director, false)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseDate, err := parseReleaseDate(movieDTO.ReleaseDate, movieDTO.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := mapReleases(movieDTO.Releases)
+	if err != nil {
+		return nil, err
+	}
+
+	originalLanguage, err := normalizeLanguage(movieDTO.OriginalLanguage)
+	if err != nil {
+		return nil, err
+	}
+
 	movie := model.Movie{
-		Title:       movieDTO.Title,
-		Director:    movieDTO.Director,
-		Year:        movieDTO.Year,
-		Plot:        movieDTO.Plot,
-		Rating:      movieDTO.Rating,
-		Duration:    movieDTO.Duration,
-		ReleaseDate: movieDTO.ReleaseDate,
+		Title:            movieDTO.Title,
+		Year:             movieDTO.Year,
+		Plot:             movieDTO.Plot,
+		OriginalLanguage: originalLanguage,
+		Rating:           movieDTO.Rating,
+		Duration:         movieDTO.Duration,
+		ReleaseDate:      releaseDate,
+		Genres:           genres,
+		Tags:             tags,
+		Credits:          credits,
+		Releases:         releases,
 	}
 
-	err := s.movieRepo.Create(&movie)
+	err = s.movieRepo.Create(&movie)
 	if err != nil {
 		return nil, err
 	}
+	s.cache.InvalidateMovie(0)
 
-	responseDTO := mapMovieToDTO(&movie)
-	return &responseDTO, nil
+	return s.writtenMovie(movie.ID)
 }
 
 func (s *MovieService) UpdateMovie(id uint, movieDTO dto.UpdateMovieDTO) (*dto.MovieResponseDTO, error) {
@@ -72,15 +162,18 @@ func (s *MovieService) UpdateMovie(id uint, movieDTO dto.UpdateMovieDTO) (*dto.M
 	if movieDTO.Title != "" {
 		movie.Title = movieDTO.Title
 	}
-	if movieDTO.Director != "" {
-		movie.Director = movieDTO.Director
-	}
 	if movieDTO.Year != 0 {
 		movie.Year = movieDTO.Year
 	}
 	if movieDTO.Plot != "" {
 		movie.Plot = movieDTO.Plot
 	}
+	if movieDTO.OriginalLanguage != "" {
+		movie.OriginalLanguage, err = normalizeLanguage(movieDTO.OriginalLanguage)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if movieDTO.Rating != 0 {
 		movie.Rating = movieDTO.Rating
 	}
@@ -88,16 +181,52 @@ func (s *MovieService) UpdateMovie(id uint, movieDTO dto.UpdateMovieDTO) (*dto.M
 		movie.Duration = movieDTO.Duration
 	}
 	if movieDTO.ReleaseDate != "" {
-		movie.ReleaseDate = movieDTO.ReleaseDate
+		movie.ReleaseDate, err = parseReleaseDate(movieDTO.ReleaseDate, movie.Year)
+		if err != nil {
+			return nil, err
+		}
+	} else if movie.ReleaseDate != nil && movie.ReleaseDate.Year() != movie.Year {
+		return nil, ErrReleaseYearMismatch
+	}
+	// A nil list leaves the links untouched, an empty list clears them.
+	if movieDTO.GenreIDs != nil {
+		movie.Genres, err = s.resolveGenres(movieDTO.GenreIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if movieDTO.Tags != nil {
+		movie.Tags, err = s.tagRepo.FindOrCreate(normalizeNames(movieDTO.Tags))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if movieDTO.Credits != nil {
+		movie.Credits, err = s.resolveCredits(movieDTO.Credits)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if movieDTO.Director != "" {
+		movie.Credits, err = s.withDirector(movie.Credits, movieDTO.Director, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if movieDTO.Releases != nil {
+		movie.Releases, err = mapReleases(movieDTO.Releases)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = s.movieRepo.Update(movie)
 	if err != nil {
 		return nil, err
 	}
+	s.cache.InvalidateMovie(movie.ID)
 
-	responseDTO := mapMovieToDTO(movie)
-	return &responseDTO, nil
+	return s.writtenMovie(movie.ID)
 }
 
 func (s *MovieService) DeleteMovie(id uint) error {
@@ -106,34 +235,329 @@ func (s *MovieService) DeleteMovie(id uint) error {
 		return err
 	}
 
-	return s.movieRepo.Delete(id)
+	err = s.movieRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	s.cache.InvalidateMovie(id)
+	return nil
 }
 
-func (s *MovieService) SearchMovies(query string) ([]dto.MovieResponseDTO, error) {
-	movies, err := s.movieRepo.SearchMovies(query)
+// writtenMovie returns a movie as just written. It is read from the primary,
+// bypassing the cache, since the replicas may not have the change yet.
+func (s *MovieService) writtenMovie(id uint) (*dto.MovieResponseDTO, error) {
+	movie, err := s.movieRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	movieDTO := mapMovieToDTO(movie, s.store, nil)
+	return &movieDTO, nil
+}
+
+func (s *MovieService) SearchMovies(query string, filterDTO dto.MovieFilterDTO, prefs locale.Preferences) ([]dto.MovieResponseDTO, error) {
+	filter, err := mapFilter(filterDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	movies, err := s.movieRepo.Replica().SearchMovies(query, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	var moviesDTO []dto.MovieResponseDTO
 	for _, movie := range movies {
-		moviesDTO = append(moviesDTO, mapMovieToDTO(&movie))
+		moviesDTO = append(moviesDTO, mapMovieToDTO(&movie, s.store, prefs))
 	}
 
 	return moviesDTO, nil
 }
 
-func mapMovieToDTO(movie *model.Movie) dto.MovieResponseDTO {
+// SearchMoviesVersion is GetMoviesVersion for SearchMovies.
+func (s *MovieService) SearchMoviesVersion(query string, filterDTO dto.MovieFilterDTO) (int64, time.Time, error) {
+	filter, err := mapFilter(filterDTO)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return s.movieRepo.Replica().SearchVersion(query, filter)
+}
+
+func (s *MovieService) resolveGenres(ids []uint) ([]model.Genre, error) {
+	ids = uniqueIDs(ids)
+	genres, err := s.genreRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(genres) != len(ids) {
+		return nil, ErrUnknownGenre
+	}
+	return genres, nil
+}
+
+func (s *MovieService) resolveCredits(creditsDTO []dto.CreditDTO) ([]model.MovieCredit, error) {
+	ids := make([]uint, 0, len(creditsDTO))
+	for _, creditDTO := range creditsDTO {
+		ids = append(ids, creditDTO.PersonID)
+	}
+	ids = uniqueIDs(ids)
+
+	people, err := s.personRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(people) != len(ids) {
+		return nil, ErrUnknownPerson
+	}
+
+	credits := make([]model.MovieCredit, 0, len(creditsDTO))
+	for _, creditDTO := range creditsDTO {
+		credits = append(credits, model.MovieCredit{
+			PersonID:  creditDTO.PersonID,
+			Role:      creditDTO.Role,
+			Character: creditDTO.Character,
+			Position:  creditDTO.Position,
+		})
+	}
+	return credits, nil
+}
+
+// withDirector adds a director credit for the named person. When replace is
+// set any existing director credits are dropped first; otherwise the name is
+// ignored if credits already contain a director.
+func (s *MovieService) withDirector(credits []model.MovieCredit, name string, replace bool) ([]model.MovieCredit, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return credits, nil
+	}
+
+	result := make([]model.MovieCredit, 0, len(credits)+1)
+	for _, credit := range credits {
+		if credit.Role == model.CreditRoleDirector {
+			if !replace {
+				return credits, nil
+			}
+			continue
+		}
+		result = append(result, credit)
+	}
+
+	person, err := s.personRepo.FindOrCreateByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	director := model.MovieCredit{PersonID: person.ID, Role: model.CreditRoleDirector}
+	return append([]model.MovieCredit{director}, result...), nil
+}
+
+// parseReleaseDate parses an ISO 8601 date and checks that it falls in year.
+func parseReleaseDate(value string, year int) (*time.Time, error) {
+	date, err := time.Parse(dto.DateLayout, value)
+	if err != nil {
+		return nil, err
+	}
+	if date.Year() != year {
+		return nil, ErrReleaseYearMismatch
+	}
+	return &date, nil
+}
+
+// normalizeLanguage returns the canonical form of an optional BCP 47 tag.
+func normalizeLanguage(tag string) (string, error) {
+	if tag == "" {
+		return "", nil
+	}
+	normalized, err := locale.Normalize(tag)
+	if err != nil {
+		return "", apperror.InvalidField("original_language", "bcp47_language_tag", "")
+	}
+	return normalized, nil
+}
+
+func mapReleases(releasesDTO []dto.ReleaseDTO) ([]model.MovieRelease, error) {
+	releases := make([]model.MovieRelease, 0, len(releasesDTO))
+	seen := make(map[string]bool, len(releasesDTO))
+	for _, releaseDTO := range releasesDTO {
+		key := releaseDTO.Country + "/" + releaseDTO.Type
+		if seen[key] {
+			return nil, ErrDuplicateRelease
+		}
+		seen[key] = true
+
+		date, err := time.Parse(dto.DateLayout, releaseDTO.ReleaseDate)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, model.MovieRelease{
+			Country:     releaseDTO.Country,
+			Type:        releaseDTO.Type,
+			ReleaseDate: date,
+		})
+	}
+	return releases, nil
+}
+
+func mapFilter(filterDTO dto.MovieFilterDTO) (repository.MovieFilter, error) {
+	filter := repository.MovieFilter{
+		Genres:   normalizeNames(splitValues(filterDTO.Genres)),
+		Tags:     normalizeNames(splitValues(filterDTO.Tags)),
+		MatchAll: filterDTO.Match == "all",
+		Country:  filterDTO.Country,
+	}
+
+	if filterDTO.ReleasedFrom != "" {
+		from, err := time.Parse(dto.DateLayout, filterDTO.ReleasedFrom)
+		if err != nil {
+			return filter, err
+		}
+		filter.ReleasedFrom = &from
+	}
+	if filterDTO.ReleasedTo != "" {
+		to, err := time.Parse(dto.DateLayout, filterDTO.ReleasedTo)
+		if err != nil {
+			return filter, err
+		}
+		filter.ReleasedTo = &to
+	}
+	if filter.ReleasedFrom != nil && filter.ReleasedTo != nil && filter.ReleasedFrom.After(*filter.ReleasedTo) {
+		return filter, ErrInvalidReleaseWindow
+	}
+
+	return filter, nil
+}
+
+// splitValues expands comma-separated query values.
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		result = append(result, strings.Split(value, ",")...)
+	}
+	return result
+}
+
+// normalizeNames trims, lowercases and de-duplicates names, dropping blanks.
+func normalizeNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
+// mapMovieToDTO shows the title and plot in the translation that prefs
+// favour over the original language, if any.
+func mapMovieToDTO(movie *model.Movie, store storage.Storage, prefs locale.Preferences) dto.MovieResponseDTO {
+	genres := make([]dto.GenreResponseDTO, 0, len(movie.Genres))
+	for _, genre := range movie.Genres {
+		genres = append(genres, mapGenreToDTO(&genre))
+	}
+
+	tags := make([]string, 0, len(movie.Tags))
+	for _, tag := range movie.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	var directors []string
+	credits := make([]dto.CreditResponseDTO, 0, len(movie.Credits))
+	for _, credit := range movie.Credits {
+		if credit.Role == model.CreditRoleDirector {
+			directors = append(directors, credit.Person.Name)
+		}
+		credits = append(credits, dto.CreditResponseDTO{
+			PersonID:  credit.PersonID,
+			Name:      credit.Person.Name,
+			Role:      credit.Role,
+			Character: credit.Character,
+			Position:  credit.Position,
+		})
+	}
+
+	var poster, backdrop *dto.MediaResponseDTO
+	stills := make([]dto.MediaResponseDTO, 0, len(movie.Media))
+	for _, asset := range movie.Media {
+		mediaDTO := mapMediaToDTO(&asset, store)
+		switch asset.Kind {
+		case model.MediaKindPoster:
+			poster = &mediaDTO
+		case model.MediaKindBackdrop:
+			backdrop = &mediaDTO
+		default:
+			stills = append(stills, mediaDTO)
+		}
+	}
+
+	releases := make([]dto.ReleaseResponseDTO, 0, len(movie.Releases))
+	for _, release := range movie.Releases {
+		releases = append(releases, dto.ReleaseResponseDTO{
+			Country:     release.Country,
+			Type:        release.Type,
+			ReleaseDate: release.ReleaseDate.Format(dto.DateLayout),
+		})
+	}
+
+	var releaseDate string
+	if movie.ReleaseDate != nil {
+		releaseDate = movie.ReleaseDate.Format(dto.DateLayout)
+	}
+
+	title, plot, language, originalTitle := movie.Title, movie.Plot, movie.OriginalLanguage, ""
+	if translation := chooseTranslation(prefs, movie.OriginalLanguage, movie.Translations); translation != nil {
+		title, language, originalTitle = translation.Title, translation.Locale, movie.Title
+		if translation.Plot != "" {
+			plot = translation.Plot
+		}
+	}
+
 	return dto.MovieResponseDTO{
-		ID:          movie.ID,
-		Title:       movie.Title,
-		Director:    movie.Director,
-		Year:        movie.Year,
-		Plot:        movie.Plot,
-		Rating:      movie.Rating,
-		Duration:    movie.Duration,
-		ReleaseDate: movie.ReleaseDate,
-		CreatedAt:   movie.CreatedAt,
-		UpdatedAt:   movie.UpdatedAt,
+		ID:               movie.ID,
+		Title:            title,
+		OriginalTitle:    originalTitle,
+		Language:         language,
+		OriginalLanguage: movie.OriginalLanguage,
+		Director:         strings.Join(directors, ", "),
+		Year:             movie.Year,
+		Plot:             plot,
+		Rating:           movie.Rating,
+		UserRating:       movie.UserRating,
+		UserRatingCount:  movie.UserRatingCount,
+		Duration:         movie.Duration,
+		ReleaseDate:      releaseDate,
+		Genres:           genres,
+		Tags:             tags,
+		Credits:          credits,
+		Releases:         releases,
+		Poster:           poster,
+		Backdrop:         backdrop,
+		Stills:           stills,
+		CreatedAt:        movie.CreatedAt,
+		UpdatedAt:        movie.UpdatedAt,
+	}
+}
+
+func mapMovieToSummaryDTO(movie *model.Movie) dto.MovieSummaryDTO {
+	return dto.MovieSummaryDTO{
+		ID:    movie.ID,
+		Title: movie.Title,
+		Year:  movie.Year,
 	}
 }
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/locale"
+	"strings"
+)
+
+type PersonService struct {
+	personRepo   *repository.PersonRepository
+	translations *TranslationService
+	movieCache   *MovieCache
+}
+
+func NewPersonService(personRepo *repository.PersonRepository, translations *TranslationService, movieCache *MovieCache) *PersonService {
+	return &PersonService{
+		personRepo:   personRepo,
+		translations: translations,
+		movieCache:   movieCache,
+	}
+}
+
+func (s *PersonService) GetAllPeople(query string) ([]dto.PersonResponseDTO, error) {
+	people, err := s.personRepo.Replica().FindAll(strings.TrimSpace(query))
+	if err != nil {
+		return nil, err
+	}
+
+	peopleDTO := make([]dto.PersonResponseDTO, 0, len(people))
+	for _, person := range people {
+		peopleDTO = append(peopleDTO, mapPersonToDTO(&person))
+	}
+
+	return peopleDTO, nil
+}
+
+func (s *PersonService) GetPersonByID(id uint) (*dto.PersonResponseDTO, error) {
+	person, err := s.personRepo.Replica().FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	personDTO := mapPersonToDTO(person)
+	return &personDTO, nil
+}
+
+// GetFilmography lists the person's credits with the movie titles in the
+// language that prefs favour.
+func (s *PersonService) GetFilmography(id uint, prefs locale.Preferences) (*dto.FilmographyResponseDTO, error) {
+	personRepo := s.personRepo.Replica()
+	person, err := personRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	credits, err := personRepo.FindCredits(id)
+	if err != nil {
+		return nil, err
+	}
+
+	movieIDs := make([]uint, 0, len(credits))
+	for _, credit := range credits {
+		movieIDs = append(movieIDs, credit.MovieID)
+	}
+	titles, err := s.translations.TranslatedTitles(prefs, uniqueIDs(movieIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]dto.FilmographyEntryDTO, 0, len(credits))
+	for _, credit := range credits {
+		title, ok := titles[credit.MovieID]
+		if !ok {
+			title = credit.Movie.Title
+		}
+		entries = append(entries, dto.FilmographyEntryDTO{
+			MovieID:   credit.MovieID,
+			Title:     title,
+			Year:      credit.Movie.Year,
+			Role:      credit.Role,
+			Character: credit.Character,
+		})
+	}
+
+	return &dto.FilmographyResponseDTO{
+		Person:  mapPersonToDTO(person),
+		Credits: entries,
+	}, nil
+}
+
+func (s *PersonService) CreatePerson(personDTO dto.CreatePersonDTO) (*dto.PersonResponseDTO, error) {
+	person := model.Person{
+		Name: strings.TrimSpace(personDTO.Name),
+		Bio:  personDTO.Bio,
+	}
+
+	err := s.personRepo.Create(&person)
+	if err != nil {
+		return nil, err
+	}
+
+	responseDTO := mapPersonToDTO(&person)
+	return &responseDTO, nil
+}
+
+func (s *PersonService) UpdatePerson(id uint, personDTO dto.UpdatePersonDTO) (*dto.PersonResponseDTO, error) {
+	person, err := s.personRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(personDTO.Name); name != "" {
+		person.Name = name
+	}
+	if personDTO.Bio != "" {
+		person.Bio = personDTO.Bio
+	}
+
+	err = s.personRepo.Update(person)
+	if err != nil {
+		return nil, err
+	}
+	s.movieCache.InvalidateAll()
+
+	responseDTO := mapPersonToDTO(person)
+	return &responseDTO, nil
+}
+
+func (s *PersonService) DeletePerson(id uint) error {
+	_, err := s.personRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.personRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	s.movieCache.InvalidateAll()
+	return nil
+}
+
+func mapPersonToDTO(person *model.Person) dto.PersonResponseDTO {
+	return dto.PersonResponseDTO{
+		ID:        person.ID,
+		Name:      person.Name,
+		Bio:       person.Bio,
+		CreatedAt: person.CreatedAt,
+		UpdatedAt: person.UpdatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"itv/internal/dto"
+	"itv/internal/repository"
+	"itv/pkg/ratelimit"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type RateLimitService struct {
+	store    ratelimit.Store
+	userRepo *repository.UserRepository
+}
+
+func NewRateLimitService(store ratelimit.Store, userRepo *repository.UserRepository) *RateLimitService {
+	return &RateLimitService{
+		store:    store,
+		userRepo: userRepo,
+	}
+}
+
+// GetUsage groups the API rate limit buckets that are in use by user or
+// client IP, users first. It reads the keys that RateLimitMiddleware.Limit
+// writes.
+func (s *RateLimitService) GetUsage() ([]dto.RateLimitUsageDTO, error) {
+	usage := make(map[string]*dto.RateLimitUsageDTO)
+	var userIDs []uint
+	for _, bucket := range s.store.Buckets("api:") {
+		parts := strings.SplitN(bucket.Key, ":", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		group, kind, subject := parts[1], parts[2], parts[3]
+
+		entry, ok := usage[kind+":"+subject]
+		if !ok {
+			entry = &dto.RateLimitUsageDTO{Buckets: []dto.RateLimitBucketDTO{}}
+			switch kind {
+			case "user":
+				id, err := strconv.ParseUint(subject, 10, 64)
+				if err != nil {
+					continue
+				}
+				entry.UserID = uint(id)
+				userIDs = append(userIDs, entry.UserID)
+			case "ip":
+				entry.IP = subject
+			default:
+				continue
+			}
+			usage[kind+":"+subject] = entry
+		}
+		entry.Buckets = append(entry.Buckets, dto.RateLimitBucketDTO{
+			Group:     group,
+			Limit:     bucket.Limit,
+			Remaining: bucket.Remaining,
+			Reset:     int(math.Ceil(bucket.Reset.Seconds())),
+		})
+	}
+
+	users, err := s.userRepo.FindByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[uint]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	usageDTO := make([]dto.RateLimitUsageDTO, 0, len(usage))
+	for _, entry := range usage {
+		entry.Username = usernames[entry.UserID]
+		sort.Slice(entry.Buckets, func(i, j int) bool {
+			return entry.Buckets[i].Group < entry.Buckets[j].Group
+		})
+		usageDTO = append(usageDTO, *entry)
+	}
+	sort.Slice(usageDTO, func(i, j int) bool {
+		a, b := usageDTO[i], usageDTO[j]
+		if (a.UserID == 0) != (b.UserID == 0) {
+			return a.UserID != 0
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.IP < b.IP
+	})
+	return usageDTO, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"itv/internal/dto"
+	"itv/internal/model"
+	"math"
+	"sort"
+)
+
+// Weights of the content signals shared by two movies.
+const (
+	genreWeight    = 3.0
+	tagWeight      = 1.0
+	directorWeight = 4.0
+	castWeight     = 1.5
+	writerWeight   = 1.0
+)
+
+// Shares of the normalized signals in the final score. Popularity only breaks
+// ties between otherwise similar candidates.
+const (
+	contentShare       = 0.6
+	collaborativeShare = 0.4
+	popularityShare    = 0.05
+)
+
+const (
+	// likedScore is the lowest review score that counts as liking a movie.
+	likedScore = 7
+	// watchedWeight is the profile weight of a movie watched but not rated.
+	watchedWeight = 0.5
+	// maxReasons caps the reasons reported per recommendation.
+	maxReasons = 3
+)
+
+// movieFeatures indexes the content of a movie for similarity lookups.
+type movieFeatures struct {
+	movie     *model.Movie
+	genres    map[uint]string
+	tags      map[uint]string
+	directors map[uint]string
+	writers   map[uint]string
+	cast      map[uint]string
+}
+
+func newMovieFeatures(movie *model.Movie) *movieFeatures {
+	features := &movieFeatures{
+		movie:     movie,
+		genres:    make(map[uint]string, len(movie.Genres)),
+		tags:      make(map[uint]string, len(movie.Tags)),
+		directors: make(map[uint]string),
+		writers:   make(map[uint]string),
+		cast:      make(map[uint]string),
+	}
+	for _, genre := range movie.Genres {
+		features.genres[genre.ID] = genre.Name
+	}
+	for _, tag := range movie.Tags {
+		features.tags[tag.ID] = tag.Name
+	}
+	for _, credit := range movie.Credits {
+		switch credit.Role {
+		case model.CreditRoleDirector:
+			features.directors[credit.PersonID] = credit.Person.Name
+		case model.CreditRoleWriter:
+			features.writers[credit.PersonID] = credit.Person.Name
+		case model.CreditRoleActor:
+			features.cast[credit.PersonID] = credit.Person.Name
+		}
+	}
+	return features
+}
+
+// candidate accumulates the signals for one recommended movie.
+type candidate struct {
+	movie         *model.Movie
+	content       float64
+	collaborative float64
+	reasons       map[string]float64
+}
+
+type candidateSet map[uint]*candidate
+
+func (cs candidateSet) get(movie *model.Movie) *candidate {
+	c, ok := cs[movie.ID]
+	if !ok {
+		c = &candidate{movie: movie, reasons: make(map[string]float64)}
+		cs[movie.ID] = c
+	}
+	return c
+}
+
+// addContent scores how much b resembles a, scaled by weight. Reasons are
+// only recorded for positive contributions so that disliked movies lower the
+// score without explaining it.
+func (c *candidate) addContent(a, b *movieFeatures, weight float64) {
+	add := func(shared map[uint]string, other map[uint]string, signal float64, label string) {
+		for id, name := range shared {
+			if _, ok := other[id]; !ok {
+				continue
+			}
+			c.content += weight * signal
+			if weight > 0 {
+				c.reasons[label+": "+name] += weight * signal
+			}
+		}
+	}
+	add(a.genres, b.genres, genreWeight, "Genre")
+	add(a.tags, b.tags, tagWeight, "Tag")
+	add(a.directors, b.directors, directorWeight, "Director")
+	add(a.writers, b.writers, writerWeight, "Writer")
+	add(a.cast, b.cast, castWeight, "Cast")
+}
+
+func (c *candidate) addCollaborative(weight float64) {
+	c.collaborative += weight
+	c.reasons["Liked by viewers with similar taste"] += weight
+}
+
+// popularity is a Bayesian average of the user ratings on a 0-1 scale, which
+// keeps movies with a handful of votes close to the neutral prior.
+func popularity(movie *model.Movie) float64 {
+	const priorVotes, priorMean = 5.0, 6.0
+	votes := float64(movie.UserRatingCount)
+	return (movie.UserRating*votes + priorMean*priorVotes) / (votes + priorVotes) / 10
+}
+
+// rank normalizes the content and collaborative signals to 0-1, combines
+// them, and returns the best positive candidates.
+func (cs candidateSet) rank(limit int) []dto.RecommendationDTO {
+	var maxContent, maxCollaborative float64
+	for _, c := range cs {
+		maxContent = math.Max(maxContent, c.content)
+		maxCollaborative = math.Max(maxCollaborative, c.collaborative)
+	}
+
+	type scored struct {
+		candidate *candidate
+		score     float64
+	}
+	results := make([]scored, 0, len(cs))
+	for _, c := range cs {
+		var score float64
+		if maxContent > 0 {
+			score += contentShare * c.content / maxContent
+		}
+		if maxCollaborative > 0 {
+			score += collaborativeShare * c.collaborative / maxCollaborative
+		}
+		if score <= 0 {
+			continue
+		}
+		score += popularityShare * popularity(c.movie)
+		results = append(results, scored{candidate: c, score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].candidate.movie.ID < results[j].candidate.movie.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	recommendations := make([]dto.RecommendationDTO, 0, len(results))
+	for _, result := range results {
+		recommendations = append(recommendations, dto.RecommendationDTO{
+			Movie:   mapMovieToSummaryDTO(result.candidate.movie),
+			Score:   math.Round(result.score*1000) / 1000,
+			Reasons: topReasons(result.candidate.reasons),
+		})
+	}
+	return recommendations
+}
+
+// rankByPopularity lists movies by popularity alone.
+func rankByPopularity(movies []model.Movie, limit int) []dto.RecommendationDTO {
+	popular := make([]*model.Movie, 0, len(movies))
+	for i := range movies {
+		popular = append(popular, &movies[i])
+	}
+
+	sort.SliceStable(popular, func(i, j int) bool {
+		return popularity(popular[i]) > popularity(popular[j])
+	})
+	if len(popular) > limit {
+		popular = popular[:limit]
+	}
+
+	recommendations := make([]dto.RecommendationDTO, 0, len(popular))
+	for _, movie := range popular {
+		recommendations = append(recommendations, dto.RecommendationDTO{
+			Movie:   mapMovieToSummaryDTO(movie),
+			Score:   math.Round(popularity(movie)*1000) / 1000,
+			Reasons: []string{"Popular with viewers"},
+		})
+	}
+	return recommendations
+}
+
+func topReasons(reasons map[string]float64) []string {
+	labels := make([]string, 0, len(reasons))
+	for label, weight := range reasons {
+		if weight > 0 {
+			labels = append(labels, label)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if reasons[labels[i]] != reasons[labels[j]] {
+			return reasons[labels[i]] > reasons[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	if len(labels) > maxReasons {
+		labels = labels[:maxReasons]
+	}
+	return labels
+}
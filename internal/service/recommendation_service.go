@@ -0,0 +1,288 @@
+package service
+
+import (
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/locale"
+	"sort"
+)
+
+const (
+	// MaxRecommendations bounds both the limit parameter and the cached list.
+	MaxRecommendations = 100
+	// recommendationCandidates bounds the movies scored per list, both those
+	// sharing features with the seed movies and those liked by neighbours.
+	recommendationCandidates = 500
+)
+
+// RecommendationService scores movies in-process from genres, tags, shared
+// people and co-rated movies. Only the movies related to the seed movies and
+// the ones most liked by similar users are loaded and scored. The lists are
+// kept in the MovieCache: personal ones until the user rates or watches
+// something, similar-movie ones until any movie changes.
+type RecommendationService struct {
+	movieRepo    *repository.MovieRepository
+	reviewRepo   *repository.ReviewRepository
+	historyRepo  *repository.WatchHistoryRepository
+	translations *TranslationService
+	movieCache   *MovieCache
+}
+
+func NewRecommendationService(
+	movieRepo *repository.MovieRepository,
+	reviewRepo *repository.ReviewRepository,
+	historyRepo *repository.WatchHistoryRepository,
+	translations *TranslationService,
+	movieCache *MovieCache,
+) *RecommendationService {
+	return &RecommendationService{
+		movieRepo:    movieRepo,
+		reviewRepo:   reviewRepo,
+		historyRepo:  historyRepo,
+		translations: translations,
+		movieCache:   movieCache,
+	}
+}
+
+// GetRecommendations returns up to limit movies the user has neither watched
+// nor rated, best match first, titled in the language that prefs favour.
+func (s *RecommendationService) GetRecommendations(userID uint, limit int, prefs locale.Preferences) ([]dto.RecommendationDTO, error) {
+	items, err := s.movieCache.Recommendations(userID, func() ([]dto.RecommendationDTO, error) {
+		return s.computeRecommendations(userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.localize(truncateRecommendations(items, limit), prefs)
+}
+
+// GetSimilarMovies returns up to limit movies resembling the given one in
+// content or in who liked it.
+func (s *RecommendationService) GetSimilarMovies(movieID uint, limit int, prefs locale.Preferences) ([]dto.RecommendationDTO, error) {
+	items, err := s.movieCache.Similar(movieID, func() ([]dto.RecommendationDTO, error) {
+		return s.computeSimilarMovies(movieID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.localize(truncateRecommendations(items, limit), prefs)
+}
+
+// InvalidateUser drops the cached recommendations of the user.
+func (s *RecommendationService) InvalidateUser(userID uint) {
+	s.movieCache.InvalidateRecommendations(userID)
+}
+
+func (s *RecommendationService) computeSimilarMovies(movieID uint) ([]dto.RecommendationDTO, error) {
+	target, err := s.movieRepo.FindByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	fans, err := s.reviewRepo.FindLikesOfMovies([]uint{target.ID}, likedScore, 0)
+	if err != nil {
+		return nil, err
+	}
+	fanIDs := make([]uint, 0, len(fans))
+	for _, review := range fans {
+		fanIDs = append(fanIDs, review.UserID)
+	}
+	likes, err := s.reviewRepo.FindLikesByUsers(fanIDs, likedScore)
+	if err != nil {
+		return nil, err
+	}
+	collaborative := make(map[uint]float64)
+	for _, review := range likes {
+		if review.MovieID != target.ID {
+			collaborative[review.MovieID]++
+		}
+	}
+
+	movies, err := s.findCandidates([]uint{target.ID}, collaborative)
+	if err != nil {
+		return nil, err
+	}
+
+	targetFeatures := newMovieFeatures(target)
+	candidates := make(candidateSet)
+	for i := range movies {
+		c := candidates.get(&movies[i])
+		c.addContent(targetFeatures, newMovieFeatures(&movies[i]), 1)
+		if weight := collaborative[movies[i].ID]; weight > 0 {
+			c.addCollaborative(weight)
+		}
+	}
+	return candidates.rank(MaxRecommendations), nil
+}
+
+func (s *RecommendationService) computeRecommendations(userID uint) ([]dto.RecommendationDTO, error) {
+	reviews, err := s.reviewRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	watched, err := s.historyRepo.FindWatchedMovieIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ratings map 1..10 onto -1..1 so that disliked movies push similar
+	// ones down; watched but unrated movies count as a mild like.
+	profile := make(map[uint]float64, len(reviews)+len(watched))
+	for _, movieID := range watched {
+		profile[movieID] = watchedWeight
+	}
+	var liked []uint
+	for _, review := range reviews {
+		profile[review.MovieID] = (float64(review.Score) - 5.5) / 4.5
+		if review.Score >= likedScore {
+			liked = append(liked, review.MovieID)
+		}
+	}
+	seenIDs := make([]uint, 0, len(profile))
+	for movieID := range profile {
+		seenIDs = append(seenIDs, movieID)
+	}
+
+	if len(profile) == 0 {
+		return s.popularMovies(seenIDs)
+	}
+
+	// Neighbours are users who liked the same movies; each shared like makes
+	// their other likes count more.
+	coLikes, err := s.reviewRepo.FindLikesOfMovies(liked, likedScore, userID)
+	if err != nil {
+		return nil, err
+	}
+	neighbours := make(map[uint]float64)
+	for _, review := range coLikes {
+		neighbours[review.UserID]++
+	}
+	neighbourIDs := make([]uint, 0, len(neighbours))
+	for neighbourID := range neighbours {
+		neighbourIDs = append(neighbourIDs, neighbourID)
+	}
+	likes, err := s.reviewRepo.FindLikesByUsers(neighbourIDs, likedScore)
+	if err != nil {
+		return nil, err
+	}
+	collaborative := make(map[uint]float64)
+	for _, review := range likes {
+		if _, seen := profile[review.MovieID]; !seen {
+			collaborative[review.MovieID] += neighbours[review.UserID]
+		}
+	}
+
+	seen, err := s.movieRepo.FindFeatures(seenIDs)
+	if err != nil {
+		return nil, err
+	}
+	seenFeatures := make(map[uint]*movieFeatures, len(seen))
+	for i := range seen {
+		seenFeatures[seen[i].ID] = newMovieFeatures(&seen[i])
+	}
+
+	movies, err := s.findCandidates(seenIDs, collaborative)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(candidateSet)
+	for i := range movies {
+		c := candidates.get(&movies[i])
+		features := newMovieFeatures(&movies[i])
+		for movieID, weight := range profile {
+			if seen, ok := seenFeatures[movieID]; ok {
+				c.addContent(seen, features, weight)
+			}
+		}
+		if weight := collaborative[movies[i].ID]; weight > 0 {
+			c.addCollaborative(weight)
+		}
+	}
+
+	recommendations := candidates.rank(MaxRecommendations)
+	if len(recommendations) == 0 {
+		return s.popularMovies(seenIDs)
+	}
+	return recommendations, nil
+}
+
+// findCandidates loads the movies related to seeds together with the ones
+// weighing most in collaborative, which must not contain seeds.
+func (s *RecommendationService) findCandidates(seeds []uint, collaborative map[uint]float64) ([]model.Movie, error) {
+	movies, err := s.movieRepo.FindRelated(seeds, recommendationCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[uint]bool, len(movies))
+	for _, movie := range movies {
+		loaded[movie.ID] = true
+	}
+	var missing []uint
+	for _, movieID := range heaviest(collaborative, recommendationCandidates) {
+		if !loaded[movieID] {
+			missing = append(missing, movieID)
+		}
+	}
+	liked, err := s.movieRepo.FindFeatures(missing)
+	if err != nil {
+		return nil, err
+	}
+	return append(movies, liked...), nil
+}
+
+// popularMovies is the cold-start fallback for users without any signal.
+func (s *RecommendationService) popularMovies(seenIDs []uint) ([]dto.RecommendationDTO, error) {
+	movies, err := s.movieRepo.FindPopular(seenIDs, MaxRecommendations)
+	if err != nil {
+		return nil, err
+	}
+	return rankByPopularity(movies, MaxRecommendations), nil
+}
+
+// localize translates the movie titles of items, which are the caller's own
+// copy of the cached list.
+func (s *RecommendationService) localize(items []dto.RecommendationDTO, prefs locale.Preferences) ([]dto.RecommendationDTO, error) {
+	if len(prefs) == 0 {
+		return items, nil
+	}
+
+	summaries := make([]*dto.MovieSummaryDTO, 0, len(items))
+	for i := range items {
+		summaries = append(summaries, &items[i].Movie)
+	}
+
+	err := s.translations.localizeSummaries(prefs, summaries)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func truncateRecommendations(items []dto.RecommendationDTO, limit int) []dto.RecommendationDTO {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+// heaviest returns up to limit keys of weights, the largest weights first.
+func heaviest(weights map[uint]float64, limit int) []uint {
+	ids := make([]uint, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if weights[ids[i]] != weights[ids[j]] {
+			return weights[ids[i]] > weights[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids
+}
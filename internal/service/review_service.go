@@ -0,0 +1,161 @@
+package service
+
+import (
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+)
+
+type ReviewService struct {
+	reviewRepo *repository.ReviewRepository
+	movieRepo  *repository.MovieRepository
+	recommends *RecommendationService
+	movieCache *MovieCache
+}
+
+func NewReviewService(
+	reviewRepo *repository.ReviewRepository,
+	movieRepo *repository.MovieRepository,
+	recommends *RecommendationService,
+	movieCache *MovieCache,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo: reviewRepo,
+		movieRepo:  movieRepo,
+		recommends: recommends,
+		movieCache: movieCache,
+	}
+}
+
+func (s *ReviewService) GetMovieReviews(movieID uint, includeHidden bool) ([]dto.ReviewResponseDTO, error) {
+	_, err := s.movieRepo.Replica().FindByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.reviewRepo.Replica().FindByMovie(movieID, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapReviewsToDTO(reviews), nil
+}
+
+func (s *ReviewService) GetFlaggedReviews() ([]dto.ReviewResponseDTO, error) {
+	reviews, err := s.reviewRepo.FindFlagged()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapReviewsToDTO(reviews), nil
+}
+
+func (s *ReviewService) GetUserReview(movieID, userID uint) (*dto.ReviewResponseDTO, error) {
+	review, err := s.reviewRepo.FindByMovieAndUser(movieID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewDTO := mapReviewToDTO(review)
+	return &reviewDTO, nil
+}
+
+// UpsertReview creates or replaces the user's review of the movie and reports
+// whether it was newly created.
+func (s *ReviewService) UpsertReview(movieID, userID uint, reviewDTO dto.UpsertReviewDTO) (*dto.ReviewResponseDTO, bool, error) {
+	review := model.Review{
+		MovieID: movieID,
+		UserID:  userID,
+		Score:   reviewDTO.Score,
+		Text:    reviewDTO.Text,
+	}
+
+	created, err := s.reviewRepo.Upsert(&review)
+	if err != nil {
+		return nil, false, err
+	}
+	s.recommends.InvalidateUser(userID)
+	s.movieCache.InvalidateMovie(movieID)
+
+	responseDTO, err := s.GetUserReview(movieID, userID)
+	return responseDTO, created, err
+}
+
+func (s *ReviewService) DeleteUserReview(movieID, userID uint) error {
+	review, err := s.reviewRepo.FindByMovieAndUser(movieID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.delete(review)
+}
+
+func (s *ReviewService) DeleteReview(id uint) error {
+	review, err := s.reviewRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.delete(review)
+}
+
+func (s *ReviewService) delete(review *model.Review) error {
+	if err := s.reviewRepo.Delete(review); err != nil {
+		return err
+	}
+	s.recommends.InvalidateUser(review.UserID)
+	s.movieCache.InvalidateMovie(review.MovieID)
+	return nil
+}
+
+func (s *ReviewService) FlagReview(id, userID uint, flagDTO dto.FlagReviewDTO) error {
+	_, err := s.reviewRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.reviewRepo.AddFlag(&model.ReviewFlag{
+		ReviewID: id,
+		UserID:   userID,
+		Reason:   flagDTO.Reason,
+	})
+}
+
+func (s *ReviewService) ModerateReview(id uint, moderateDTO dto.ModerateReviewDTO) (*dto.ReviewResponseDTO, error) {
+	review, err := s.reviewRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.reviewRepo.Moderate(review, *moderateDTO.Hidden)
+	if err != nil {
+		return nil, err
+	}
+	s.movieCache.InvalidateMovie(review.MovieID)
+
+	reviewDTO := mapReviewToDTO(review)
+	return &reviewDTO, nil
+}
+
+func mapReviewsToDTO(reviews []model.Review) []dto.ReviewResponseDTO {
+	reviewsDTO := make([]dto.ReviewResponseDTO, 0, len(reviews))
+	for _, review := range reviews {
+		reviewsDTO = append(reviewsDTO, mapReviewToDTO(&review))
+	}
+	return reviewsDTO
+}
+
+func mapReviewToDTO(review *model.Review) dto.ReviewResponseDTO {
+	return dto.ReviewResponseDTO{
+		ID:        review.ID,
+		MovieID:   review.MovieID,
+		UserID:    review.UserID,
+		Username:  review.User.Username,
+		Score:     review.Score,
+		Text:      review.Text,
+		Hidden:    review.Hidden,
+		FlagCount: review.FlagCount,
+		CreatedAt: review.CreatedAt,
+		UpdatedAt: review.UpdatedAt,
+	}
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/cache"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rolePermissionsKeyPrefix = "roles:permissions:"
+	userRoleKeyPrefix        = "roles:user:"
+)
+
+var (
+	ErrRoleExists  = apperror.Conflict("role_exists", "role with this name already exists")
+	ErrRoleBuiltIn = apperror.Conflict("built_in_role", "built-in roles cannot be deleted and admin cannot be changed")
+	ErrRoleInUse   = apperror.Conflict("role_in_use", "role is assigned to users")
+
+	ErrAdminOnly         = apperror.Forbidden("admin_only", "only admins can assign or remove the admin role")
+	ErrPermissionNotHeld = apperror.Forbidden("permission_not_held", "you cannot grant permissions you do not hold")
+)
+
+// Grantor is whoever changes a role or assigns one: a user, by role, or an
+// API key, by its permissions. Only an admin may hand out permissions it does
+// not hold itself or touch the admin role.
+type Grantor struct {
+	// Role is the role of the user; it is empty for an API key.
+	Role string
+	// Permissions are those of the API key.
+	Permissions []string
+}
+
+// RoleService manages roles and answers permission checks. The role of each
+// user and the permissions of each role are cached for CACHE_TTL and dropped
+// when they change, so that checking them does not cost queries per request.
+type RoleService struct {
+	roleRepo *repository.RoleRepository
+	userRepo *repository.UserRepository
+	cache    cache.Cache
+	ttl      time.Duration
+}
+
+func NewRoleService(roleRepo *repository.RoleRepository, userRepo *repository.UserRepository, cache cache.Cache, config *config.Config) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		cache:    cache,
+		ttl:      config.CacheTTL,
+	}
+}
+
+// UserRole returns the current role of the user, so that a new role applies
+// from the next request rather than from the next login.
+func (s *RoleService) UserRole(userID uint) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+
+	key := userRoleKeyPrefix + strconv.FormatUint(uint64(userID), 10)
+	if data, ok, err := s.cache.Get(ctx, key); err == nil && ok {
+		return string(data), nil
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	_ = s.cache.Set(ctx, key, []byte(user.Role), s.ttl)
+	return user.Role, nil
+}
+
+// InvalidateUserRole drops the cached role of the user after it changed.
+func (s *RoleService) InvalidateUserRole(userID uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	_ = s.cache.Delete(ctx, userRoleKeyPrefix+strconv.FormatUint(uint64(userID), 10))
+}
+
+// CheckGrant returns ErrPermissionNotHeld unless grantor holds every one of
+// permissions.
+func (s *RoleService) CheckGrant(grantor Grantor, permissions []string) error {
+	if grantor.Role == model.RoleAdmin {
+		return nil
+	}
+	held := grantor.Permissions
+	if grantor.Role != "" {
+		var err error
+		if held, err = s.permissions(grantor.Role); err != nil {
+			return err
+		}
+	}
+	for _, permission := range permissions {
+		if !slices.Contains(held, permission) {
+			return ErrPermissionNotHeld
+		}
+	}
+	return nil
+}
+
+// CheckAssign returns an error unless grantor may give the role to a user or
+// take it from one, which takes holding every permission of the role, or
+// being an admin for the admin role itself.
+func (s *RoleService) CheckAssign(grantor Grantor, role string) error {
+	if grantor.Role == model.RoleAdmin {
+		return nil
+	}
+	if role == model.RoleAdmin {
+		return ErrAdminOnly
+	}
+	permissions, err := s.permissions(role)
+	if err != nil {
+		return err
+	}
+	return s.CheckGrant(grantor, permissions)
+}
+
+// HasPermission reports whether the role grants permission. Admin holds
+// every permission whatever is stored for it.
+func (s *RoleService) HasPermission(role, permission string) (bool, error) {
+	if role == model.RoleAdmin {
+		return true, nil
+	}
+	permissions, err := s.permissions(role)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(permissions, permission), nil
+}
+
+func (s *RoleService) GetAllRoles() ([]dto.RoleResponseDTO, error) {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rolesDTO := make([]dto.RoleResponseDTO, 0, len(roles))
+	for _, role := range roles {
+		rolesDTO = append(rolesDTO, mapRoleToDTO(&role))
+	}
+	return rolesDTO, nil
+}
+
+func (s *RoleService) GetRoleByID(id uint) (*dto.RoleResponseDTO, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	roleDTO := mapRoleToDTO(role)
+	return &roleDTO, nil
+}
+
+// CreateRole adds a role with permissions that grantor holds. Names are
+// stored in lowercase.
+func (s *RoleService) CreateRole(grantor Grantor, roleDTO dto.CreateRoleDTO) (*dto.RoleResponseDTO, error) {
+	if err := s.CheckGrant(grantor, roleDTO.Permissions); err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(roleDTO.Name))
+	_, err := s.roleRepo.FindByName(name)
+	if err == nil {
+		return nil, ErrRoleExists
+	}
+	if !apperror.IsNotFound(err) {
+		return nil, err
+	}
+
+	role := model.Role{
+		Name:        name,
+		Description: roleDTO.Description,
+		Permissions: mapPermissions(roleDTO.Permissions),
+	}
+	if err := s.roleRepo.Create(&role); err != nil {
+		return nil, err
+	}
+	s.invalidate(name)
+
+	responseDTO := mapRoleToDTO(&role)
+	return &responseDTO, nil
+}
+
+// UpdateRole replaces the description and permissions of a role other than
+// admin with permissions that grantor holds.
+func (s *RoleService) UpdateRole(grantor Grantor, id uint, roleDTO dto.UpdateRoleDTO) (*dto.RoleResponseDTO, error) {
+	if err := s.CheckGrant(grantor, roleDTO.Permissions); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == model.RoleAdmin {
+		return nil, ErrRoleBuiltIn
+	}
+
+	role.Description = roleDTO.Description
+	role.Permissions = mapPermissions(roleDTO.Permissions)
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+	s.invalidate(role.Name)
+
+	responseDTO := mapRoleToDTO(role)
+	return &responseDTO, nil
+}
+
+// DeleteRole removes a role that is neither built in nor held by a user.
+func (s *RoleService) DeleteRole(id uint) error {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn() {
+		return ErrRoleBuiltIn
+	}
+
+	users, err := s.roleRepo.CountUsers(role.Name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate(role.Name)
+	return nil
+}
+
+// permissions returns the cached permissions of the role or loads and caches
+// them. Cache failures fall through to the database.
+func (s *RoleService) permissions(role string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+
+	key := rolePermissionsKeyPrefix + role
+	if data, ok, err := s.cache.Get(ctx, key); err == nil && ok {
+		var permissions []string
+		if json.Unmarshal(data, &permissions) == nil {
+			return permissions, nil
+		}
+	}
+
+	permissions, err := s.roleRepo.FindPermissions(role)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(permissions); err == nil {
+		_ = s.cache.Set(ctx, key, data, s.ttl)
+	}
+	return permissions, nil
+}
+
+func (s *RoleService) invalidate(role string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	_ = s.cache.Delete(ctx, rolePermissionsKeyPrefix+role)
+}
+
+// mapPermissions turns permission names into grants, dropping duplicates.
+func mapPermissions(names []string) []model.RolePermission {
+	permissions := make([]model.RolePermission, 0, len(names))
+	for _, name := range names {
+		if !slices.ContainsFunc(permissions, func(p model.RolePermission) bool { return p.Permission == name }) {
+			permissions = append(permissions, model.RolePermission{Permission: name})
+		}
+	}
+	return permissions
+}
+
+func mapRoleToDTO(role *model.Role) dto.RoleResponseDTO {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Permission)
+	}
+	slices.Sort(permissions)
+
+	return dto.RoleResponseDTO{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		BuiltIn:     role.BuiltIn(),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
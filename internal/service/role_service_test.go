@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/cache"
+	"itv/pkg/database"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestRoleServices returns the role and user services on an in-memory
+// database with the built-in roles, a manager role holding users:manage and
+// movies:write, an editor and a moderator role, and a user holding each role
+// named after it plus a second admin.
+func newTestRoleServices(t *testing.T) (*RoleService, *UserService, map[string]uint) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	roleRepo, userRepo := repository.NewRoleRepository(db), repository.NewUserRepository(db)
+	roleService := NewRoleService(roleRepo, userRepo, cache.NewMemoryCache(100), &config.Config{CacheTTL: time.Minute})
+	userService := NewUserService(userRepo, roleRepo, roleService)
+
+	roles := map[string][]string{
+		"manager":   {model.PermissionUsersManage, model.PermissionMoviesWrite},
+		"editor":    {model.PermissionMoviesWrite},
+		"moderator": {model.PermissionReviewsModerate},
+	}
+	for name, permissions := range roles {
+		if _, err := roleService.CreateRole(operator, dto.CreateRoleDTO{Name: name, Permissions: permissions}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	users := make(map[string]uint)
+	for _, name := range []string{"admin", "user", "manager", "editor", "moderator", "second admin"} {
+		role := name
+		if name == "second admin" {
+			role = model.RoleAdmin
+		}
+		user := &model.User{Username: name, Password: "hash", Role: role}
+		if err := userRepo.Create(user); err != nil {
+			t.Fatal(err)
+		}
+		users[name] = user.ID
+	}
+	return roleService, userService, users
+}
+
+func problemCode(t *testing.T, err error) string {
+	t.Helper()
+	if problem, ok := err.(*apperror.Error); ok {
+		return problem.Code
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return ""
+}
+
+func TestUserServiceSetRoleByID(t *testing.T) {
+	manager := Grantor{Role: "manager"}
+	tests := []struct {
+		name     string
+		grantor  Grantor
+		user     string
+		role     string
+		wantCode string
+	}{
+		{"role the grantor holds", manager, "user", "editor", ""},
+		{"role with a permission the grantor lacks", manager, "user", "moderator", "permission_not_held"},
+		{"making an admin", manager, "user", model.RoleAdmin, "admin_only"},
+		{"making oneself an admin", manager, "manager", model.RoleAdmin, "admin_only"},
+		{"demoting an admin", manager, "admin", model.RoleUser, "admin_only"},
+		{"demoting a user with more permissions", manager, "moderator", model.RoleUser, "permission_not_held"},
+		{"API key", Grantor{Permissions: []string{model.PermissionUsersManage, model.PermissionMoviesWrite}}, "user", "editor", ""},
+		{"API key making an admin", Grantor{Permissions: model.Permissions}, "user", model.RoleAdmin, "admin_only"},
+		{"admin", Grantor{Role: model.RoleAdmin}, "second admin", "moderator", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roleService, userService, users := newTestRoleServices(t)
+			before, err := roleService.UserRole(users[tt.user])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = userService.SetRoleByID(tt.grantor, users[tt.user], dto.UpdateUserRoleDTO{Role: tt.role})
+			if code := problemCode(t, err); code != tt.wantCode {
+				t.Fatalf("SetRoleByID = %v, want code %q", err, tt.wantCode)
+			}
+
+			want := before
+			if tt.wantCode == "" {
+				want = tt.role
+			}
+			if got, err := roleService.UserRole(users[tt.user]); err != nil || got != want {
+				t.Errorf("UserRole after SetRoleByID = %q, %v, want %q", got, err, want)
+			}
+		})
+	}
+}
+
+func TestRoleServiceGrants(t *testing.T) {
+	roleService, _, _ := newTestRoleServices(t)
+	manager := Grantor{Role: "manager"}
+	tests := []struct {
+		name        string
+		grantor     Grantor
+		permissions []string
+		wantCode    string
+	}{
+		{"permissions the grantor holds", manager, []string{model.PermissionMoviesWrite}, ""},
+		{"a permission the grantor lacks", manager, []string{model.PermissionMoviesWrite, model.PermissionMoviesDelete}, "permission_not_held"},
+		{"API key", Grantor{Permissions: []string{model.PermissionUsersManage}}, []string{model.PermissionMoviesWrite}, "permission_not_held"},
+		{"admin", Grantor{Role: model.RoleAdmin}, model.Permissions, ""},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := fmt.Sprintf("role%d", i)
+			_, err := roleService.CreateRole(tt.grantor, dto.CreateRoleDTO{Name: name, Permissions: tt.permissions})
+			if code := problemCode(t, err); code != tt.wantCode {
+				t.Errorf("CreateRole = %v, want code %q", err, tt.wantCode)
+			}
+
+			editor, err := roleService.roleRepo.FindByName("editor")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = roleService.UpdateRole(tt.grantor, editor.ID, dto.UpdateRoleDTO{Permissions: tt.permissions})
+			if code := problemCode(t, err); code != tt.wantCode {
+				t.Errorf("UpdateRole = %v, want code %q", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"itv/internal/apperror"
+	"itv/internal/config"
+	"itv/internal/dto"
+	"itv/internal/fixtures"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/auth"
+	"itv/pkg/validator"
+	"log"
+	"slices"
+	"strings"
+)
+
+// SeedResult counts the records a seed run added.
+type SeedResult struct {
+	Genres int
+	Users  int
+	Movies int
+}
+
+// SeedService loads the embedded fixtures and generated fake movies, and
+// imports and exports movies in the same format. Records
+// are matched by natural key, genres by name, users by username and movies by
+// title and year, and the ones that exist are left as they are, so seeding
+// again only adds what is missing.
+type SeedService struct {
+	genreRepo    *repository.GenreRepository
+	userRepo     *repository.UserRepository
+	personRepo   *repository.PersonRepository
+	movieRepo    *repository.MovieRepository
+	movieService *MovieService
+	production   bool
+}
+
+func NewSeedService(
+	genreRepo *repository.GenreRepository,
+	userRepo *repository.UserRepository,
+	personRepo *repository.PersonRepository,
+	movieRepo *repository.MovieRepository,
+	movieService *MovieService,
+	config *config.Config,
+) *SeedService {
+	return &SeedService{
+		genreRepo:    genreRepo,
+		userRepo:     userRepo,
+		personRepo:   personRepo,
+		movieRepo:    movieRepo,
+		movieService: movieService,
+		production:   config.IsProduction(),
+	}
+}
+
+// Seed adds the fixtures followed by fakeMovies generated movies. Movies go
+// through MovieService and are validated like API requests. The demo users
+// are skipped in production since their passwords are published.
+func (s *SeedService) Seed(fakeMovies int) (*SeedResult, error) {
+	set, err := fixtures.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeedResult{}
+	genreIDs, err := s.seedGenres(set.Genres, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.production {
+		log.Println("seed: skipping the demo users in production")
+	} else if err := s.seedUsers(set.Users, result); err != nil {
+		return nil, err
+	}
+
+	for _, movie := range set.Movies {
+		if err := s.seedMovie(movie, genreIDs, result); err != nil {
+			return nil, err
+		}
+	}
+
+	genreNames := make([]string, 0, len(set.Genres))
+	for _, genre := range set.Genres {
+		genreNames = append(genreNames, genre.Name)
+	}
+	for i := 0; i < fakeMovies; i++ {
+		if err := s.seedMovie(fixtures.FakeMovie(i, genreNames), genreIDs, result); err != nil {
+			return nil, err
+		}
+		if (i+1)%100 == 0 {
+			log.Printf("seed: %d of %d fake movies done", i+1, fakeMovies)
+		}
+	}
+
+	log.Printf("seed: added %d genres, %d users and %d movies", result.Genres, result.Users, result.Movies)
+	return result, nil
+}
+
+// ImportMovies adds the movies that do not exist yet, as Seed does, and
+// creates the genres they name that are missing.
+func (s *SeedService) ImportMovies(movies []fixtures.Movie) (*SeedResult, error) {
+	result := &SeedResult{}
+	genreIDs, err := s.seedGenres(nil, result)
+	if err != nil {
+		return nil, err
+	}
+	for _, movie := range movies {
+		if err := s.seedMovie(movie, genreIDs, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ExportMovies returns every movie in the format ImportMovies reads, oldest
+// first. Media, translations and reviews are not included.
+func (s *SeedService) ExportMovies() ([]fixtures.Movie, error) {
+	movies, err := s.movieRepo.FindAll(repository.MovieFilter{})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(movies, func(a, b model.Movie) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	exported := make([]fixtures.Movie, 0, len(movies))
+	for i := range movies {
+		exported = append(exported, exportMovie(&movies[i]))
+	}
+	return exported, nil
+}
+
+// seedGenres adds the missing genres and maps the lowercase names of all
+// genres to their IDs.
+func (s *SeedService) seedGenres(genres []fixtures.Genre, result *SeedResult) (map[string]uint, error) {
+	for _, fixture := range genres {
+		exists, err := s.genreRepo.ExistsByName(fixture.Name, 0)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		genre := model.Genre{Name: fixture.Name, Description: fixture.Description}
+		if err := s.genreRepo.Create(&genre); err != nil {
+			return nil, fmt.Errorf("seeding genre %q: %w", fixture.Name, err)
+		}
+		result.Genres++
+	}
+
+	all, err := s.genreRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint, len(all))
+	for _, genre := range all {
+		ids[strings.ToLower(genre.Name)] = genre.ID
+	}
+	return ids, nil
+}
+
+func (s *SeedService) seedUsers(users []fixtures.User, result *SeedResult) error {
+	for _, fixture := range users {
+		_, err := s.userRepo.FindByUsername(fixture.Username)
+		if err == nil {
+			continue
+		}
+		if !apperror.IsNotFound(err) {
+			return err
+		}
+
+		hashedPassword, err := auth.HashPassword(fixture.Password)
+		if err != nil {
+			return err
+		}
+		user := model.User{Username: fixture.Username, Password: hashedPassword, Role: fixture.Role}
+		if err := s.userRepo.Create(&user); err != nil {
+			return fmt.Errorf("seeding user %q: %w", fixture.Username, err)
+		}
+		result.Users++
+	}
+	return nil
+}
+
+// seedMovie adds the movie unless one with its title and year exists. Genres
+// missing from genreIDs are created and added to it.
+func (s *SeedService) seedMovie(fixture fixtures.Movie, genreIDs map[string]uint, result *SeedResult) error {
+	exists, err := s.movieRepo.ExistsByTitleAndYear(fixture.Title, fixture.Year)
+	if err != nil || exists {
+		return err
+	}
+
+	movieDTO := dto.CreateMovieDTO{
+		Title:            fixture.Title,
+		Year:             fixture.Year,
+		Plot:             fixture.Plot,
+		OriginalLanguage: fixture.OriginalLanguage,
+		Rating:           fixture.Rating,
+		Duration:         fixture.Duration,
+		ReleaseDate:      fixture.ReleaseDate,
+		Tags:             fixture.Tags,
+	}
+	for _, name := range fixture.Genres {
+		id, ok := genreIDs[strings.ToLower(name)]
+		if !ok {
+			genre := model.Genre{Name: name}
+			if err := s.genreRepo.Create(&genre); err != nil {
+				return fmt.Errorf("seeding genre %q: %w", name, err)
+			}
+			id = genre.ID
+			genreIDs[strings.ToLower(name)] = id
+			result.Genres++
+		}
+		movieDTO.GenreIDs = append(movieDTO.GenreIDs, id)
+	}
+
+	// The director is credited here rather than through movieDTO.Director,
+	// which is ignored when the cast holds another director.
+	credits := fixture.Cast
+	if fixture.Director != "" {
+		credits = append([]fixtures.Credit{{Name: fixture.Director, Role: model.CreditRoleDirector}}, credits...)
+	}
+	for i, credit := range credits {
+		person, err := s.personRepo.FindOrCreateByName(credit.Name)
+		if err != nil {
+			return err
+		}
+		role := credit.Role
+		if role == "" {
+			role = model.CreditRoleActor
+		}
+		movieDTO.Credits = append(movieDTO.Credits, dto.CreditDTO{
+			PersonID:  person.ID,
+			Role:      role,
+			Character: credit.Character,
+			Position:  i,
+		})
+	}
+	for _, release := range fixture.Releases {
+		movieDTO.Releases = append(movieDTO.Releases, dto.ReleaseDTO{
+			Country:     release.Country,
+			Type:        release.Type,
+			ReleaseDate: release.ReleaseDate,
+		})
+	}
+
+	validationErrs, err := validator.ValidateStruct(movieDTO)
+	if err != nil {
+		return err
+	}
+	if len(validationErrs) > 0 {
+		return fmt.Errorf("seeding movie %q (%d): %w", fixture.Title, fixture.Year, validationErrs)
+	}
+	if _, err := s.movieService.CreateMovie(movieDTO); err != nil {
+		return fmt.Errorf("seeding movie %q (%d): %w", fixture.Title, fixture.Year, err)
+	}
+	result.Movies++
+	return nil
+}
+
+// exportMovie maps movie to the fixture format. The first director becomes
+// Director and the other credits keep their order in Cast.
+func exportMovie(movie *model.Movie) fixtures.Movie {
+	exported := fixtures.Movie{
+		Title:            movie.Title,
+		Year:             movie.Year,
+		Plot:             movie.Plot,
+		OriginalLanguage: movie.OriginalLanguage,
+		Rating:           movie.Rating,
+		Duration:         movie.Duration,
+		Genres:           []string{},
+	}
+	if movie.ReleaseDate != nil {
+		exported.ReleaseDate = movie.ReleaseDate.Format(dto.DateLayout)
+	}
+	for _, genre := range movie.Genres {
+		exported.Genres = append(exported.Genres, genre.Name)
+	}
+	for _, tag := range movie.Tags {
+		exported.Tags = append(exported.Tags, tag.Name)
+	}
+	for _, credit := range movie.Credits {
+		if credit.Role == model.CreditRoleDirector && exported.Director == "" {
+			exported.Director = credit.Person.Name
+			continue
+		}
+		role := credit.Role
+		if role == model.CreditRoleActor {
+			role = ""
+		}
+		exported.Cast = append(exported.Cast, fixtures.Credit{
+			Name:      credit.Person.Name,
+			Role:      role,
+			Character: credit.Character,
+		})
+	}
+	for _, release := range movie.Releases {
+		exported.Releases = append(exported.Releases, fixtures.Release{
+			Country:     release.Country,
+			Type:        release.Type,
+			ReleaseDate: release.ReleaseDate.Format(dto.DateLayout),
+		})
+	}
+	return exported
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/locale"
+	"strings"
+)
+
+var ErrInvalidLocale = apperror.InvalidField("locale", "bcp47_language_tag", "")
+
+type TranslationService struct {
+	translationRepo *repository.TranslationRepository
+	movieRepo       *repository.MovieRepository
+	movieCache      *MovieCache
+}
+
+func NewTranslationService(
+	translationRepo *repository.TranslationRepository,
+	movieRepo *repository.MovieRepository,
+	movieCache *MovieCache,
+) *TranslationService {
+	return &TranslationService{
+		translationRepo: translationRepo,
+		movieRepo:       movieRepo,
+		movieCache:      movieCache,
+	}
+}
+
+func (s *TranslationService) GetTranslations(movieID uint) ([]dto.TranslationResponseDTO, error) {
+	if _, err := s.movieRepo.Replica().FindByID(movieID); err != nil {
+		return nil, err
+	}
+
+	translations, err := s.translationRepo.Replica().FindByMovie(movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	translationsDTO := make([]dto.TranslationResponseDTO, 0, len(translations))
+	for _, translation := range translations {
+		translationsDTO = append(translationsDTO, mapTranslationToDTO(&translation))
+	}
+
+	return translationsDTO, nil
+}
+
+// SaveTranslation creates or replaces the translation of a movie into tag.
+func (s *TranslationService) SaveTranslation(movieID uint, tag string, translationDTO dto.TranslationDTO) (*dto.TranslationResponseDTO, error) {
+	normalized, err := locale.Normalize(tag)
+	if err != nil {
+		return nil, ErrInvalidLocale
+	}
+
+	if _, err := s.movieRepo.FindByID(movieID); err != nil {
+		return nil, err
+	}
+
+	translation := model.MovieTranslation{
+		MovieID: movieID,
+		Locale:  normalized,
+		Title:   strings.TrimSpace(translationDTO.Title),
+		Plot:    translationDTO.Plot,
+	}
+
+	err = s.translationRepo.Save(&translation)
+	if err != nil {
+		return nil, err
+	}
+	s.movieCache.InvalidateMovie(movieID)
+
+	responseDTO := mapTranslationToDTO(&translation)
+	return &responseDTO, nil
+}
+
+func (s *TranslationService) DeleteTranslation(movieID uint, tag string) error {
+	normalized, err := locale.Normalize(tag)
+	if err != nil {
+		return ErrInvalidLocale
+	}
+
+	if _, err := s.movieRepo.FindByID(movieID); err != nil {
+		return err
+	}
+
+	err = s.translationRepo.Delete(movieID, normalized)
+	if err != nil {
+		return err
+	}
+	s.movieCache.InvalidateMovie(movieID)
+	return nil
+}
+
+// TranslatedTitles maps each of the given movies that has a translation
+// preferred over its original language to the translated title.
+func (s *TranslationService) TranslatedTitles(prefs locale.Preferences, movieIDs []uint) (map[uint]string, error) {
+	titles := make(map[uint]string)
+	if len(prefs) == 0 || len(movieIDs) == 0 {
+		return titles, nil
+	}
+
+	translations, err := s.translationRepo.FindByMovies(movieIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(translations) == 0 {
+		return titles, nil
+	}
+
+	languages, err := s.movieRepo.FindOriginalLanguages(movieIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byMovie := make(map[uint][]model.MovieTranslation)
+	for _, translation := range translations {
+		byMovie[translation.MovieID] = append(byMovie[translation.MovieID], translation)
+	}
+	for movieID, movieTranslations := range byMovie {
+		if translation := chooseTranslation(prefs, languages[movieID], movieTranslations); translation != nil {
+			titles[movieID] = translation.Title
+		}
+	}
+
+	return titles, nil
+}
+
+// localizeSummaries replaces the titles of the summaries with the
+// translations that prefs favour.
+func (s *TranslationService) localizeSummaries(prefs locale.Preferences, summaries []*dto.MovieSummaryDTO) error {
+	if len(prefs) == 0 || len(summaries) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(summaries))
+	for _, summary := range summaries {
+		ids = append(ids, summary.ID)
+	}
+
+	titles, err := s.TranslatedTitles(prefs, uniqueIDs(ids))
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		if title, ok := titles[summary.ID]; ok {
+			summary.Title = title
+		}
+	}
+	return nil
+}
+
+// chooseTranslation returns the translation that prefs favour over the
+// original language, or nil when the original should be shown.
+func chooseTranslation(prefs locale.Preferences, original string, translations []model.MovieTranslation) *model.MovieTranslation {
+	if len(prefs) == 0 || len(translations) == 0 {
+		return nil
+	}
+
+	locales := make([]string, 0, len(translations))
+	for _, translation := range translations {
+		locales = append(locales, translation.Locale)
+	}
+
+	index := prefs.Choose(original, locales)
+	if index < 0 {
+		return nil
+	}
+	return &translations[index]
+}
+
+func mapTranslationToDTO(translation *model.MovieTranslation) dto.TranslationResponseDTO {
+	return dto.TranslationResponseDTO{
+		Locale:    translation.Locale,
+		Title:     translation.Title,
+		Plot:      translation.Plot,
+		UpdatedAt: translation.UpdatedAt,
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/auth"
+	"strings"
+)
+
+var (
+	ErrUsernameTaken = apperror.Conflict("username_taken", "user with this username already exists")
+	ErrLastAdmin     = apperror.Conflict("last_admin", "the last admin cannot give up the admin role")
+	ErrUnknownRole   = apperror.Validation("unknown_role", "role does not exist")
+)
+
+// operator is the grantor behind commands run on the server, which may
+// assign any role.
+var operator = Grantor{Role: model.RoleAdmin}
+
+// UserService manages accounts for operators. A new role applies from the
+// next request, while changing a password does not revoke tokens already
+// issued, which stay valid until they expire.
+type UserService struct {
+	userRepo    *repository.UserRepository
+	roleRepo    *repository.RoleRepository
+	roleService *RoleService
+}
+
+func NewUserService(userRepo *repository.UserRepository, roleRepo *repository.RoleRepository, roleService *RoleService) *UserService {
+	return &UserService{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		roleService: roleService,
+	}
+}
+
+func (s *UserService) GetAllUsers() ([]dto.UserResponseDTO, error) {
+	users, err := s.userRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	usersDTO := make([]dto.UserResponseDTO, 0, len(users))
+	for _, user := range users {
+		usersDTO = append(usersDTO, mapUserToDTO(&user))
+	}
+	return usersDTO, nil
+}
+
+func (s *UserService) CreateUser(userDTO dto.CreateUserDTO) (*dto.UserResponseDTO, error) {
+	username := strings.TrimSpace(userDTO.Username)
+	_, err := s.userRepo.FindByUsername(username)
+	if err == nil {
+		return nil, ErrUsernameTaken
+	}
+	if !apperror.IsNotFound(err) {
+		return nil, err
+	}
+	if err := s.ensureRoleExists(userDTO.Role); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := auth.HashPassword(userDTO.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := model.User{
+		Username: username,
+		Password: hashedPassword,
+		Role:     userDTO.Role,
+	}
+	if err := s.userRepo.Create(&user); err != nil {
+		return nil, err
+	}
+
+	responseDTO := mapUserToDTO(&user)
+	return &responseDTO, nil
+}
+
+// SetRole changes the role of the user with the given username.
+func (s *UserService) SetRole(username string, roleDTO dto.UpdateUserRoleDTO) (*dto.UserResponseDTO, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	return s.setRole(operator, user, roleDTO.Role)
+}
+
+// SetRoleByID changes the role of the user with the given ID on behalf of
+// grantor.
+func (s *UserService) SetRoleByID(grantor Grantor, id uint, roleDTO dto.UpdateUserRoleDTO) (*dto.UserResponseDTO, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.setRole(grantor, user, roleDTO.Role)
+}
+
+// setRole requires grantor to be allowed to assign both the old and the new
+// role, so that nobody can demote a user who holds more than they do. It
+// keeps the last admin, since the admin account from ADMIN_USERNAME would
+// otherwise be created again on the next start.
+func (s *UserService) setRole(grantor Grantor, user *model.User, role string) (*dto.UserResponseDTO, error) {
+	if err := s.ensureRoleExists(role); err != nil {
+		return nil, err
+	}
+	if err := s.roleService.CheckAssign(grantor, user.Role); err != nil {
+		return nil, err
+	}
+	if err := s.roleService.CheckAssign(grantor, role); err != nil {
+		return nil, err
+	}
+
+	if user.Role == model.RoleAdmin && role != model.RoleAdmin {
+		admins, err := s.userRepo.CountByRole(model.RoleAdmin)
+		if err != nil {
+			return nil, err
+		}
+		if admins <= 1 {
+			return nil, ErrLastAdmin
+		}
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	s.roleService.InvalidateUserRole(user.ID)
+
+	responseDTO := mapUserToDTO(user)
+	return &responseDTO, nil
+}
+
+func (s *UserService) ResetPassword(username string, passwordDTO dto.ResetPasswordDTO) error {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := auth.HashPassword(passwordDTO.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	return s.userRepo.Update(user)
+}
+
+func (s *UserService) ensureRoleExists(role string) error {
+	_, err := s.roleRepo.FindByName(role)
+	if apperror.IsNotFound(err) {
+		return ErrUnknownRole
+	}
+	return err
+}
+
+func mapUserToDTO(user *model.User) dto.UserResponseDTO {
+	return dto.UserResponseDTO{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
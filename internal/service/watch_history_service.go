@@ -0,0 +1,100 @@
+package service
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/locale"
+)
+
+type WatchHistoryService struct {
+	historyRepo  *repository.WatchHistoryRepository
+	movieRepo    *repository.MovieRepository
+	recommends   *RecommendationService
+	translations *TranslationService
+}
+
+func NewWatchHistoryService(
+	historyRepo *repository.WatchHistoryRepository,
+	movieRepo *repository.MovieRepository,
+	recommends *RecommendationService,
+	translations *TranslationService,
+) *WatchHistoryService {
+	return &WatchHistoryService{
+		historyRepo:  historyRepo,
+		movieRepo:    movieRepo,
+		recommends:   recommends,
+		translations: translations,
+	}
+}
+
+// GetHistory lists the user's watched movies, titled in the language that
+// prefs favour.
+func (s *WatchHistoryService) GetHistory(userID uint, prefs locale.Preferences) ([]dto.WatchHistoryResponseDTO, error) {
+	history, err := s.historyRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	historyDTO := make([]dto.WatchHistoryResponseDTO, 0, len(history))
+	for _, entry := range history {
+		historyDTO = append(historyDTO, mapWatchHistoryToDTO(&entry))
+	}
+
+	summaries := make([]*dto.MovieSummaryDTO, 0, len(historyDTO))
+	for i := range historyDTO {
+		summaries = append(summaries, &historyDTO[i].Movie)
+	}
+	err = s.translations.localizeSummaries(prefs, summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	return historyDTO, nil
+}
+
+func (s *WatchHistoryService) MarkWatched(userID uint, watchedDTO dto.MarkWatchedDTO) (*dto.WatchHistoryResponseDTO, error) {
+	movie, err := s.movieRepo.FindByID(watchedDTO.MovieID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := model.WatchHistory{
+		UserID:  userID,
+		MovieID: movie.ID,
+	}
+	if watchedDTO.WatchedAt != nil {
+		entry.WatchedAt = *watchedDTO.WatchedAt
+	}
+
+	err = s.historyRepo.Create(&entry)
+	if err != nil {
+		return nil, err
+	}
+	s.recommends.InvalidateUser(userID)
+
+	entry.Movie = *movie
+	responseDTO := mapWatchHistoryToDTO(&entry)
+	return &responseDTO, nil
+}
+
+func (s *WatchHistoryService) DeleteEntry(userID, id uint) error {
+	deleted, err := s.historyRepo.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apperror.NotFound("history_entry_not_found", "history entry not found")
+	}
+	s.recommends.InvalidateUser(userID)
+	return nil
+}
+
+func mapWatchHistoryToDTO(entry *model.WatchHistory) dto.WatchHistoryResponseDTO {
+	return dto.WatchHistoryResponseDTO{
+		ID:        entry.ID,
+		Movie:     mapMovieToSummaryDTO(&entry.Movie),
+		WatchedAt: entry.WatchedAt,
+	}
+}
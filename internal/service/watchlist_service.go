@@ -0,0 +1,268 @@
+package service
+
+import (
+	"itv/internal/apperror"
+	"itv/internal/dto"
+	"itv/internal/model"
+	"itv/internal/repository"
+	"itv/pkg/locale"
+	"strings"
+)
+
+var (
+	ErrWatchlistExists       = apperror.Conflict("watchlist_exists", "watchlist with this name already exists")
+	ErrDefaultWatchlist      = apperror.Conflict("default_watchlist", "the default watchlist cannot be renamed or deleted")
+	ErrAlreadyInWatchlist    = apperror.Conflict("already_in_watchlist", "movie is already in the watchlist")
+	ErrInvalidWatchlistOrder = apperror.Validation("invalid_watchlist_order", "movie_ids must list every movie of the watchlist exactly once")
+)
+
+// WatchlistService manages the authenticated user's watchlists. Every method
+// takes the owner's ID and never touches lists of other users; a watchlist ID
+// of 0 refers to the user's default list.
+type WatchlistService struct {
+	watchlistRepo *repository.WatchlistRepository
+	movieRepo     *repository.MovieRepository
+	translations  *TranslationService
+}
+
+func NewWatchlistService(watchlistRepo *repository.WatchlistRepository, movieRepo *repository.MovieRepository, translations *TranslationService) *WatchlistService {
+	return &WatchlistService{
+		watchlistRepo: watchlistRepo,
+		movieRepo:     movieRepo,
+		translations:  translations,
+	}
+}
+
+func (s *WatchlistService) GetWatchlists(userID uint) ([]dto.WatchlistResponseDTO, error) {
+	_, err := s.watchlistRepo.EnsureDefault(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlists, err := s.watchlistRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlistsDTO := make([]dto.WatchlistResponseDTO, 0, len(watchlists))
+	for _, watchlist := range watchlists {
+		watchlistsDTO = append(watchlistsDTO, mapWatchlistToDTO(&watchlist, nil))
+	}
+
+	return watchlistsDTO, nil
+}
+
+// GetWatchlist returns the watchlist with its movies, titled in the language
+// that prefs favour.
+func (s *WatchlistService) GetWatchlist(userID, id uint, prefs locale.Preferences) (*dto.WatchlistResponseDTO, error) {
+	watchlist, err := s.findWatchlist(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withItems(watchlist, prefs)
+}
+
+func (s *WatchlistService) CreateWatchlist(userID uint, watchlistDTO dto.CreateWatchlistDTO) (*dto.WatchlistResponseDTO, error) {
+	// The default list reserves its name before any custom list can take it.
+	_, err := s.watchlistRepo.EnsureDefault(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(watchlistDTO.Name)
+	if err := s.ensureNameAvailable(userID, name, 0); err != nil {
+		return nil, err
+	}
+
+	watchlist := model.Watchlist{
+		UserID: userID,
+		Name:   name,
+	}
+
+	err = s.watchlistRepo.Create(&watchlist)
+	if err != nil {
+		return nil, err
+	}
+
+	responseDTO := mapWatchlistToDTO(&watchlist, nil)
+	return &responseDTO, nil
+}
+
+func (s *WatchlistService) RenameWatchlist(userID, id uint, watchlistDTO dto.UpdateWatchlistDTO) (*dto.WatchlistResponseDTO, error) {
+	watchlist, err := s.findWatchlist(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if watchlist.IsDefault {
+		return nil, ErrDefaultWatchlist
+	}
+
+	name := strings.TrimSpace(watchlistDTO.Name)
+	if err := s.ensureNameAvailable(userID, name, watchlist.ID); err != nil {
+		return nil, err
+	}
+	watchlist.Name = name
+
+	err = s.watchlistRepo.Update(watchlist)
+	if err != nil {
+		return nil, err
+	}
+
+	responseDTO := mapWatchlistToDTO(watchlist, nil)
+	return &responseDTO, nil
+}
+
+func (s *WatchlistService) DeleteWatchlist(userID, id uint) error {
+	watchlist, err := s.findWatchlist(userID, id)
+	if err != nil {
+		return err
+	}
+	if watchlist.IsDefault {
+		return ErrDefaultWatchlist
+	}
+
+	return s.watchlistRepo.Delete(watchlist.ID)
+}
+
+func (s *WatchlistService) AddMovie(userID, id uint, itemDTO dto.AddWatchlistItemDTO) (*dto.WatchlistResponseDTO, error) {
+	watchlist, err := s.findWatchlist(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.movieRepo.FindByID(itemDTO.MovieID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.watchlistRepo.HasItem(watchlist.ID, itemDTO.MovieID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAlreadyInWatchlist
+	}
+
+	err = s.watchlistRepo.AddItem(&model.WatchlistItem{
+		WatchlistID: watchlist.ID,
+		MovieID:     itemDTO.MovieID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetWatchlist(userID, watchlist.ID, nil)
+}
+
+func (s *WatchlistService) RemoveMovie(userID, id, movieID uint) error {
+	watchlist, err := s.findWatchlist(userID, id)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.watchlistRepo.RemoveItem(watchlist.ID, movieID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return apperror.NotFound("watchlist_item_not_found", "movie not in watchlist")
+	}
+	return nil
+}
+
+func (s *WatchlistService) ReorderMovies(userID, id uint, reorderDTO dto.ReorderWatchlistDTO) (*dto.WatchlistResponseDTO, error) {
+	watchlist, err := s.findWatchlist(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.watchlistRepo.FindItems(watchlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reorderDTO.MovieIDs) != len(items) {
+		return nil, ErrInvalidWatchlistOrder
+	}
+	current := make(map[uint]bool, len(items))
+	for _, item := range items {
+		current[item.MovieID] = true
+	}
+	for _, movieID := range reorderDTO.MovieIDs {
+		if !current[movieID] {
+			return nil, ErrInvalidWatchlistOrder
+		}
+		delete(current, movieID)
+	}
+
+	err = s.watchlistRepo.Reorder(watchlist.ID, reorderDTO.MovieIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetWatchlist(userID, watchlist.ID, nil)
+}
+
+func (s *WatchlistService) findWatchlist(userID, id uint) (*model.Watchlist, error) {
+	if id == 0 {
+		return s.watchlistRepo.EnsureDefault(userID)
+	}
+	return s.watchlistRepo.FindByID(userID, id)
+}
+
+func (s *WatchlistService) ensureNameAvailable(userID uint, name string, excludeID uint) error {
+	exists, err := s.watchlistRepo.ExistsByName(userID, name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrWatchlistExists
+	}
+	return nil
+}
+
+func (s *WatchlistService) withItems(watchlist *model.Watchlist, prefs locale.Preferences) (*dto.WatchlistResponseDTO, error) {
+	items, err := s.watchlistRepo.FindItems(watchlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responseDTO := mapWatchlistToDTO(watchlist, items)
+
+	summaries := make([]*dto.MovieSummaryDTO, 0, len(responseDTO.Items))
+	for i := range responseDTO.Items {
+		summaries = append(summaries, &responseDTO.Items[i].Movie)
+	}
+	err = s.translations.localizeSummaries(prefs, summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &responseDTO, nil
+}
+
+func mapWatchlistToDTO(watchlist *model.Watchlist, items []model.WatchlistItem) dto.WatchlistResponseDTO {
+	responseDTO := dto.WatchlistResponseDTO{
+		ID:        watchlist.ID,
+		Name:      watchlist.Name,
+		IsDefault: watchlist.IsDefault,
+		ItemCount: watchlist.ItemCount,
+		CreatedAt: watchlist.CreatedAt,
+		UpdatedAt: watchlist.UpdatedAt,
+	}
+
+	if items != nil {
+		responseDTO.ItemCount = len(items)
+		responseDTO.Items = make([]dto.WatchlistItemResponseDTO, 0, len(items))
+		for _, item := range items {
+			responseDTO.Items = append(responseDTO.Items, dto.WatchlistItemResponseDTO{
+				Movie:    mapMovieToSummaryDTO(&item.Movie),
+				Position: item.Position,
+				AddedAt:  item.AddedAt,
+			})
+		}
+	}
+
+	return responseDTO
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"itv/internal/config"
 	"itv/internal/model"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -20,21 +21,37 @@ type JWTClaims struct {
 
 type JWTService struct {
 	config *config.Config
+	// expiry follows configuration reloads.
+	expiry atomic.Int64
 }
 
-func NewJWTService(config *config.Config) *JWTService {
-	return &JWTService{
+func NewJWTService(config *config.Config, reloader *config.Reloader) *JWTService {
+	j := &JWTService{
 		config: config,
 	}
+	j.expiry.Store(int64(config.JWTExpiry))
+	reloader.Subscribe(j.reload)
+	return j
+}
+
+func (j *JWTService) reload(config *config.Config) error {
+	j.expiry.Store(int64(config.JWTExpiry))
+	return nil
 }
 
 func (j *JWTService) GenerateToken(user *model.User) (string, error) {
+	return j.GenerateTokenWithExpiry(user, time.Duration(j.expiry.Load()))
+}
+
+// GenerateTokenWithExpiry issues a token that expires after expiry instead
+// of JWT_EXPIRY.
+func (j *JWTService) GenerateTokenWithExpiry(user *model.User, expiry time.Duration) (string, error) {
 	claims := JWTClaims{
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.config.JWTExpiry)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
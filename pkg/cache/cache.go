@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"itv/internal/config"
+	"time"
+)
+
+// Cache stores opaque values under string keys for a limited time. A missing
+// or expired key is reported by Get returning false without an error.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix removes every key that starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// NewCache returns the backend selected by CACHE_DRIVER.
+func NewCache(config *config.Config) (Cache, error) {
+	switch config.CacheDriver {
+	case "memory":
+		return NewMemoryCache(config.CacheSize), nil
+	case "redis":
+		return NewRedisCache(RedisOptions{
+			Addr:     config.RedisAddr,
+			Password: config.RedisPassword,
+			DB:       config.RedisDB,
+		})
+	case "none":
+		return nopCache{}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", config.CacheDriver)
+	}
+}
+
+// nopCache never stores anything, so every read goes to the database.
+type nopCache struct{}
+
+func (nopCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (nopCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (nopCache) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
+
+func (nopCache) DeletePrefix(ctx context.Context, prefix string) error {
+	return nil
+}
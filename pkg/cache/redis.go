@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanBatch is the number of keys requested per SCAN call.
+const scanBatch = 500
+
+type RedisOptions struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// RedisCache keeps entries in Redis or any server speaking its protocol, so
+// that several API instances share the cache and its invalidations.
+type RedisCache struct {
+	client *redis.Client
+}
+
+func NewRedisCache(opts RedisOptions) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", opts.Addr, err)
+	}
+
+	return &RedisCache{
+		client: client,
+	}, nil
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Unlink(ctx, keys...).Err()
+}
+
+func (c *RedisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := c.client.Scan(ctx, 0, escapePattern(prefix)+"*", scanBatch).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanBatch {
+			if err := c.Delete(ctx, keys...); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return c.Delete(ctx, keys...)
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// escapePattern quotes the glob characters of a SCAN MATCH pattern.
+func escapePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
@@ -3,32 +3,117 @@ package database
 import (
 	"fmt"
 	"itv/internal/config"
-	"itv/internal/model"
 	"log"
+	"os"
+	"time"
 
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// NewDatabase creates a new database connection
+// maxConnectBackoff caps the wait between connection attempts at startup.
+const maxConnectBackoff = 30 * time.Second
+
+// NewDatabase creates a new database connection with the driver named by
+// DB_DRIVER. The database may still be starting, as under docker-compose, so
+// failed attempts are retried with exponential backoff. Read replicas in
+// DB_REPLICAS are registered for the queries marked with Replica.
 func NewDatabase(config *config.Config) (*gorm.DB, error) {
 	dsn := config.GetDBConnectionString()
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-
+	db, err := connect(config, "database", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	log.Println("Connected to database")
 
-	err = db.AutoMigrate(&model.Movie{}, &model.User{})
+	if len(config.DBReplicas) > 0 {
+		replicas := &replicas{}
+		for i, replicaDSN := range config.DBReplicas {
+			replica, err := connect(config, fmt.Sprintf("read replica %d", i+1), replicaDSN)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to read replica %d: %w", i+1, err)
+			}
+			replicas.pools = append(replicas.pools, replica.ConnPool)
+		}
+		if err := db.Use(replicas); err != nil {
+			return nil, err
+		}
+		log.Printf("Connected to %d read replica(s)", len(replicas.pools))
+	}
+
+	err = Migrate(db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to migrate database schema: %w", err)
+		return nil, err
 	}
 
 	return db, nil
 }
+
+// sqlLogger logs every query like the GORM default logger, but to the
+// standard error with the other logs, which keeps the standard output of
+// commands such as movies export clean.
+var sqlLogger = logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+	SlowThreshold: 200 * time.Millisecond,
+	LogLevel:      logger.Info,
+	Colorful:      true,
+})
+
+// connect opens dsn with the configured pool settings, retrying up to
+// DB_CONNECT_RETRIES times.
+func connect(config *config.Config, name, dsn string) (*gorm.DB, error) {
+	backoff := config.DBConnectBackoff
+	for attempt := 0; ; attempt++ {
+		db, err := gorm.Open(dialector(config.DBDriver, dsn), &gorm.Config{
+			Logger: sqlLogger,
+		})
+		if err == nil {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return nil, err
+			}
+			sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+			sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+			sqlDB.SetConnMaxLifetime(config.DBConnMaxLifetime)
+			sqlDB.SetConnMaxIdleTime(config.DBConnMaxIdleTime)
+			if config.DBDriver == "sqlite" && config.DBPath == ":memory:" {
+				// Every connection to :memory: opens a database of its
+				// own, so a single connection is kept open for good.
+				sqlDB.SetMaxOpenConns(1)
+				sqlDB.SetMaxIdleConns(1)
+				sqlDB.SetConnMaxLifetime(0)
+				sqlDB.SetConnMaxIdleTime(0)
+			}
+			return db, nil
+		}
+		if db != nil {
+			// The pool is open even when the first ping failed.
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		}
+		if attempt >= config.DBConnectRetries {
+			return nil, err
+		}
+
+		log.Printf("Connecting to %s failed, retrying in %s: %v", name, backoff, err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// dialector returns the GORM dialector of driver for dsn.
+func dialector(driver, dsn string) gorm.Dialector {
+	switch driver {
+	case "mysql":
+		return mysql.Open(dsn)
+	case "sqlite":
+		return sqlite.Open(dsn)
+	default:
+		return postgres.Open(dsn)
+	}
+}
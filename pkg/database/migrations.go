@@ -0,0 +1,234 @@
+package database
+
+import (
+	"fmt"
+	"itv/internal/model"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// legacyDateLayouts are the formats recognised in free-text release dates.
+var legacyDateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006/01/02",
+	"02.01.2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+// Migrate brings the schema up to date and runs the data migrations.
+func Migrate(db *gorm.DB) error {
+	err := normalizeReleaseDates(db)
+	if err != nil {
+		return fmt.Errorf("failed to normalize release dates: %w", err)
+	}
+
+	err = db.AutoMigrate(
+		&model.Movie{},
+		&model.User{},
+		&model.Genre{},
+		&model.Tag{},
+		&model.Person{},
+		&model.MovieCredit{},
+		&model.Review{},
+		&model.ReviewFlag{},
+		&model.Watchlist{},
+		&model.WatchlistItem{},
+		&model.WatchHistory{},
+		&model.MediaAsset{},
+		&model.MediaVariant{},
+		&model.MovieRelease{},
+		&model.MovieTranslation{},
+		&model.Role{},
+		&model.RolePermission{},
+		&model.APIKey{},
+		&model.APIKeyPermission{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database schema: %w", err)
+	}
+
+	err = migrateDirectors(db)
+	if err != nil {
+		return fmt.Errorf("failed to migrate movie directors: %w", err)
+	}
+
+	err = seedRoles(db)
+	if err != nil {
+		return fmt.Errorf("failed to create the built-in roles: %w", err)
+	}
+
+	return nil
+}
+
+// seedRoles creates the built-in roles and grants admin every permission,
+// including the ones added since the last start. Roles that users hold from
+// before roles were stored become roles without permissions.
+func seedRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		roles := []model.Role{
+			{Name: model.RoleAdmin, Description: "Full access, including every permission added later."},
+			{Name: model.RoleUser, Description: "Default role of new accounts."},
+		}
+		var legacy []string
+		err := tx.Model(&model.User{}).Distinct("role").
+			Where("role NOT IN ?", []string{model.RoleAdmin, model.RoleUser}).
+			Pluck("role", &legacy).Error
+		if err != nil {
+			return err
+		}
+		for _, name := range legacy {
+			roles = append(roles, model.Role{Name: name})
+		}
+
+		for _, role := range roles {
+			if err := tx.Where(model.Role{Name: role.Name}).Attrs(role).FirstOrCreate(&role).Error; err != nil {
+				return err
+			}
+			if role.Name != model.RoleAdmin {
+				continue
+			}
+
+			var granted []string
+			err := tx.Model(&model.RolePermission{}).Where("role_id = ?", role.ID).Pluck("permission", &granted).Error
+			if err != nil {
+				return err
+			}
+			for _, permission := range model.Permissions {
+				if slices.Contains(granted, permission) {
+					continue
+				}
+				if err := tx.Create(&model.RolePermission{RoleID: role.ID, Permission: permission}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// migrateDirectors converts the legacy free-text movies.director column into
+// people and director credits and then drops the column. Names are matched
+// case-insensitively and comma-separated values yield one credit per name.
+func migrateDirectors(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&model.Movie{}, "director") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID       uint
+			Director string
+		}
+		err := tx.Table("movies").Select("id, director").
+			Where("director IS NOT NULL AND director <> ''").
+			Order("id").Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		people := make(map[string]uint)
+		for _, row := range rows {
+			for position, name := range strings.Split(row.Director, ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+
+				key := strings.ToLower(name)
+				personID, ok := people[key]
+				if !ok {
+					var person model.Person
+					err := tx.Where("LOWER(name) = ?", key).Order("id").Limit(1).Find(&person).Error
+					if err == nil && person.ID == 0 {
+						person = model.Person{Name: name}
+						err = tx.Create(&person).Error
+					}
+					if err != nil {
+						return err
+					}
+					personID = person.ID
+					people[key] = personID
+				}
+
+				credit := model.MovieCredit{
+					MovieID:  row.ID,
+					PersonID: personID,
+					Role:     model.CreditRoleDirector,
+					Position: position,
+				}
+				if err := tx.Omit("Movie", "Person").Create(&credit).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Migrator().DropColumn(&model.Movie{}, "director")
+	})
+}
+
+// normalizeReleaseDates rewrites the legacy free-text movies.release_date
+// values as ISO 8601 dates so that AutoMigrate can convert the column to a
+// date. Values that cannot be parsed are cleared and logged.
+func normalizeReleaseDates(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.Movie{}) {
+		return nil
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(&model.Movie{})
+	if err != nil {
+		return err
+	}
+	for _, column := range columnTypes {
+		if column.Name() == "release_date" && strings.EqualFold(column.DatabaseTypeName(), "date") {
+			return nil
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID          uint
+			Title       string
+			ReleaseDate *string
+		}
+		err := tx.Table("movies").Select("id, title, release_date").
+			Where("release_date IS NOT NULL").
+			Order("id").Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			var value interface{}
+			if date, ok := parseLegacyDate(*row.ReleaseDate); ok {
+				value = date.Format("2006-01-02")
+			} else {
+				log.Printf("clearing unparseable release date %q of movie %d (%s)", *row.ReleaseDate, row.ID, row.Title)
+			}
+
+			err := tx.Table("movies").Where("id = ?", row.ID).Update("release_date", value).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func parseLegacyDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range legacyDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// replicaKey marks the statements that may run on a read replica.
+const replicaKey = "database:replica"
+
+// Replica returns db for reads that tolerate replication lag, such as
+// listings and search. Its queries run on one of the read replicas, taken in
+// turn, unless none are configured or they are part of a transaction. All
+// other statements, and every write, run on the primary.
+func Replica(db *gorm.DB) *gorm.DB {
+	return db.Set(replicaKey, true).Session(&gorm.Session{})
+}
+
+// replicas is a GORM plugin that sends the queries marked by Replica to the
+// read replica connection pools.
+type replicas struct {
+	pools []gorm.ConnPool
+	next  atomic.Uint64
+}
+
+func (r *replicas) Name() string {
+	return "itv:replicas"
+}
+
+func (r *replicas) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("itv:replica_query", r.route); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("itv:replica_row", r.route)
+}
+
+func (r *replicas) route(db *gorm.DB) {
+	if _, ok := db.Get(replicaKey); !ok {
+		return
+	}
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); inTransaction {
+		return
+	}
+	db.Statement.ConnPool = r.pools[r.next.Add(1)%uint64(len(r.pools))]
+}
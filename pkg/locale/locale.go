@@ -0,0 +1,80 @@
+package locale
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Preferences is the ordered list of languages a client asked for, most
+// preferred first.
+type Preferences []language.Tag
+
+// Normalize validates a BCP 47 language tag and returns its canonical form,
+// such as "pt-BR" for "pt_br".
+func Normalize(tag string) (string, error) {
+	parsed, err := language.Parse(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
+
+// ParsePreferences reads the preferred languages from an explicit lang value,
+// which may hold a comma-separated list, and otherwise from an
+// Accept-Language header. Invalid values are ignored.
+func ParsePreferences(lang, acceptLanguage string) Preferences {
+	if lang != "" {
+		var prefs Preferences
+		for _, value := range strings.Split(lang, ",") {
+			if tag, err := language.Parse(strings.TrimSpace(value)); err == nil {
+				prefs = append(prefs, tag)
+			}
+		}
+		if len(prefs) > 0 {
+			return prefs
+		}
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return nil
+	}
+	return tags
+}
+
+// Choose returns the index in available of the best translation, or -1 when
+// the original, written in the original language, should be used. Each
+// preference is tried in order, first for an exact match and then for a
+// match on the base language alone, so "pt-BR" falls back to "pt".
+func (p Preferences) Choose(original string, available []string) int {
+	candidates := make([]language.Tag, 0, len(available)+1)
+	for _, value := range append([]string{original}, available...) {
+		tag, err := language.Parse(value)
+		if err != nil {
+			tag = language.Und
+		}
+		candidates = append(candidates, tag)
+	}
+
+	for _, pref := range p {
+		if pref == language.Und {
+			continue
+		}
+		for i, candidate := range candidates {
+			if candidate == pref {
+				return i - 1
+			}
+		}
+		base, _ := pref.Base()
+		for i, candidate := range candidates {
+			if candidate == language.Und {
+				continue
+			}
+			if candidateBase, _ := candidate.Base(); candidateBase == base {
+				return i - 1
+			}
+		}
+	}
+	return -1
+}
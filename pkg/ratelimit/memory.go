@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryStore drops idle buckets and expired
+// failure counters.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps the state in process memory, so every instance of the
+// API enforces its own limits.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	failures  map[string]*failureEntry
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	burst    int
+	interval time.Duration
+	// full is when the bucket has refilled completely and can be dropped.
+	full time.Time
+}
+
+type failureEntry struct {
+	Failures
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		failures: make(map[string]*failureEntry),
+	}
+}
+
+func (s *MemoryStore) Take(key string, limit Limit) Result {
+	if limit.Unlimited() {
+		return Result{Allowed: true}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+
+	interval := limit.interval()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.burst, b.interval = limit.Burst, interval
+	b.tokens = b.tokensAt(now)
+	b.updated = now
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(interval))
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((float64(limit.Burst) - b.tokens) * float64(interval))
+	b.full = now.Add(result.Reset)
+	return result
+}
+
+func (s *MemoryStore) Buckets(prefix string) []Bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+
+	var buckets []Bucket
+	for key, b := range s.buckets {
+		if !strings.HasPrefix(key, prefix) || !now.Before(b.full) {
+			continue
+		}
+		tokens := b.tokensAt(now)
+		buckets = append(buckets, Bucket{
+			Key:       key,
+			Limit:     b.burst,
+			Remaining: int(tokens),
+			Reset:     time.Duration((float64(b.burst) - tokens) * float64(b.interval)),
+		})
+	}
+	return buckets
+}
+
+func (s *MemoryStore) Attempt(key string, window time.Duration, wait func(Failures) time.Duration) (Failures, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+
+	entry, ok := s.failures[key]
+	if !ok || !now.Before(entry.expires) {
+		entry = &failureEntry{}
+	}
+	if delay := wait(entry.Failures); delay > 0 {
+		return entry.Failures, delay
+	}
+
+	s.failures[key] = entry
+	entry.Count++
+	entry.Last = now
+	entry.expires = now.Add(window)
+	return entry.Failures, 0
+}
+
+func (s *MemoryStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.failures[key]; ok {
+		entry.Count--
+		if entry.Count <= 0 {
+			delete(s.failures, key)
+		}
+	}
+}
+
+func (s *MemoryStore) Failures(key string) Failures {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.failures[key]
+	if !ok || !time.Now().Before(entry.expires) {
+		return Failures{}
+	}
+	return entry.Failures
+}
+
+func (s *MemoryStore) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, key)
+}
+
+// tokensAt returns the tokens in the bucket at now after refilling it.
+func (b *bucket) tokensAt(now time.Time) float64 {
+	tokens := b.tokens + float64(now.Sub(b.updated))/float64(b.interval)
+	return min(tokens, float64(b.burst))
+}
+
+// sweep drops full buckets, which behave exactly like missing ones, and
+// expired failure counters. The caller must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, entry := range s.failures {
+		if !now.Before(entry.expires) {
+			delete(s.failures, key)
+		}
+	}
+}
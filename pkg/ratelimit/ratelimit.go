@@ -0,0 +1,162 @@
+// Package ratelimit implements token buckets and failure counters for
+// throttling clients.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit lets Requests requests through per Period on average and up to Burst
+// at once. A zero Limit does not restrict anything.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// ParseLimit reads a limit written as "<requests>/<period>[:<burst>]", for
+// example "60/1m" or "10/1s:30". The period may omit a leading 1, as in
+// "60/m", and the burst defaults to the number of requests. An empty string
+// or "0" means no limit.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return Limit{}, nil
+	}
+
+	rate, burst, hasBurst := strings.Cut(value, ":")
+	requests, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q: want <requests>/<period>[:<burst>]", value)
+	}
+
+	var limit Limit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: requests must be a positive integer", value)
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: period must be a positive duration", value)
+	}
+	limit.Burst = limit.Requests
+	if hasBurst {
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid limit %q: burst must be a positive integer", value)
+		}
+	}
+	return limit, nil
+}
+
+// Unlimited reports whether the limit lets everything through.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s:%d", l.Requests, l.Period, l.Burst)
+}
+
+// interval is the time it takes to refill one token.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// AnyRole is the Policy entry for roles without a limit of their own.
+const AnyRole = "*"
+
+// Policy maps roles to their limits.
+type Policy map[string]Limit
+
+// ParsePolicy reads a comma-separated list of role=limit pairs, such as
+// "user=120/1m:30,admin=600/1m". A limit without a role applies to every
+// role that is not listed.
+func ParsePolicy(value string) (Policy, error) {
+	policy := Policy{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		role, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			role, limit = AnyRole, entry
+		}
+		parsed, err := ParseLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+		policy[strings.TrimSpace(role)] = parsed
+	}
+	return policy, nil
+}
+
+// For returns the limit of role.
+func (p Policy) For(role string) Limit {
+	if limit, ok := p[role]; ok {
+		return limit
+	}
+	return p[AnyRole]
+}
+
+// Result describes a bucket after Take.
+type Result struct {
+	Allowed bool
+	// Limit is the bucket's capacity.
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when
+	// Allowed is true.
+	RetryAfter time.Duration
+}
+
+// Bucket describes a token bucket that is not full.
+type Bucket struct {
+	Key       string
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// Failures counts consecutive failures, such as failed logins, under a key.
+type Failures struct {
+	Count int
+	Last  time.Time
+}
+
+// Store keeps the state of token buckets and failure counters.
+type Store interface {
+	// Take removes a token from the bucket under key if one is left.
+	Take(key string, limit Limit) Result
+	// Buckets lists the buckets under keys starting with prefix that are
+	// not full.
+	Buckets(prefix string) []Bucket
+	// Attempt counts an attempt under key as a failure in advance, unless
+	// wait returns a positive delay for the counter as it is, which Attempt
+	// then returns without counting. Checking and counting in one step keeps
+	// concurrent attempts from all passing the check. The counter is
+	// forgotten once no attempt was counted for window.
+	Attempt(key string, window time.Duration, wait func(Failures) time.Duration) (Failures, time.Duration)
+	// Release takes back an attempt that neither failed nor succeeded.
+	Release(key string)
+	// Failures returns the counter under key.
+	Failures(key string) Failures
+	// Reset forgets the failures under key.
+	Reset(key string)
+}
+
+// NewStore returns the store the API keeps its limits in.
+func NewStore() Store {
+	return NewMemoryStore()
+}
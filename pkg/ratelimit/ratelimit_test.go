@@ -0,0 +1,236 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{"", Limit{}, false},
+		{"0", Limit{}, false},
+		{"60/1m", Limit{Requests: 60, Period: time.Minute, Burst: 60}, false},
+		{"60/m", Limit{Requests: 60, Period: time.Minute, Burst: 60}, false},
+		{" 10/1s:30 ", Limit{Requests: 10, Period: time.Second, Burst: 30}, false},
+		{"5/15m", Limit{Requests: 5, Period: 15 * time.Minute, Burst: 5}, false},
+		{"60", Limit{}, true},
+		{"0/1m", Limit{}, true},
+		{"-1/1m", Limit{}, true},
+		{"x/1m", Limit{}, true},
+		{"60/", Limit{}, true},
+		{"60/0s", Limit{}, true},
+		{"60/fortnight", Limit{}, true},
+		{"60/1m:0", Limit{}, true},
+		{"60/1m:x", Limit{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLimitString(t *testing.T) {
+	for _, value := range []string{"0", "60/1m0s:60", "10/1s:30"} {
+		limit, err := ParseLimit(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := limit.String(); got != value {
+			t.Errorf("ParseLimit(%q).String() = %q", value, got)
+		}
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         Limit
+		takes         int
+		wantAllowed   int
+		wantRemaining int
+	}{
+		{"unlimited", Limit{}, 100, 100, 0},
+		{"within the burst", Limit{Requests: 10, Period: time.Hour, Burst: 3}, 2, 2, 1},
+		{"burst used up", Limit{Requests: 10, Period: time.Hour, Burst: 3}, 5, 3, 0},
+		{"burst above the rate", Limit{Requests: 1, Period: time.Hour, Burst: 5}, 6, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			var allowed int
+			var last Result
+			for i := 0; i < tt.takes; i++ {
+				last = s.Take("user:1", tt.limit)
+				if last.Allowed {
+					allowed++
+				}
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("%d of %d requests allowed, want %d", allowed, tt.takes, tt.wantAllowed)
+			}
+			if last.Remaining != tt.wantRemaining {
+				t.Errorf("Remaining = %d, want %d", last.Remaining, tt.wantRemaining)
+			}
+			if last.Allowed != (last.RetryAfter == 0) {
+				t.Errorf("Allowed = %t with RetryAfter %s", last.Allowed, last.RetryAfter)
+			}
+			if !tt.limit.Unlimited() && last.Limit != tt.limit.Burst {
+				t.Errorf("Limit = %d, want %d", last.Limit, tt.limit.Burst)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Requests: 1, Period: 6 * time.Minute, Burst: 2}
+	s.Take("user:1", limit)
+	s.Take("user:1", limit)
+
+	denied := s.Take("user:1", limit)
+	if denied.Allowed {
+		t.Fatal("request beyond the burst was allowed")
+	}
+	if denied.RetryAfter <= 5*time.Minute || denied.RetryAfter > 6*time.Minute {
+		t.Errorf("RetryAfter = %s, want just under 6m", denied.RetryAfter)
+	}
+	if denied.Reset <= 11*time.Minute || denied.Reset > 12*time.Minute {
+		t.Errorf("Reset = %s, want just under 12m", denied.Reset)
+	}
+	if other := s.Take("user:2", limit); !other.Allowed {
+		t.Error("another key shares the bucket")
+	}
+
+	// Pretend a token has been refilled.
+	s.mu.Lock()
+	s.buckets["user:1"].updated = time.Now().Add(-limit.interval())
+	s.mu.Unlock()
+	if result := s.Take("user:1", limit); !result.Allowed {
+		t.Errorf("request after the refill interval was denied: %+v", result)
+	}
+}
+
+func TestMemoryStoreBuckets(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Requests: 10, Period: time.Minute, Burst: 10}
+	s.Take("rl:user:1", limit)
+	s.Take("rl:user:1", limit)
+	s.Take("rl:user:2", limit)
+	s.Take("login:1.1.1.1", limit)
+	s.Take("rl:unlimited", Limit{})
+
+	buckets := s.Buckets("rl:")
+	remaining := make(map[string]int, len(buckets))
+	for _, b := range buckets {
+		remaining[b.Key] = b.Remaining
+	}
+	if len(remaining) != 2 || remaining["rl:user:1"] != 8 || remaining["rl:user:2"] != 9 {
+		t.Errorf("Buckets = %+v, want rl:user:1 with 8 and rl:user:2 with 9 remaining", buckets)
+	}
+}
+
+func TestMemoryStoreAttempt(t *testing.T) {
+	s := NewMemoryStore()
+	never := func(Failures) time.Duration { return 0 }
+	afterTwo := func(f Failures) time.Duration {
+		if f.Count >= 2 {
+			return time.Minute
+		}
+		return 0
+	}
+
+	for i := 1; i <= 2; i++ {
+		failures, wait := s.Attempt("admin", time.Hour, afterTwo)
+		if failures.Count != i || wait != 0 {
+			t.Fatalf("attempt %d = %d failures, wait %s", i, failures.Count, wait)
+		}
+	}
+	failures, wait := s.Attempt("admin", time.Hour, afterTwo)
+	if failures.Count != 2 || wait != time.Minute {
+		t.Errorf("refused attempt = %d failures, wait %s; want 2 and 1m", failures.Count, wait)
+	}
+	if got := s.Failures("other").Count; got != 0 {
+		t.Errorf("another key has %d failures", got)
+	}
+
+	s.Release("admin")
+	if got := s.Failures("admin").Count; got != 1 {
+		t.Errorf("%d failures after Release, want 1", got)
+	}
+	s.Reset("admin")
+	if got := s.Failures("admin").Count; got != 0 {
+		t.Errorf("%d failures after Reset, want 0", got)
+	}
+
+	// Counters expire once the window has passed since the last attempt.
+	s.Attempt("expiring", time.Nanosecond, never)
+	time.Sleep(time.Millisecond)
+	if failures, _ := s.Attempt("expiring", time.Hour, never); failures.Count != 1 {
+		t.Errorf("%d failures after the window passed, want 1", failures.Count)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	user := Limit{Requests: 120, Period: time.Minute, Burst: 30}
+	admin := Limit{Requests: 600, Period: time.Minute, Burst: 600}
+	tests := []struct {
+		value   string
+		want    Policy
+		wantErr bool
+	}{
+		{"", Policy{}, false},
+		{"user=120/1m:30", Policy{"user": user}, false},
+		{"user=120/1m:30, admin=600/1m", Policy{"user": user, "admin": admin}, false},
+		{"600/1m,user=120/1m:30", Policy{AnyRole: admin, "user": user}, false},
+		{"user=120/1m:30,,", Policy{"user": user}, false},
+		{"anonymous=0", Policy{"anonymous": {}}, false},
+		{"user=120", nil, true},
+		{"user=120/1m,admin=x", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePolicy(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePolicy(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParsePolicy(%q) = %v, want %v", tt.value, got, tt.want)
+			continue
+		}
+		for role, limit := range tt.want {
+			if got[role] != limit {
+				t.Errorf("ParsePolicy(%q)[%q] = %+v, want %+v", tt.value, role, got[role], limit)
+			}
+		}
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	user := Limit{Requests: 1, Period: time.Second, Burst: 1}
+	other := Limit{Requests: 2, Period: time.Second, Burst: 2}
+	tests := []struct {
+		policy Policy
+		role   string
+		want   Limit
+	}{
+		{Policy{"user": user}, "user", user},
+		{Policy{"user": user}, "admin", Limit{}},
+		{Policy{"user": user, AnyRole: other}, "admin", other},
+		{Policy{"user": user, AnyRole: other}, "user", user},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.For(tt.role); got != tt.want {
+			t.Errorf("%v.For(%q) = %+v, want %+v", tt.policy, tt.role, got, tt.want)
+		}
+	}
+}
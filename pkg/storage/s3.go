@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Options struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// PublicURL is the prefix clients fetch objects from, e.g. a CDN.
+	// Defaults to path-style URLs on the endpoint.
+	PublicURL string
+}
+
+// S3Storage stores files in any S3-compatible service (AWS S3, MinIO, ...).
+// Objects must be publicly readable, for example through a bucket policy.
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+func NewS3Storage(options S3Options) (*S3Storage, error) {
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(options.AccessKey, options.SecretKey, ""),
+		Secure: options.UseSSL,
+		Region: options.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, options.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach S3 bucket %q: %w", options.Bucket, err)
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, options.Bucket, minio.MakeBucketOptions{Region: options.Region})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 bucket %q: %w", options.Bucket, err)
+		}
+	}
+
+	publicURL := options.PublicURL
+	if publicURL == "" {
+		publicURL = client.EndpointURL().String() + "/" + options.Bucket
+	}
+
+	return &S3Storage{
+		client:    client,
+		bucket:    options.Bucket,
+		publicURL: publicURL,
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	return err
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Storage) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"itv/internal/config"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded files under slash-separated keys such as
+// "movies/1/poster/ab12.jpg" and knows the public URL of each key.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// NewStorage returns the backend selected by STORAGE_DRIVER.
+func NewStorage(config *config.Config) (Storage, error) {
+	switch config.StorageDriver {
+	case "local":
+		return NewLocalStorage(config.MediaDir, config.MediaBaseURL)
+	case "s3":
+		return NewS3Storage(S3Options{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+			UseSSL:    config.S3UseSSL,
+			PublicURL: config.S3PublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.StorageDriver)
+	}
+}
+
+// cleanKey rejects keys that could escape the storage root.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return key, nil
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
@@ -0,0 +1,122 @@
+package validator
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage is used when a client accepts none of the translations.
+const DefaultLanguage = "en"
+
+// languages are the message translations in order of preference; the first
+// one is the fallback.
+var languages = []language.Tag{language.English, language.Russian, language.Uzbek}
+
+var languageMatcher = language.NewMatcher(languages)
+
+// messages maps a language and a rule to a message template. {param} is
+// replaced with the rule parameter and {tag} with the rule name.
+var messages = map[string]map[string]string{
+	"en": {
+		"required":           "This field is required",
+		"required_without":   "This field is required when {param} is not set",
+		"email":              "Must be a valid email address",
+		"min":                "Must be at least {param}",
+		"max":                "Must not be greater than {param}",
+		"gt":                 "Must be greater than {param}",
+		"lt":                 "Must be less than {param}",
+		"oneof":              "Must be one of: {param}",
+		"len":                "Must have length {param}",
+		"date":               "Must be a date in the format {param}",
+		"datetime":           "Must be a date in the format {param}",
+		"iso3166_1_alpha2":   "Must be an ISO 3166-1 alpha-2 country code",
+		"max_years_ahead":    "Must not be more than {param} years after the current year",
+		"notblank":           "Must not be blank",
+		"bcp47_language_tag": "Must be a BCP 47 language tag such as en or pt-BR",
+		"type":               "Must be of type {param}",
+		"":                   "Failed validation on {tag}",
+	},
+	"ru": {
+		"required":           "Обязательное поле",
+		"required_without":   "Обязательное поле, если не указано {param}",
+		"email":              "Должен быть корректный адрес электронной почты",
+		"min":                "Должно быть не меньше {param}",
+		"max":                "Должно быть не больше {param}",
+		"gt":                 "Должно быть больше {param}",
+		"lt":                 "Должно быть меньше {param}",
+		"oneof":              "Должно быть одним из значений: {param}",
+		"len":                "Длина должна быть равна {param}",
+		"date":               "Должно быть датой в формате {param}",
+		"datetime":           "Должно быть датой в формате {param}",
+		"iso3166_1_alpha2":   "Должно быть кодом страны ISO 3166-1 alpha-2",
+		"max_years_ahead":    "Может превышать текущий год не более чем на {param}",
+		"notblank":           "Не может быть пустым",
+		"bcp47_language_tag": "Должно быть языковым тегом BCP 47, например en или pt-BR",
+		"type":               "Должно иметь тип {param}",
+		"":                   "Не прошло проверку {tag}",
+	},
+	"uz": {
+		"required":           "Bu maydon majburiy",
+		"required_without":   "{param} ko'rsatilmagan bo'lsa, bu maydon majburiy",
+		"email":              "To'g'ri elektron pochta manzili bo'lishi kerak",
+		"min":                "Kamida {param} bo'lishi kerak",
+		"max":                "{param} dan oshmasligi kerak",
+		"gt":                 "{param} dan katta bo'lishi kerak",
+		"lt":                 "{param} dan kichik bo'lishi kerak",
+		"oneof":              "Quyidagilardan biri bo'lishi kerak: {param}",
+		"len":                "Uzunligi {param} bo'lishi kerak",
+		"date":               "{param} formatidagi sana bo'lishi kerak",
+		"datetime":           "{param} formatidagi sana bo'lishi kerak",
+		"iso3166_1_alpha2":   "ISO 3166-1 alpha-2 mamlakat kodi bo'lishi kerak",
+		"max_years_ahead":    "Joriy yildan {param} yildan ko'proq keyin bo'lmasligi kerak",
+		"notblank":           "Bo'sh bo'lmasligi kerak",
+		"bcp47_language_tag": "en yoki pt-BR kabi BCP 47 til tegi bo'lishi kerak",
+		"type":               "{param} turida bo'lishi kerak",
+		"":                   "{tag} tekshiruvidan o'tmadi",
+	},
+}
+
+// MatchLanguage picks the best supported message language for an
+// Accept-Language header value, falling back to DefaultLanguage.
+func MatchLanguage(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLanguage
+	}
+
+	_, index, confidence := languageMatcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLanguage
+	}
+	base, _ := languages[index].Base()
+	return base.String()
+}
+
+func message(lang, tag, param string) string {
+	catalog, ok := messages[lang]
+	if !ok {
+		catalog = messages[DefaultLanguage]
+	}
+	template, ok := catalog[tag]
+	if !ok {
+		template = catalog[""]
+	}
+
+	return strings.NewReplacer("{param}", displayParam(tag, param), "{tag}", tag).Replace(template)
+}
+
+// displayParam renders a rule parameter for clients: date layouts become
+// YYYY-MM-DD patterns and field names are snake_cased.
+func displayParam(tag, param string) string {
+	switch tag {
+	case "date":
+		return "YYYY-MM-DD"
+	case "datetime":
+		return strings.NewReplacer("2006", "YYYY", "01", "MM", "02", "DD").Replace(param)
+	case "required_without":
+		return ToSnakeCase(param)
+	default:
+		return param
+	}
+}
@@ -0,0 +1,40 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// dateLayout is the ISO 8601 calendar date format accepted by the date rule.
+const dateLayout = "2006-01-02"
+
+// registerRules adds the movie domain rules:
+//
+//   - date: a string holding a calendar date in the YYYY-MM-DD format
+//   - max_years_ahead=N: a year at most N years after the current one
+//   - notblank: a string that is not empty after trimming whitespace
+func registerRules(validate *validator.Validate) {
+	_ = validate.RegisterValidation("date", isDate)
+	_ = validate.RegisterValidation("max_years_ahead", isWithinYearsAhead)
+	_ = validate.RegisterValidation("notblank", isNotBlank)
+}
+
+func isDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(dateLayout, fl.Field().String())
+	return err == nil
+}
+
+func isWithinYearsAhead(fl validator.FieldLevel) bool {
+	years, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		panic("max_years_ahead: invalid parameter " + fl.Param())
+	}
+	return fl.Field().Int() <= int64(time.Now().Year()+years)
+}
+
+func isNotBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
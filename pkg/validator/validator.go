@@ -3,7 +3,9 @@ package validator
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -11,6 +13,10 @@ import (
 type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
+	// Tag and Param identify the failed rule so that Message can be
+	// translated; they are empty for errors that carry a fixed message.
+	Tag   string `json:"-"`
+	Param string `json:"-"`
 }
 
 type ValidationErrors []ValidationError
@@ -28,59 +34,153 @@ func (v ValidationErrors) Error() string {
 	return sb.String()
 }
 
-func ValidateStruct(s interface{}) (ValidationErrors, error) {
+// Localize returns a copy of the errors with their messages in lang. Errors
+// without a rule and rules without a translation keep their message.
+func (v ValidationErrors) Localize(lang string) ValidationErrors {
+	localized := make(ValidationErrors, len(v))
+	for i, err := range v {
+		if err.Tag != "" {
+			err.Message = message(lang, err.Tag, err.Param)
+		}
+		localized[i] = err
+	}
+	return localized
+}
+
+// NewError reports that field failed the rule tag with param, using the
+// same messages as struct validation.
+func NewError(field, tag, param string) ValidationError {
+	return ValidationError{
+		Field:   field,
+		Message: message(DefaultLanguage, tag, param),
+		Tag:     tag,
+		Param:   param,
+	}
+}
+
+// Validator checks structs against their `binding` tags with the project
+// rules and reports fields by their JSON or form names. It implements Gin's
+// binding.StructValidator.
+type Validator struct {
+	validate *validator.Validate
+}
+
+func New() *Validator {
 	validate := validator.New()
-	err := validate.Struct(s)
+	validate.SetTagName("binding")
+	validate.RegisterTagNameFunc(fieldName)
+	registerRules(validate)
+
+	return &Validator{
+		validate: validate,
+	}
+}
+
+// ValidateStruct validates a struct, a pointer to one or every element of a
+// slice or array of them. Other values are accepted as they are.
+func (v *Validator) ValidateStruct(obj interface{}) error {
+	if obj == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return v.ValidateStruct(value.Elem().Interface())
+	case reflect.Struct:
+		return v.validate.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Engine returns the underlying go-playground validator.
+func (v *Validator) Engine() interface{} {
+	return v.validate
+}
+
+var defaultValidator = New()
+
+func ValidateStruct(s interface{}) (ValidationErrors, error) {
+	err := defaultValidator.ValidateStruct(s)
 
 	if err == nil {
 		return nil, nil
 	}
 
-	var validationErrors ValidationErrors
 	var validationErrs validator.ValidationErrors
 	if errors.As(err, &validationErrs) {
-		for _, err := range validationErrs {
-			field := ToSnakeCase(err.Field())
-			message := getErrorMessage(err)
-			validationErrors = append(validationErrors, ValidationError{
-				Field:   field,
-				Message: message,
-			})
-		}
-		return validationErrors, nil
+		return FromFieldErrors(validationErrs), nil
 	}
 
 	return nil, err
 }
 
-// Helper function to convert error tag to a human-readable message
-func getErrorMessage(err validator.FieldError) string {
-	switch err.Tag() {
-	case "required":
-		return "This field is required"
-	case "email":
-		return "Must be a valid email address"
-	case "min":
-		return fmt.Sprintf("Must be at least %s", err.Param())
-	case "max":
-		return fmt.Sprintf("Must not be greater than %s", err.Param())
-	case "gt":
-		return fmt.Sprintf("Must be greater than %s", err.Param())
-	case "lt":
-		return fmt.Sprintf("Must be less than %s", err.Param())
-	default:
-		return fmt.Sprintf("Failed validation on %s", err.Tag())
+// FromFieldErrors converts the errors of a failed validation. Nested fields
+// are reported as dotted paths such as "releases[0].country".
+func FromFieldErrors(errs validator.ValidationErrors) ValidationErrors {
+	validationErrors := make(ValidationErrors, 0, len(errs))
+	for _, err := range errs {
+		validationErrors = append(validationErrors, NewError(fieldPath(err.Namespace()), err.Tag(), err.Param()))
 	}
+	return validationErrors
 }
 
-// ToSnakeCase converts a camel case string to snake case
+// fieldPath drops the struct name from a namespace like
+// "CreateMovieDTO.releases[0].country".
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// fieldName names a field after its JSON tag, falling back to the form tag
+// and then to the snake_cased field name.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return ToSnakeCase(field.Name)
+}
+
+// ToSnakeCase converts a camel case string to snake case. Acronyms stay
+// together, so "MovieID" becomes "movie_id" and "GenreIDs" "genre_ids".
 func ToSnakeCase(s string) string {
+	runes := []rune(s)
 	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && 'A' <= r && r <= 'Z' {
-			result.WriteRune('_')
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			switch {
+			case unicode.IsLower(prev) || unicode.IsDigit(prev):
+				result.WriteRune('_')
+			case unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !isPluralSuffix(runes, i+1):
+				result.WriteRune('_')
+			}
 		}
 		result.WriteRune(r)
 	}
 	return strings.ToLower(result.String())
 }
+
+// isPluralSuffix reports whether runes[i] is an "s" ending a word, as in "IDs".
+func isPluralSuffix(runes []rune, i int) bool {
+	return runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLetter(runes[i+1]))
+}
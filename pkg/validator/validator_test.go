@@ -0,0 +1,177 @@
+package validator
+
+import (
+	"testing"
+	"time"
+)
+
+type release struct {
+	Country string `json:"country" binding:"required,iso3166_1_alpha2"`
+}
+
+type movieInput struct {
+	Title       string    `json:"title" binding:"notblank"`
+	ReleaseDate string    `json:"release_date" binding:"omitempty,date"`
+	Year        int       `json:"year" binding:"max_years_ahead=5"`
+	Releases    []release `json:"releases" binding:"dive"`
+	// Fields without a JSON tag are reported in snake case.
+	DirectorID   uint `binding:"required_without=DirectorName"`
+	DirectorName string
+}
+
+func TestValidateStruct(t *testing.T) {
+	valid := func() movieInput {
+		return movieInput{Title: "Heat", ReleaseDate: "1995-12-15", Year: 1995, DirectorID: 1}
+	}
+	tests := []struct {
+		name   string
+		modify func(m *movieInput)
+		want   []ValidationError
+	}{
+		{"valid", func(m *movieInput) {}, nil},
+		{"blank title", func(m *movieInput) { m.Title = " \t" },
+			[]ValidationError{{Field: "title", Message: "Must not be blank"}}},
+		{"date in another format", func(m *movieInput) { m.ReleaseDate = "15.12.1995" },
+			[]ValidationError{{Field: "release_date", Message: "Must be a date in the format YYYY-MM-DD"}}},
+		{"impossible date", func(m *movieInput) { m.ReleaseDate = "1995-02-30" },
+			[]ValidationError{{Field: "release_date", Message: "Must be a date in the format YYYY-MM-DD"}}},
+		{"year within reach", func(m *movieInput) { m.Year = time.Now().Year() + 5 }, nil},
+		{"year too far ahead", func(m *movieInput) { m.Year = time.Now().Year() + 6 },
+			[]ValidationError{{Field: "year", Message: "Must not be more than 5 years after the current year"}}},
+		{"nested field", func(m *movieInput) { m.Releases = []release{{Country: "US"}, {Country: "USA"}} },
+			[]ValidationError{{Field: "releases[1].country", Message: "Must be an ISO 3166-1 alpha-2 country code"}}},
+		{"neither director", func(m *movieInput) { m.DirectorID = 0 },
+			[]ValidationError{{Field: "director_id", Message: "This field is required when director_name is not set"}}},
+		{"director by name", func(m *movieInput) { m.DirectorID, m.DirectorName = 0, "Michael Mann" }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid()
+			tt.modify(&input)
+			errs, err := ValidateStruct(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("ValidateStruct = %v, want %v", errs, tt.want)
+			}
+			for i, got := range errs {
+				if got.Field != tt.want[i].Field || got.Message != tt.want[i].Message {
+					t.Errorf("error %d = %s: %s, want %s: %s", i, got.Field, got.Message, tt.want[i].Field, tt.want[i].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	errs := ValidationErrors{
+		NewError("title", "required", ""),
+		NewError("year", "min", "1888"),
+		NewError("release_date", "date", dateLayout),
+		NewError("role", "oneof", "admin user"),
+		NewError("rating", "unique", ""),
+		{Field: "file", Message: "must be an image"},
+	}
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"en", []string{
+			"This field is required",
+			"Must be at least 1888",
+			"Must be a date in the format YYYY-MM-DD",
+			"Must be one of: admin user",
+			"Failed validation on unique",
+			"must be an image",
+		}},
+		{"ru", []string{
+			"Обязательное поле",
+			"Должно быть не меньше 1888",
+			"Должно быть датой в формате YYYY-MM-DD",
+			"Должно быть одним из значений: admin user",
+			"Не прошло проверку unique",
+			"must be an image",
+		}},
+		{"uz", []string{
+			"Bu maydon majburiy",
+			"Kamida 1888 bo'lishi kerak",
+			"YYYY-MM-DD formatidagi sana bo'lishi kerak",
+			"Quyidagilardan biri bo'lishi kerak: admin user",
+			"unique tekshiruvidan o'tmadi",
+			"must be an image",
+		}},
+		{"de", []string{
+			"This field is required",
+			"Must be at least 1888",
+			"Must be a date in the format YYYY-MM-DD",
+			"Must be one of: admin user",
+			"Failed validation on unique",
+			"must be an image",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			localized := errs.Localize(tt.lang)
+			for i, err := range localized {
+				if err.Field != errs[i].Field || err.Message != tt.want[i] {
+					t.Errorf("%s = %q, want %q", err.Field, err.Message, tt.want[i])
+				}
+			}
+		})
+	}
+	if errs[0].Message != "This field is required" {
+		t.Errorf("Localize changed the original errors: %q", errs[0].Message)
+	}
+}
+
+// TestMessagesComplete checks that every language translates every rule.
+func TestMessagesComplete(t *testing.T) {
+	for lang, catalog := range messages {
+		for tag := range messages[DefaultLanguage] {
+			if _, ok := catalog[tag]; !ok {
+				t.Errorf("%s has no message for %q", lang, tag)
+			}
+		}
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "en"},
+		{"ru", "ru"},
+		{"ru-RU,ru;q=0.9,en;q=0.8", "ru"},
+		{"uz-Latn-UZ", "uz"},
+		{"de,uz;q=0.5", "uz"},
+		{"en;q=0.5,ru;q=0.9", "ru"},
+		{"de", "en"},
+		{"not a language ;;", "en"},
+	}
+	for _, tt := range tests {
+		if got := MatchLanguage(tt.acceptLanguage); got != tt.want {
+			t.Errorf("MatchLanguage(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Title", "title"},
+		{"ReleaseDate", "release_date"},
+		{"MovieID", "movie_id"},
+		{"GenreIDs", "genre_ids"},
+		{"HTTPServer", "http_server"},
+		{"ISO3166Code", "iso3166_code"},
+		{"Year2000", "year2000"},
+	}
+	for _, tt := range tests {
+		if got := ToSnakeCase(tt.in); got != tt.want {
+			t.Errorf("ToSnakeCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}